@@ -9,12 +9,19 @@ import (
 	"modular_monolith/internal/cart"
 	"modular_monolith/internal/category"
 	"modular_monolith/internal/coupon"
+	"modular_monolith/internal/inventory"
+	"modular_monolith/internal/loyalty"
 	"modular_monolith/internal/order"
 	"modular_monolith/internal/payment"
+	"modular_monolith/internal/procurement"
 	"modular_monolith/internal/product"
 	"modular_monolith/internal/profile"
+	"modular_monolith/internal/question"
+	"modular_monolith/internal/recommendation"
+	"modular_monolith/internal/referral"
 	review "modular_monolith/internal/reviews"
 	"modular_monolith/internal/user"
+	"modular_monolith/internal/wishlist"
 	"os"
 	"time"
 
@@ -88,60 +95,149 @@ func main() {
 
 	usersCollection := mongoClient.Database(cfg.MongoDB).Collection("users")
 	userRepository := user.NewUserRepository(usersCollection)
-	userService := user.NewUserService(userRepository, profileService, )
+
+	coupons := mongoClient.Database(cfg.MongoDB).Collection("coupons")
+	couponsRepository := coupon.NewCouponRepository(coupons)
+	couponsService := coupon.NewCouponService(couponsRepository, userRepository, cfg.Coupon)
+	couponsHandler := coupon.NewCouponHandler(couponsService)
+
+	referrals := mongoClient.Database(cfg.MongoDB).Collection("referrals")
+	referralsRepository := referral.NewReferralRepository(referrals)
+	referralsService := referral.NewReferralService(referralsRepository, userRepository, couponsService, cfg.Referral)
+	referralsHandler := referral.NewReferralHandler(referralsService)
+
+	userService := user.NewUserService(userRepository, profileService, referralsService)
 	userHandler := user.NewUserHandler(userService)
 
+	products := mongoClient.Database(cfg.MongoDB).Collection("products")
+	productsRepository := product.NewProductRepository(products)
+
 	categories := mongoClient.Database(cfg.MongoDB).Collection("categories")
 	categoryRepository := category.NewCategoryRepository(categories)
-	categoryService := category.NewCategoryService(categoryRepository)
+	categoryService := category.NewCategoryService(categoryRepository, productsRepository, cfg.SEO)
 	categoryHandler := category.NewCategoryHandler(categoryService)
 
+	if err := categoryService.MigrateSlugs(context.Background()); err != nil {
+		log.Printf("Category MigrateSlugs failed: %v", err)
+	}
+
+	orders := mongoClient.Database(cfg.MongoDB).Collection("orders")
+	ordersRepository := order.NewOrderRepository(orders)
+
+	warehouses := mongoClient.Database(cfg.MongoDB).Collection("warehouses")
+	stockLevels := mongoClient.Database(cfg.MongoDB).Collection("stock_levels")
+	stockTransfers := mongoClient.Database(cfg.MongoDB).Collection("stock_transfers")
+	inventoryRepository := inventory.NewInventoryRepository(warehouses, stockLevels, stockTransfers)
+	stockMovements := mongoClient.Database(cfg.MongoDB).Collection("stock_movements")
+	stockMovementsRepository := inventory.NewMovementRepository(stockMovements)
+	stockTakes := mongoClient.Database(cfg.MongoDB).Collection("stock_takes")
+	stockTakesRepository := inventory.NewStockTakeRepository(stockTakes)
+	stockThresholds := mongoClient.Database(cfg.MongoDB).Collection("stock_thresholds")
+	stockThresholdsRepository := inventory.NewThresholdRepository(stockThresholds)
+	suppliers := mongoClient.Database(cfg.MongoDB).Collection("suppliers")
+	purchaseOrders := mongoClient.Database(cfg.MongoDB).Collection("purchase_orders")
+	procurementRepository := procurement.NewProcurementRepository(suppliers, purchaseOrders)
+	inventoryService := inventory.NewInventoryService(inventoryRepository, stockMovementsRepository, stockTakesRepository, stockThresholdsRepository, productsRepository, ordersRepository, procurementRepository, userRepository, cfg.Inventory)
+	inventoryHandler := inventory.NewInventoryHandler(inventoryService)
+
+	procurementService := procurement.NewProcurementService(procurementRepository, inventoryRepository, inventoryService, productsRepository, userRepository)
+	procurementHandler := procurement.NewProcurementHandler(procurementService)
+
+	productImportJobs := mongoClient.Database(cfg.MongoDB).Collection("product_import_jobs")
+	productImportJobsRepository := product.NewImportJobRepository(productImportJobs)
+	productPriceHistory := mongoClient.Database(cfg.MongoDB).Collection("product_price_history")
+	productPriceHistoryRepository := product.NewPriceHistoryRepository(productPriceHistory)
+	productsService := product.NewProductService(productsRepository, productImportJobsRepository, productPriceHistoryRepository, userRepository, inventoryService, cld, categoryService, cfg.SEO)
+	productsHandler := product.NewProductHandler(productsService)
+
+	if err := productsService.MigrateVariants(context.Background()); err != nil {
+		log.Printf("MigrateVariants failed: %v", err)
+	}
+
+	if err := inventoryService.MigrateStock(context.Background()); err != nil {
+		log.Printf("MigrateStock failed: %v", err)
+	}
+
+	if err := productsService.MigrateSlugs(context.Background()); err != nil {
+		log.Printf("Product MigrateSlugs failed: %v", err)
+	}
+
+	if err := productsService.BuildSearchIndex(context.Background()); err != nil {
+		log.Printf("BuildSearchIndex failed: %v", err)
+	}
+
 	reviews := mongoClient.Database(cfg.MongoDB).Collection("reviews")
 	reviewsRepository := review.NewReviewRepository(reviews)
-	reviewsService := review.NewReviewService(reviewsRepository, userRepository)
+
+	if err := reviewsRepository.EnsureUserProductIndex(context.Background()); err != nil {
+		log.Printf("Review EnsureUserProductIndex failed: %v", err)
+	}
+
+	reviewsService := review.NewReviewService(reviewsRepository, userRepository, ordersRepository, productsRepository, cld, cfg.Review)
 	reviewsHandler := review.NewReviewHandler(reviewsService)
 
-	products := mongoClient.Database(cfg.MongoDB).Collection("products")
-	productsRepository := product.NewProductRepository(products)
-	productsService := product.NewProductService(productsRepository, cld, reviewsService, categoryService)
-	productsHandler := product.NewProductHandler(productsService)
+	questions := mongoClient.Database(cfg.MongoDB).Collection("questions")
+	questionsRepository := question.NewQuestionRepository(questions)
+	questionsService := question.NewQuestionService(questionsRepository, userRepository, ordersRepository, productsRepository)
+	questionsHandler := question.NewQuestionHandler(questionsService)
 
 	carts := mongoClient.Database(cfg.MongoDB).Collection("carts")
 	cartsRepository := cart.NewCartRepository(carts)
-	cartsService := cart.NewCartService(cartsRepository, productsRepository)
+	cartsService := cart.NewCartService(cartsRepository, productsRepository, inventoryService)
 	cartsHandler := cart.NewCartHandler(cartsService)
 
-	coupons := mongoClient.Database(cfg.MongoDB).Collection("coupons")
-	couponsRepository := coupon.NewCouponRepository(coupons)
-	couponsService := coupon.NewCouponService(couponsRepository, userService)
-	couponsHandler := coupon.NewCouponHandler(couponsService)
+	loyaltyAccounts := mongoClient.Database(cfg.MongoDB).Collection("loyalty_accounts")
+	loyaltyTransactions := mongoClient.Database(cfg.MongoDB).Collection("loyalty_transactions")
+	loyaltyRepository := loyalty.NewLoyaltyRepository(loyaltyAccounts, loyaltyTransactions)
+	loyaltyService := loyalty.NewLoyaltyService(loyaltyRepository, userRepository, cfg.Loyalty)
+	loyaltyHandler := loyalty.NewLoyaltyHandler(loyaltyService)
 
 	payments := mongoClient.Database(cfg.MongoDB).Collection("payments")
 	paymentsRepository := payment.NewPaymentRepository(payments)
 
-	orders := mongoClient.Database(cfg.MongoDB).Collection("orders")
-	ordersRepository := order.NewOrderRepository(orders)
-	ordersService := order.NewOrderService(ordersRepository, cartsService, couponsRepository, paymentsRepository, productsRepository)
+	ordersService := order.NewOrderService(ordersRepository, cartsService, couponsRepository, paymentsRepository, productsRepository, inventoryService, loyaltyService, referralsService)
 	ordersHandler := order.NewOrderHandler(ordersService)
 
-	paymentsService := payment.NewPaymentService(paymentsRepository, ordersRepository, cfg.VNPayConfig)
+	paymentsService := payment.NewPaymentService(paymentsRepository, ordersRepository, inventoryService, loyaltyService, referralsService, cfg.VNPayConfig)
 	paymentsHandler := payment.NewPaymentHandler(paymentsService)
 
+	wishlists := mongoClient.Database(cfg.MongoDB).Collection("wishlists")
+	wishlistsRepository := wishlist.NewWishlistRepository(wishlists)
+	wishlistsService := wishlist.NewWishlistService(wishlistsRepository, productsRepository, productsService)
+	wishlistsHandler := wishlist.NewWishlistHandler(wishlistsService)
+
+	productViews := mongoClient.Database(cfg.MongoDB).Collection("product_views")
+	productRecommendations := mongoClient.Database(cfg.MongoDB).Collection("product_recommendations")
+	recommendationsRepository := recommendation.NewRecommendationRepository(productViews, productRecommendations)
+	recommendationsService := recommendation.NewRecommendationService(recommendationsRepository, productsRepository, productsService, ordersRepository, wishlistsRepository)
+	recommendationsHandler := recommendation.NewRecommendationHandler(recommendationsService)
+
 	blogs := mongoClient.Database(cfg.MongoDB).Collection("blogs")
 	blogsRepository := blog.NewBlogRepository(blogs)
-	blogsService := blog.NewBlogService(blogsRepository, cld)
+	blogsService := blog.NewBlogService(blogsRepository, cld, cfg.SEO)
 	blogsHandler := blog.NewBlogHandler(blogsService)
 
+	if err := blogsService.MigrateSlugs(context.Background()); err != nil {
+		log.Printf("Blog MigrateSlugs failed: %v", err)
+	}
+
 	blog.RegisterRoutes(r, blogsHandler)
 	coupon.RegisterRoutes(r, couponsHandler)
 	payment.RegisterRoutes(r, paymentsHandler)
 	order.RegisterRoutes(r, ordersHandler)
 	review.RegisterRoutes(r, reviewsHandler)
+	question.RegisterRoutes(r, questionsHandler)
 	user.RegisterRoutes(r, userHandler)
 	profile.RegisterRoutes(r, profileHandler)
 	category.RegisterRoutes(r, categoryHandler)
 	product.RegisterRoutes(r, productsHandler)
+	inventory.RegisterRoutes(r, inventoryHandler)
+	procurement.RegisterRoutes(r, procurementHandler)
+	recommendation.RegisterRoutes(r, recommendationsHandler)
+	wishlist.RegisterRoutes(r, wishlistsHandler)
 	cart.RegisterRoutes(r, cartsHandler)
+	loyalty.RegisterRoutes(r, loyaltyHandler)
+	referral.RegisterRoutes(r, referralsHandler)
 
 	c := cron.New(cron.WithSeconds())
 	_, err = c.AddFunc("0 */5 * * * *", func() {
@@ -155,6 +251,62 @@ func main() {
 		log.Fatalf("AddFunc error: %v", err)
 	}
 
+	_, err = c.AddFunc("0 0 3 * * *", func() {
+		ctx := context.Background()
+		if err := reviewsService.SyncProductRatings(ctx); err != nil {
+			log.Printf("SyncProductRatings failed: %v", err)
+		}
+		if err := productsService.RefreshLowestPrices(ctx); err != nil {
+			log.Printf("RefreshLowestPrices failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("AddFunc error: %v", err)
+	}
+
+	_, err = c.AddFunc("0 */30 * * * *", func() {
+		ctx := context.Background()
+		if err := couponsService.CronCouponNotifications(ctx); err != nil {
+			log.Printf("CronCouponNotifications failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("AddFunc error: %v", err)
+	}
+
+	_, err = c.AddFunc("0 * * * * *", func() {
+		ctx := context.Background()
+		if err := productsService.CronPublishSchedule(ctx); err != nil {
+			log.Printf("CronPublishSchedule failed: %v", err)
+		}
+		if err := productsService.CronPriceSchedule(ctx); err != nil {
+			log.Printf("CronPriceSchedule failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("AddFunc error: %v", err)
+	}
+
+	_, err = c.AddFunc("0 15 * * * *", func() {
+		ctx := context.Background()
+		if err := recommendationsService.CronRefreshRecommendations(ctx); err != nil {
+			log.Printf("CronRefreshRecommendations failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("AddFunc error: %v", err)
+	}
+
+	_, err = c.AddFunc("0 0 7 * * *", func() {
+		ctx := context.Background()
+		if err := inventoryService.CronLowStockDigest(ctx); err != nil {
+			log.Printf("CronLowStockDigest failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("AddFunc error: %v", err)
+	}
+
 	c.Start()
 	defer c.Stop()
 
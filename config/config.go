@@ -1,6 +1,10 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 type Config struct {
 	Port        string
@@ -8,6 +12,12 @@ type Config struct {
 	MongoDB     string
 	Clouldinary string
 	VNPayConfig VNPayConfig
+	Loyalty     LoyaltyConfig
+	Referral    ReferralConfig
+	Coupon      CouponConfig
+	Review      ReviewConfig
+	SEO         SEOConfig
+	Inventory   InventoryConfig
 }
 
 type VNPayConfig struct {
@@ -19,6 +29,63 @@ type VNPayConfig struct {
 	CurrCode   string
 }
 
+type LoyaltyConfig struct {
+	// Points earned per 1 unit of order total (before tier multiplier)
+	EarnRate float64
+	// Currency value of 1 point when redeemed at checkout
+	RedeemRate float64
+	// Maximum share of an order total (in percent) that can be paid with points
+	MaxRedeemPercent float64
+	Tiers            []LoyaltyTier
+}
+
+type LoyaltyTier struct {
+	Name       string
+	MinPoints  int
+	Multiplier float64
+}
+
+type ReferralConfig struct {
+	// Discount percent of the coupon granted to both referrer and referee
+	RewardDiscount  float64
+	RewardValidDays int
+}
+
+type CouponConfig struct {
+	// How many days before expiry allowed users get a reminder email
+	ExpiryReminderDays int
+}
+
+type ReviewConfig struct {
+	// Publish reviews that pass the content filter without waiting for a moderator
+	AutoApprove bool
+	// Reviews containing any of these words are rejected automatically
+	BannedWords []string
+	// Reviews with more links than this are held for moderation as likely spam
+	MaxLinks int
+	// Number of abuse reports after which a review is hidden
+	ReportThreshold int
+	// Maximum number of photos/videos attached to one review
+	MaxMedia int
+}
+
+type SEOConfig struct {
+	// Storefront base URL used to build canonical URLs, without trailing slash
+	SiteURL string
+}
+
+type InventoryConfig struct {
+	// Variants without their own threshold are low at or below this stock
+	LowStockThreshold int
+	// Recipients of the low-stock digest; every staff member when empty
+	AlertEmails []string
+	// Number of past days the sales velocity is computed over
+	VelocityDays int
+	// Days a reorder takes to arrive, and days of sales it should cover after that
+	ReorderLeadDays  int
+	ReorderCoverDays int
+}
+
 func LoadConfig() *Config {
 	return &Config{
 		Port:        getEnv("PORT", "8005"),
@@ -33,6 +100,36 @@ func LoadConfig() *Config {
 			Command:    getEnv("VN_PAY_COMMAND", "pay"),
 			CurrCode:   getEnv("VN_PAY_CURR_CODE", "VND"),
 		},
+		Loyalty: LoyaltyConfig{
+			EarnRate:         getEnvFloat("LOYALTY_EARN_RATE", 0.0001),
+			RedeemRate:       getEnvFloat("LOYALTY_REDEEM_RATE", 100),
+			MaxRedeemPercent: getEnvFloat("LOYALTY_MAX_REDEEM_PERCENT", 50),
+			Tiers:            parseLoyaltyTiers(getEnv("LOYALTY_TIERS", "bronze:0:1,silver:1000:1.25,gold:5000:1.5")),
+		},
+		Referral: ReferralConfig{
+			RewardDiscount:  getEnvFloat("REFERRAL_REWARD_DISCOUNT", 10),
+			RewardValidDays: getEnvInt("REFERRAL_REWARD_VALID_DAYS", 30),
+		},
+		Coupon: CouponConfig{
+			ExpiryReminderDays: getEnvInt("COUPON_EXPIRY_REMINDER_DAYS", 3),
+		},
+		Review: ReviewConfig{
+			AutoApprove:     getEnvBool("REVIEW_AUTO_APPROVE", false),
+			BannedWords:     getEnvList("REVIEW_BANNED_WORDS", "fuck,shit,bitch,asshole,dick,cunt"),
+			MaxLinks:        getEnvInt("REVIEW_MAX_LINKS", 0),
+			ReportThreshold: getEnvInt("REVIEW_REPORT_THRESHOLD", 3),
+			MaxMedia:        getEnvInt("REVIEW_MAX_MEDIA", 5),
+		},
+		SEO: SEOConfig{
+			SiteURL: strings.TrimSuffix(getEnv("SITE_URL", "http://localhost:3000"), "/"),
+		},
+		Inventory: InventoryConfig{
+			LowStockThreshold: getEnvInt("INVENTORY_LOW_STOCK_THRESHOLD", 5),
+			AlertEmails:       getEnvList("INVENTORY_ALERT_EMAILS", ""),
+			VelocityDays:      getEnvInt("INVENTORY_VELOCITY_DAYS", 30),
+			ReorderLeadDays:   getEnvInt("INVENTORY_REORDER_LEAD_DAYS", 7),
+			ReorderCoverDays:  getEnvInt("INVENTORY_REORDER_COVER_DAYS", 30),
+		},
 	}
 }
 
@@ -42,3 +139,74 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, defaultValue string) []string {
+
+	var values []string
+
+	for _, v := range strings.Split(getEnv(key, defaultValue), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// parseLoyaltyTiers reads tiers in the form "name:min_points:multiplier,..."
+func parseLoyaltyTiers(raw string) []LoyaltyTier {
+
+	var tiers []LoyaltyTier
+
+	for _, part := range strings.Split(raw, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) != 3 {
+			continue
+		}
+
+		minPoints, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		multiplier, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			continue
+		}
+
+		tiers = append(tiers, LoyaltyTier{
+			Name:       fields[0],
+			MinPoints:  minPoints,
+			Multiplier: multiplier,
+		})
+	}
+
+	return tiers
+}
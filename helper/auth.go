@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserID returns the user set by the JWT middleware. When there is none
+// it sends the 401 response itself and returns false.
+func CurrentUserID(c *gin.Context) (string, bool) {
+
+	userIDRaw, exists := c.Get("user_id")
+	if !exists {
+		SendError(c, http.StatusUnauthorized, fmt.Errorf("unauthorized"), ErrInvalidRequest)
+		return "", false
+	}
+
+	userID, ok := userIDRaw.(string)
+	if !ok {
+		SendError(c, http.StatusUnauthorized, fmt.Errorf("user_id must be string"), ErrInvalidRequest)
+		return "", false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,203 @@
+package helper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var vietnameseFold = map[rune]rune{}
+
+func init() {
+	groups := map[rune]string{
+		'a': "àáảãạăằắẳẵặâầấẩẫậ",
+		'e': "èéẻẽẹêềếểễệ",
+		'i': "ìíỉĩị",
+		'o': "òóỏõọôồốổỗộơờớởỡợ",
+		'u': "ùúủũụưừứửữự",
+		'y': "ỳýỷỹỵ",
+		'd': "đ",
+	}
+
+	for base, variants := range groups {
+		for _, r := range variants {
+			vietnameseFold[r] = base
+		}
+	}
+}
+
+// FoldVietnamese lowercases the text and strips Vietnamese diacritics.
+func FoldVietnamese(text string) string {
+
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range strings.ToLower(text) {
+		if base, ok := vietnameseFold[r]; ok {
+			r = base
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Slugify turns text into a lowercase, hyphen separated ASCII slug,
+// e.g. "Giày Chạy Bộ Nữ" becomes "giay-chay-bo-nu".
+func Slugify(text string) string {
+
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range FoldVietnamese(text) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// UniqueSlug returns base, or base with the first free numeric suffix
+// ("-2", "-3", ...) when exists reports it as taken.
+func UniqueSlug(base string, exists func(slug string) (bool, error)) (string, error) {
+
+	if base == "" {
+		return "", fmt.Errorf("slug cannot be empty")
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		taken, err := exists(slug)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return slug, nil
+		}
+
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// AssignSlug sets slug from requested, name or fallback. A slug that still
+// matches keeps its suffix, and a replaced slug goes to history for redirects.
+func AssignSlug(slug *string, history *[]string, requested, name, fallback string, exists func(slug string) (bool, error)) error {
+
+	base := Slugify(requested)
+	if base == "" {
+		base = Slugify(name)
+	}
+	if base == "" {
+		base = fallback
+	}
+
+	if *slug != "" && SlugFromBase(*slug, base) {
+		return nil
+	}
+
+	next, err := UniqueSlug(base, exists)
+	if err != nil {
+		return fmt.Errorf("failed to generate slug: %w", err)
+	}
+
+	*history = SlugHistory(*history, *slug, next)
+	*slug = next
+
+	return nil
+}
+
+// SlugIndex makes slugs unique, leaving out records that have none yet.
+var SlugIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "slug", Value: 1}},
+	Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"slug": bson.M{"$gt": ""}}),
+}
+
+// MigrateSlugs calls migrate on every record whose slug is still empty.
+func MigrateSlugs[T any](records []T, slug func(T) string, migrate func(T) error) error {
+
+	for _, record := range records {
+		if slug(record) != "" {
+			continue
+		}
+
+		if err := migrate(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// MetaDescription strips HTML from the text and cuts it to at most 160
+// characters on a word boundary.
+func MetaDescription(text string) string {
+
+	const maxLen = 160
+
+	text = strings.Join(strings.Fields(htmlTag.ReplaceAllString(text, " ")), " ")
+
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return cut + "…"
+}
+
+// SlugHistory keeps current in the history for redirects and drops next,
+// which is live again.
+func SlugHistory(history []string, current, next string) []string {
+
+	updated := []string{}
+	for _, s := range history {
+		if s != next && s != current {
+			updated = append(updated, s)
+		}
+	}
+
+	if current != "" && current != next {
+		updated = append(updated, current)
+	}
+
+	return updated
+}
+
+// SlugFromBase reports whether slug is base or base with a numeric suffix
+// added by UniqueSlug.
+func SlugFromBase(slug, base string) bool {
+
+	if slug == base {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok || suffix == "" {
+		return false
+	}
+
+	for _, r := range suffix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
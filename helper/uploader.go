@@ -36,4 +36,28 @@ func (u *CloudinaryUploader) DeleteImage(ctx context.Context, publicID string) e
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// UploadMedia uploads an image or a video and returns its resource type, which
+// is needed to delete it later.
+func (u *CloudinaryUploader) UploadMedia(ctx context.Context, file string, folderName string) (string, string, string, error) {
+	result, err := u.cld.Upload.Upload(ctx, file, uploader.UploadParams{
+		Folder:       folderName,
+		ResourceType: "auto",
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	return result.SecureURL, result.PublicID, result.ResourceType, nil
+}
+
+func (u *CloudinaryUploader) DeleteMedia(ctx context.Context, publicID string, resourceType string) error {
+	_, err := u.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -31,6 +31,9 @@ func (h *BlogHandler) CreateBlog(c *gin.Context) {
 	req.UserID = c.PostForm("user_id")
 	req.Content = c.PostForm("content")
 	req.Title = c.PostForm("title")
+	req.Slug = c.PostForm("slug")
+	req.MetaTitle = c.PostForm("meta_title")
+	req.MetaDescription = c.PostForm("meta_description")
 
 	err := c.Request.ParseMultipartForm(32 << 20)
 	if err != nil {
@@ -78,6 +81,25 @@ func (h *BlogHandler) GetBlogByID(c *gin.Context) {
 
 }
 
+func (h *BlogHandler) GetBlogBySlug(c *gin.Context) {
+
+	slug := c.Param("slug")
+
+	res, err := h.service.GetBlogBySlug(c, slug)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	if res.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/blog/slug/"+res.Slug)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", res)
+
+}
+
 func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 
 	id := c.Param("id")
@@ -91,6 +113,9 @@ func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 	req.UserID = c.PostForm("user_id")
 	req.Content = c.PostForm("content")
 	req.Title = c.PostForm("title")
+	req.Slug = c.PostForm("slug")
+	req.MetaTitle = c.PostForm("meta_title")
+	req.MetaDescription = c.PostForm("meta_description")
 
 	err := c.Request.ParseMultipartForm(32 << 20)
 	if err != nil {
@@ -1,6 +1,7 @@
 package blog
 
 import (
+	"modular_monolith/internal/shared/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,6 +10,9 @@ import (
 	type Blog struct {
 		ID            primitive.ObjectID   `json:"id" bson:"_id"`
 		Title         string               `json:"title" bson:"title"`
+		Slug          string               `json:"slug" bson:"slug"`
+		SlugHistory   []string             `json:"slug_history" bson:"slug_history"`
+		SEO           model.SEO            `json:"seo" bson:"seo"`
 		Content       string               `json:"content" bson:"content"`
 		ImageURL      string               `json:"image_url" bson:"image_url"`
 		ImagePublicID string               `json:"image_public_id" bson:"image_public_id"`
@@ -2,6 +2,7 @@ package blog
 
 import (
 	"context"
+	"modular_monolith/helper"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,6 +16,9 @@ type BlogRepository interface{
 	UpdateByID(ctx context.Context, id primitive.ObjectID, blog *Blog) error
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
 	IncrementViews(ctx context.Context, id primitive.ObjectID) error
+	FindBySlug(ctx context.Context, slug string) (*Blog, error)
+	ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
+	EnsureSlugIndex(ctx context.Context) error
 }
 
 type blogRepository struct{
@@ -78,4 +82,48 @@ func (r *blogRepository) IncrementViews(ctx context.Context, id primitive.Object
 	filter := bson.M{"_id": id}
 	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"total_view": 1}})
 	return err
-}
\ No newline at end of file
+}
+
+// FindBySlug matches the current slug or one the blog used before.
+func (r *blogRepository) FindBySlug(ctx context.Context, slug string) (*Blog, error) {
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"slug_history": slug},
+	}}
+
+	var blog *Blog
+
+	err := r.collection.FindOne(ctx, filter).Decode(&blog)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+func (r *blogRepository) ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+
+	filter := bson.M{
+		"_id": bson.M{"$ne": excludeID},
+		"$or": bson.A{
+			bson.M{"slug": slug},
+			bson.M{"slug_history": slug},
+		},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *blogRepository) EnsureSlugIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, helper.SlugIndex)
+	return err
+}
@@ -1,13 +1,19 @@
 package blog
 
 type CreateBlogRequest struct {
-	UserID  string `json:"user_id" bson:"user_id"`
-	Title   string `json:"title" bson:"title"`
-	Content string `json:"content" bson:"content"`
+	UserID          string `json:"user_id" bson:"user_id"`
+	Title           string `json:"title" bson:"title"`
+	Content         string `json:"content" bson:"content"`
+	Slug            string `json:"slug" bson:"slug"`
+	MetaTitle       string `json:"meta_title" bson:"meta_title"`
+	MetaDescription string `json:"meta_description" bson:"meta_description"`
 }
 
 type UpdateBlogRequest struct {
-	UserID  string `json:"user_id" bson:"user_id"`
-	Title   string `json:"title" bson:"title"`
-	Content string `json:"content" bson:"content"`
+	UserID          string `json:"user_id" bson:"user_id"`
+	Title           string `json:"title" bson:"title"`
+	Content         string `json:"content" bson:"content"`
+	Slug            string `json:"slug" bson:"slug"`
+	MetaTitle       string `json:"meta_title" bson:"meta_title"`
+	MetaDescription string `json:"meta_description" bson:"meta_description"`
 }
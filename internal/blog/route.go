@@ -12,6 +12,7 @@ func RegisterRoutes(r *gin.Engine, handler *BlogHandler) {
 	{
 		blogGroup.POST("", handler.CreateBlog)
 		blogGroup.GET("", handler.GetAllBlogs)
+		blogGroup.GET("/slug/:slug", handler.GetBlogBySlug)
 		blogGroup.GET("/:id", handler.GetBlogByID)
 		blogGroup.PUT("/:id", handler.UpdateBlog)
 		blogGroup.DELETE("/:id", handler.DeleteBlog)
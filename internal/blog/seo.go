@@ -0,0 +1,58 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/helper"
+)
+
+// assignSlug sets the blog slug from the requested slug or the title.
+func (s *blogService) assignSlug(ctx context.Context, blog *Blog, requested string) error {
+	return helper.AssignSlug(&blog.Slug, &blog.SlugHistory, requested, blog.Title, blog.ID.Hex(), func(slug string) (bool, error) {
+		return s.blogRepo.ExistsSlug(ctx, slug, blog.ID)
+	})
+}
+
+func (s *blogService) applySEO(blog *Blog, metaTitle, metaDescription string) {
+
+	if metaTitle == "" {
+		metaTitle = blog.Title
+	}
+
+	if metaDescription == "" {
+		metaDescription = helper.MetaDescription(blog.Content)
+	}
+
+	blog.SEO.MetaTitle = metaTitle
+	blog.SEO.MetaDescription = metaDescription
+	blog.SEO.CanonicalURL = s.seoConfig.SiteURL + "/blog/" + blog.Slug
+}
+
+// MigrateSlugs backfills the slug and SEO metadata of older blogs.
+func (s *blogService) MigrateSlugs(ctx context.Context) error {
+
+	blogs, err := s.blogRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = helper.MigrateSlugs(blogs, func(blog *Blog) string { return blog.Slug }, func(blog *Blog) error {
+
+		if err := s.assignSlug(ctx, blog, ""); err != nil {
+			return err
+		}
+
+		s.applySEO(blog, "", "")
+
+		if err := s.blogRepo.UpdateByID(ctx, blog.ID, blog); err != nil {
+			return fmt.Errorf("failed to migrate blog %s: %w", blog.ID.Hex(), err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.blogRepo.EnsureSlugIndex(ctx)
+}
@@ -1,8 +1,10 @@
 package blog
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
+	"modular_monolith/config"
 	"modular_monolith/helper"
 	"os"
 	"time"
@@ -19,17 +21,21 @@ type BlogService interface {
 	DeleteBlog(c *gin.Context, id string) error
 	LikeBlog(c *gin.Context, id string, userID primitive.ObjectID) error
 	ViewBlog(c *gin.Context, id string) error
+	GetBlogBySlug(c *gin.Context, slug string) (*Blog, error)
+	MigrateSlugs(ctx context.Context) error
 }
 
 type blogService struct {
 	blogRepo BlogRepository
 	cloudUploader *helper.CloudinaryUploader
+	seoConfig     config.SEOConfig
 }
 
-func NewBlogService(repo BlogRepository, uploader *helper.CloudinaryUploader) BlogService {
+func NewBlogService(repo BlogRepository, uploader *helper.CloudinaryUploader, seoConfig config.SEOConfig) BlogService {
 	return &blogService{
 		blogRepo: repo,
 		cloudUploader: uploader,
+		seoConfig:     seoConfig,
 	}
 }
 
@@ -83,6 +89,12 @@ func (s *blogService) CreateBlog(c *gin.Context, req *CreateBlogRequest, mainIma
 		Updated:       time.Now(),
 	}
 
+	if err := s.assignSlug(c, blog, req.Slug); err != nil {
+		return err
+	}
+
+	s.applySEO(blog, req.MetaTitle, req.MetaDescription)
+
 	return s.blogRepo.Create(c, blog)
 
 }
@@ -102,6 +114,21 @@ func (s *blogService) GetBlogByID(c *gin.Context, id string) (*Blog, error) {
 
 }
 
+// GetBlogBySlug looks a blog up by its current or a previous slug.
+func (s *blogService) GetBlogBySlug(c *gin.Context, slug string) (*Blog, error) {
+
+	blog, err := s.blogRepo.FindBySlug(c, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if blog == nil {
+		return nil, fmt.Errorf("blog not found")
+	}
+
+	return blog, nil
+}
+
 func (s *blogService) UpdateBlog(c *gin.Context, id string, req *UpdateBlogRequest, mainImage *multipart.FileHeader) error{
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -156,6 +183,12 @@ func (s *blogService) UpdateBlog(c *gin.Context, id string, req *UpdateBlogReque
 	blog.ImagePublicID = mainImagePublicID
 	blog.Updated = time.Now()
 
+	if err := s.assignSlug(c, blog, req.Slug); err != nil {
+		return err
+	}
+
+	s.applySEO(blog, req.MetaTitle, req.MetaDescription)
+
 	return s.blogRepo.UpdateByID(c, objectID, blog)
 
 }
@@ -16,6 +16,9 @@ type Cart struct {
 
 type CartItem struct {
 	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID   primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU         string             `json:"sku" bson:"sku"`
+	Attributes  map[string]string  `json:"attributes" bson:"attributes"`
 	ProductName string             `json:"product_name" bson:"product_name"`
 	Quantity    int                `json:"quantity" bson:"quantity"`
 	Price       float64            `json:"price" bson:"price"`
@@ -23,3 +26,15 @@ type CartItem struct {
 	TotalPrice  float64            `json:"total_price" bson:"total_price"`
 	ImageUrl    string             `json:"image_url" bson:"image_url"`
 }
+
+// sameLine reports whether the item is the given variant. Items added before
+// variants existed have no variant id and are matched by size.
+func (item *CartItem) sameLine(productID primitive.ObjectID, variantID primitive.ObjectID, size string) bool {
+	if item.ProductID != productID {
+		return false
+	}
+	if !variantID.IsZero() && !item.VariantID.IsZero() {
+		return item.VariantID == variantID
+	}
+	return item.Size == size
+}
@@ -14,8 +14,8 @@ type CartRepository interface {
 	Create(ctx context.Context, cart *Cart) error
 	FindCartByUserID(ctx context.Context, userID primitive.ObjectID) (*Cart, error)
 	AddToCart(ctx context.Context, cartItem *CartItem, userID primitive.ObjectID) error
-	UpdateCart(ctx context.Context, productID primitive.ObjectID, userID primitive.ObjectID, quantity int, size string, types string) error
-	DeleteItemCart(ctx context.Context, productID primitive.ObjectID, userID primitive.ObjectID) error
+	UpdateCart(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, userID primitive.ObjectID, quantity int, size string, types string) error
+	DeleteItemCart(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, userID primitive.ObjectID) error
 	DeleteCart(ctx context.Context, userID primitive.ObjectID) error
 }
 
@@ -76,12 +76,10 @@ func (r *cartRepository) AddToCart(ctx context.Context, cartItem *CartItem, user
 	found := false
 
 	for i, item := range cart.CartItems {
-		if item.ProductID == cartItem.ProductID {
-			if item.Size == cartItem.Size {
-				cart.CartItems[i].Quantity += cartItem.Quantity
-				found = true
-				break
-			}
+		if item.sameLine(cartItem.ProductID, cartItem.VariantID, cartItem.Size) {
+			cart.CartItems[i].Quantity += cartItem.Quantity
+			found = true
+			break
 		}
 	}
 
@@ -115,7 +113,7 @@ func (r *cartRepository) updateCart(ctx context.Context, cart *Cart) error {
 
 }
 
-func (r *cartRepository) UpdateCart(ctx context.Context, productID primitive.ObjectID, userID primitive.ObjectID, quantity int, size string, types string) error {
+func (r *cartRepository) UpdateCart(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, userID primitive.ObjectID, quantity int, size string, types string) error {
 	cart, err := r.FindCartByUserID(ctx, userID)
 	if err != nil {
 		return err
@@ -123,18 +121,18 @@ func (r *cartRepository) UpdateCart(ctx context.Context, productID primitive.Obj
 
 	if types == "add" {
 		for i, item := range cart.CartItems {
-			if item.ProductID == productID && item.Size == size {
+			if item.sameLine(productID, variantID, size) {
 				cart.CartItems[i].Quantity += quantity
 				break
 			}
 		}
 	} else if types == "remove" {
 		for i, item := range cart.CartItems {
-			if item.ProductID == productID && item.Size == size {
+			if item.sameLine(productID, variantID, size) {
 				if cart.CartItems[i].Quantity > quantity {
 					cart.CartItems[i].Quantity -= quantity
 				} else {
-					err := r.DeleteItemCart(ctx, productID, userID)
+					err := r.DeleteItemCart(ctx, productID, item.VariantID, userID)
 					if err != nil {
 						return err
 					}
@@ -164,7 +162,9 @@ func (r *cartRepository) updateTotalPrice(ctx context.Context, cart *Cart) error
 
 }
 
-func (r *cartRepository) DeleteItemCart(ctx context.Context, productID primitive.ObjectID, userID primitive.ObjectID) error {
+// DeleteItemCart removes the given variant, or the first line of the product
+// when no variant id is given.
+func (r *cartRepository) DeleteItemCart(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, userID primitive.ObjectID) error {
 
 	cart, err := r.FindCartByUserID(ctx, userID)
 	if err != nil {
@@ -172,7 +172,7 @@ func (r *cartRepository) DeleteItemCart(ctx context.Context, productID primitive
 	}
 
 	for i, item := range cart.CartItems {
-		if item.ProductID == productID {
+		if item.ProductID == productID && (variantID.IsZero() || item.VariantID == variantID) {
 			cart.CartItems = append(cart.CartItems[:i], cart.CartItems[i+1:]...)
 			break
 		}
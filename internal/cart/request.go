@@ -2,6 +2,7 @@ package cart
 
 type AddtoCartRequest struct {
 	ProductID string `json:"product_id" bson:"product_id"`
+	VariantID string `json:"variant_id" bson:"variant_id"`
 	UserID    string `json:"user_id" bson:"user_id"`
 	Quantity  int    `json:"quantity" bson:"quantity"`
 	Size      string `json:"size" bson:"size"`
@@ -9,6 +10,7 @@ type AddtoCartRequest struct {
 
 type UpdateCartRequest struct {
 	ProductID string `json:"product_id" bson:"product_id"`
+	VariantID string `json:"variant_id" bson:"variant_id"`
 	UserID    string `json:"user_id" bson:"user_id"`
 	Quantity  int    `json:"quantity" bson:"quantity"`
 	Size      string `json:"size" bson:"size"`
@@ -17,5 +19,6 @@ type UpdateCartRequest struct {
 
 type DeleteItemCartRequest struct {
 	ProductID string `json:"product_id" bson:"product_id"`
+	VariantID string `json:"variant_id" bson:"variant_id"`
 	UserID    string `json:"user_id" bson:"user_id"`
 }
@@ -3,6 +3,7 @@ package cart
 import (
 	"context"
 	"fmt"
+	"modular_monolith/internal/inventory"
 	"modular_monolith/internal/product"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,14 +18,16 @@ type CartService interface {
 }
 
 type cartService struct {
-	repo        CartRepository
-	productRepo product.ProductRepository
+	repo             CartRepository
+	productRepo      product.ProductRepository
+	inventoryService inventory.InventoryService
 }
 
-func NewCartService(repo CartRepository, productRepo product.ProductRepository) CartService {
+func NewCartService(repo CartRepository, productRepo product.ProductRepository, inventoryService inventory.InventoryService) CartService {
 	return &cartService{
-		repo:        repo,
-		productRepo: productRepo,
+		repo:             repo,
+		productRepo:      productRepo,
+		inventoryService: inventoryService,
 	}
 }
 
@@ -40,33 +43,42 @@ func (s *cartService) CreateCart(c context.Context, req *AddtoCartRequest) error
 		return fmt.Errorf("product not found")
 	}
 
-	var stock int
+	if !product.IsVisible() {
+		return fmt.Errorf("product %s is not available", product.ProductName)
+	}
 
-	found := false
-	for _, s := range product.Sizes {
-		if s.Size == req.Size {
-			stock = s.Stock
-			found = true
-			break
-		}
+	variant, err := resolveVariant(product, req.VariantID, req.Size)
+	if err != nil {
+		return err
+	}
+
+	available, err := s.inventoryService.AvailableStock(c, product.ID, variant.ID)
+	if err != nil {
+		return err
 	}
 
-	if !found {
-		return fmt.Errorf("size %s not available for this product", req.Size)
+	if available < req.Quantity {
+		return fmt.Errorf("not enough stock for variant %s (only %d left)", variant.SKU, available)
 	}
 
-	if stock < req.Quantity {
-		return fmt.Errorf("not enough stock for size %s (only %d left)", req.Size, stock)
+	price := product.VariantPrice(variant)
+
+	imageUrl := product.MainImage
+	if len(variant.Images) > 0 {
+		imageUrl = variant.Images[0].Url
 	}
 
 	cartItem := &CartItem{
 		ProductID:   product.ID,
+		VariantID:   variant.ID,
+		SKU:         variant.SKU,
+		Attributes:  variant.Attributes,
 		ProductName: product.ProductName,
 		Quantity:    req.Quantity,
-		TotalPrice:  product.Price * float64(req.Quantity),
-		Price:       product.Price,
-		Size:        req.Size,
-		ImageUrl:    product.MainImage,
+		TotalPrice:  price * float64(req.Quantity),
+		Price:       price,
+		Size:        variant.Attributes["size"],
+		ImageUrl:    imageUrl,
 	}
 
 	userID, err := primitive.ObjectIDFromHex(req.UserID)
@@ -122,8 +134,8 @@ func (s *cartService) UpdateCart(c context.Context, req *UpdateCartRequest) erro
 		return fmt.Errorf("types is required")
 	}
 
-	if req.Size == "" {
-		return fmt.Errorf("size is required")
+	if req.Size == "" && req.VariantID == "" {
+		return fmt.Errorf("variant id or size is required")
 	}
 
 	objectProductID, err := primitive.ObjectIDFromHex(req.ProductID)
@@ -131,12 +143,17 @@ func (s *cartService) UpdateCart(c context.Context, req *UpdateCartRequest) erro
 		return fmt.Errorf("invalid product id: %v", err)
 	}
 
+	objectVariantID, err := parseVariantID(req.VariantID)
+	if err != nil {
+		return err
+	}
+
 	objectUserID, err := primitive.ObjectIDFromHex(req.UserID)
 	if err != nil {
 		return fmt.Errorf("invalid user id: %v", err)
 	}
 
-	return s.repo.UpdateCart(c, objectProductID, objectUserID, req.Quantity, req.Size, req.Types)
+	return s.repo.UpdateCart(c, objectProductID, objectVariantID, objectUserID, req.Quantity, req.Size, req.Types)
 
 }
 
@@ -155,12 +172,17 @@ func (s *cartService) DeleteItemCart(c context.Context, req *DeleteItemCartReque
 		return fmt.Errorf("invalid product id: %v", err)
 	}
 
+	objectVariantID, err := parseVariantID(req.VariantID)
+	if err != nil {
+		return err
+	}
+
 	objectUserID, err := primitive.ObjectIDFromHex(req.UserID)
 	if err != nil {
 		return fmt.Errorf("invalid user id: %v", err)
 	}
 
-	return s.repo.DeleteItemCart(c, objectProductID, objectUserID)
+	return s.repo.DeleteItemCart(c, objectProductID, objectVariantID, objectUserID)
 }
 
 func (s *cartService) DeleteCart(c context.Context, userID string) error {
@@ -177,3 +199,42 @@ func (s *cartService) DeleteCart(c context.Context, userID string) error {
 	return s.repo.DeleteCart(c, objectUserID)
 
 }
+
+// resolveVariant finds the variant by id, falling back to the size for
+// clients that do not send a variant id yet.
+func resolveVariant(p *product.Product, variantID string, size string) (*product.Variant, error) {
+
+	if variantID != "" {
+		objectID, err := parseVariantID(variantID)
+		if err != nil {
+			return nil, err
+		}
+
+		variant := p.FindVariant(objectID)
+		if variant == nil {
+			return nil, fmt.Errorf("variant %s not available for this product", variantID)
+		}
+		return variant, nil
+	}
+
+	variant := p.FindVariantBySize(size)
+	if variant == nil {
+		return nil, fmt.Errorf("size %s not available for this product", size)
+	}
+
+	return variant, nil
+}
+
+func parseVariantID(variantID string) (primitive.ObjectID, error) {
+
+	if variantID == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(variantID)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid variant id: %v", err)
+	}
+
+	return objectID, nil
+}
@@ -48,6 +48,48 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 
 }
 
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+
+	tree, err := h.categoryService.GetCategoryTree(c)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", tree)
+}
+
+func (h *CategoryHandler) GetBreadcrumbs(c *gin.Context) {
+
+	categoryID := c.Param("id")
+
+	breadcrumbs, err := h.categoryService.GetBreadcrumbs(c, categoryID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", breadcrumbs)
+}
+
+func (h *CategoryHandler) GetCategoryBySlug(c *gin.Context) {
+
+	slug := c.Param("slug")
+
+	category, err := h.categoryService.GetCategoryBySlug(c, slug)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	if category.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/category/slug/"+category.Slug)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", category)
+}
+
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 
 	categoryID := c.Param("id")	
@@ -84,7 +126,13 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	
 	categoryID := c.Param("id")
 
-	err := h.categoryService.DeleteCategory(c, categoryID)
+	var req DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.categoryService.DeleteCategory(c, categoryID, req.Mode)
 
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
@@ -1,6 +1,7 @@
 package category
 
 import (
+	"modular_monolith/internal/shared/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,9 +10,23 @@ import (
 type Category struct {
 	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
 	CategoryName string              `json:"category_name" bson:"category_name"`
+	Slug         string              `json:"slug" bson:"slug"`
+	SlugHistory  []string            `json:"slug_history" bson:"slug_history"`
+	SEO          model.SEO           `json:"seo" bson:"seo"`
 	ParentID     *primitive.ObjectID `json:"parent_id" bson:"parent_id"`
 	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time           `json:"updated_at" bson:"updated_at"`
 }
 
+// CategoryTree is a category with its nested children.
+type CategoryTree struct {
+	*Category
+	Children []*CategoryTree `json:"children"`
+}
 
+// Delete modes for a category that still has children or products.
+const (
+	DeleteModeBlock    = "block"
+	DeleteModeReparent = "reparent"
+	DeleteModeCascade  = "cascade"
+)
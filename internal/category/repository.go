@@ -2,6 +2,8 @@ package category
 
 import (
 	"context"
+	"modular_monolith/helper"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,6 +16,11 @@ type CategoryRepository interface {
 	FindByID(ctx context.Context, categoryID primitive.ObjectID) (*Category, error)
 	UpdateByID(ctx context.Context, category *Category, categoryID primitive.ObjectID) error
 	DeleteByID(ctx context.Context, categoryID primitive.ObjectID) error
+	DeleteByIDs(ctx context.Context, categoryIDs []primitive.ObjectID) error
+	UpdateParent(ctx context.Context, fromParentID primitive.ObjectID, toParentID *primitive.ObjectID) error
+	FindBySlug(ctx context.Context, slug string) (*Category, error)
+	ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
+	EnsureSlugIndex(ctx context.Context) error
 }
 
 type categoryRepository struct {
@@ -92,3 +99,71 @@ func (r *categoryRepository) DeleteByID(ctx context.Context, categoryID primitiv
 	return nil
 	
 }
+
+func (r *categoryRepository) DeleteByIDs(ctx context.Context, categoryIDs []primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": categoryIDs}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateParent moves every child of fromParentID under toParentID.
+func (r *categoryRepository) UpdateParent(ctx context.Context, fromParentID primitive.ObjectID, toParentID *primitive.ObjectID) error {
+
+	filter := bson.M{"parent_id": fromParentID}
+	update := bson.M{"$set": bson.M{"parent_id": toParentID, "updated_at": time.Now()}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindBySlug matches the current slug or one the category used before.
+func (r *categoryRepository) FindBySlug(ctx context.Context, slug string) (*Category, error) {
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"slug_history": slug},
+	}}
+
+	var category Category
+
+	err := r.collection.FindOne(ctx, filter).Decode(&category)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepository) ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+
+	filter := bson.M{
+		"_id": bson.M{"$ne": excludeID},
+		"$or": bson.A{
+			bson.M{"slug": slug},
+			bson.M{"slug_history": slug},
+		},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *categoryRepository) EnsureSlugIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, helper.SlugIndex)
+	return err
+}
@@ -1,11 +1,21 @@
 package category
 
 type CreateCategoryRequest struct {
-	CategoryName string `json:"category_name" bson:"category_name"`
-	ParentID     *string `json:"parent_id" bson:"parent_id"`
+	CategoryName    string  `json:"category_name" bson:"category_name"`
+	ParentID        *string `json:"parent_id" bson:"parent_id"`
+	Slug            string  `json:"slug" bson:"slug"`
+	MetaTitle       string  `json:"meta_title" bson:"meta_title"`
+	MetaDescription string  `json:"meta_description" bson:"meta_description"`
+}
+
+type DeleteCategoryRequest struct {
+	Mode string `form:"mode"`
 }
 
 type UpdateCategoryRequest struct {
-	CategoryName string `json:"category_name" bson:"category_name"`
-	ParentID     *string `json:"parent_id" bson:"parent_id"`
+	CategoryName    string  `json:"category_name" bson:"category_name"`
+	ParentID        *string `json:"parent_id" bson:"parent_id"`
+	Slug            string  `json:"slug" bson:"slug"`
+	MetaTitle       string  `json:"meta_title" bson:"meta_title"`
+	MetaDescription string  `json:"meta_description" bson:"meta_description"`
 }
@@ -9,7 +9,10 @@ func RegisterRoutes(route *gin.Engine, categoryHandler *CategoryHandler) {
 	{
 		categoriesGroup.POST("", categoryHandler.CreateCategory)
 		categoriesGroup.GET("", categoryHandler.GetCategories)
+		categoriesGroup.GET("/tree", categoryHandler.GetCategoryTree)
+		categoriesGroup.GET("/slug/:slug", categoryHandler.GetCategoryBySlug)
 		categoriesGroup.GET("/:id", categoryHandler.GetCategory)
+		categoriesGroup.GET("/:id/breadcrumbs", categoryHandler.GetBreadcrumbs)
 		categoriesGroup.PUT("/:id", categoryHandler.UpdateCategory)
 		categoriesGroup.DELETE("/:id", categoryHandler.DeleteCategory)
 	}
@@ -0,0 +1,69 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/helper"
+)
+
+// assignSlug sets the category slug from the requested slug or the name.
+func (s *categoryService) assignSlug(ctx context.Context, category *Category, requested string) error {
+	return helper.AssignSlug(&category.Slug, &category.SlugHistory, requested, category.CategoryName, category.ID.Hex(), func(slug string) (bool, error) {
+		return s.categoryRepository.ExistsSlug(ctx, slug, category.ID)
+	})
+}
+
+func (s *categoryService) applySEO(category *Category, metaTitle, metaDescription string) {
+
+	if metaTitle == "" {
+		metaTitle = category.CategoryName
+	}
+
+	category.SEO.MetaTitle = metaTitle
+	category.SEO.MetaDescription = metaDescription
+	category.SEO.CanonicalURL = s.seoConfig.SiteURL + "/category/" + category.Slug
+}
+
+// MigrateSlugs backfills the slug and SEO metadata of older categories.
+func (s *categoryService) MigrateSlugs(ctx context.Context) error {
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = helper.MigrateSlugs(categories, func(category *Category) string { return category.Slug }, func(category *Category) error {
+
+		if err := s.assignSlug(ctx, category, ""); err != nil {
+			return err
+		}
+
+		s.applySEO(category, "", "")
+
+		if err := s.categoryRepository.UpdateByID(ctx, category, category.ID); err != nil {
+			return fmt.Errorf("failed to migrate category %s: %w", category.ID.Hex(), err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.categoryRepository.EnsureSlugIndex(ctx)
+}
+
+// GetCategoryBySlug looks a category up by its current or a previous slug.
+func (s *categoryService) GetCategoryBySlug(ctx context.Context, slug string) (*Category, error) {
+
+	category, err := s.categoryRepository.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	return category, nil
+}
@@ -3,6 +3,8 @@ package category
 import (
 	"context"
 	"fmt"
+	"modular_monolith/config"
+	"modular_monolith/internal/shared/ports"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,16 +15,25 @@ type CategoryService interface {
 	GetCategories(ctx context.Context) ([]*Category, error)
 	GetCategory(ctx context.Context, categoryID string) (*Category, error)
 	UpdateCategory(ctx context.Context, req *UpdateCategoryRequest, categoryID string) error
-	DeleteCategory(ctx context.Context, categoryID string) error
+	DeleteCategory(ctx context.Context, categoryID string, mode string) error
+	GetCategoryTree(ctx context.Context) ([]*CategoryTree, error)
+	GetBreadcrumbs(ctx context.Context, categoryID string) ([]*Category, error)
+	GetDescendantIDs(ctx context.Context, categoryID string) ([]primitive.ObjectID, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*Category, error)
+	MigrateSlugs(ctx context.Context) error
 }
 
 type categoryService struct {
 	categoryRepository CategoryRepository
+	productRepository  ports.ProductCategoryRepository
+	seoConfig          config.SEOConfig
 }
 
-func NewCategoryService(categoryRepository CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepository CategoryRepository, productRepository ports.ProductCategoryRepository, seoConfig config.SEOConfig) CategoryService {
 	return &categoryService{
 		categoryRepository: categoryRepository,
+		productRepository:  productRepository,
+		seoConfig:          seoConfig,
 	}
 }
 
@@ -39,18 +50,30 @@ func (s *categoryService) CreateCategory(ctx context.Context, req *CreateCategor
 		if err != nil {
 			return fmt.Errorf("invalid parent id: %v", err)
 		}
+
+		if parent, err := s.categoryRepository.FindByID(ctx, objectID); err != nil || parent == nil {
+			return fmt.Errorf("parent category not found")
+		}
+
 		parentID = &objectID
 	} else {
 		parentID = nil
 	} 
 
 	category := Category{
+		ID:           primitive.NewObjectID(),
 		CategoryName: req.CategoryName,
 		ParentID:     parentID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
+	if err := s.assignSlug(ctx, &category, req.Slug); err != nil {
+		return err
+	}
+
+	s.applySEO(&category, req.MetaTitle, req.MetaDescription)
+
 	return s.categoryRepository.Create(ctx, &category)
 
 }
@@ -87,32 +110,244 @@ func (s *categoryService) UpdateCategory(ctx context.Context, req *UpdateCategor
 	}
 
 	if req.ParentID != nil {
-		objectID, err := primitive.ObjectIDFromHex(*req.ParentID)
+		parentObjectID, err := primitive.ObjectIDFromHex(*req.ParentID)
 		if err != nil {
 			return fmt.Errorf("invalid parent id: %v", err)
 		}
-		parentID = &objectID
+
+		categories, err := s.categoryRepository.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !containsCategory(categories, parentObjectID) {
+			return fmt.Errorf("parent category not found")
+		}
+
+		// The new parent must not be the category itself or one of its descendants
+		for _, id := range descendantIDs(categories, objectID) {
+			if id == parentObjectID {
+				return fmt.Errorf("category cannot be moved under itself or one of its descendants")
+			}
+		}
+
+		parentID = &parentObjectID
 	} else {
 		parentID = nil
 	}
 	
-	category := Category{
-		ID:           objectID,
-		CategoryName: req.CategoryName,
-		ParentID:     parentID,
-		UpdatedAt:    time.Now(),
+	category, err := s.categoryRepository.FindByID(ctx, objectID)
+	if err != nil || category == nil {
+		return fmt.Errorf("category not found")
+	}
+
+	category.CategoryName = req.CategoryName
+	category.ParentID = parentID
+	category.UpdatedAt = time.Now()
+
+	if err := s.assignSlug(ctx, category, req.Slug); err != nil {
+		return err
 	}
 
-	return s.categoryRepository.UpdateByID(ctx, &category, objectID)
+	s.applySEO(category, req.MetaTitle, req.MetaDescription)
+
+	return s.categoryRepository.UpdateByID(ctx, category, objectID)
 
 }
 
-func (s *categoryService) DeleteCategory(ctx context.Context, categoryID string) error {
+// DeleteCategory removes a category. One with children or products needs
+// mode reparent, which moves them to its parent, or cascade, which also
+// deletes the descendants.
+func (s *categoryService) DeleteCategory(ctx context.Context, categoryID string, mode string) error {
 	
 	objectID, err := primitive.ObjectIDFromHex(categoryID)
 	if err != nil {
 		return fmt.Errorf("invalid category id: %v", err)
 	}
 
+	if mode == "" {
+		mode = DeleteModeBlock
+	}
+
+	if mode != DeleteModeBlock && mode != DeleteModeReparent && mode != DeleteModeCascade {
+		return fmt.Errorf("invalid delete mode %s", mode)
+	}
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var category *Category
+	for _, c := range categories {
+		if c.ID == objectID {
+			category = c
+			break
+		}
+	}
+
+	if category == nil {
+		return fmt.Errorf("category not found")
+	}
+
+	subtree := descendantIDs(categories, objectID)
+	hasChildren := len(subtree) > 1
+
+	affected := []primitive.ObjectID{objectID}
+	if mode == DeleteModeCascade {
+		affected = subtree
+	}
+
+	productCount, err := s.productRepository.CountByCategoryIDs(ctx, affected)
+	if err != nil {
+		return err
+	}
+
+	if mode == DeleteModeBlock && (hasChildren || productCount > 0) {
+		return fmt.Errorf("category has %d child categories and %d products", len(subtree)-1, productCount)
+	}
+
+	if productCount > 0 {
+		if category.ParentID == nil {
+			return fmt.Errorf("category has %d products and no parent to move them to", productCount)
+		}
+
+		if err := s.productRepository.MoveCategory(ctx, affected, *category.ParentID); err != nil {
+			return fmt.Errorf("failed to move products: %w", err)
+		}
+	}
+
+	if mode == DeleteModeCascade {
+		return s.categoryRepository.DeleteByIDs(ctx, subtree)
+	}
+
+	if hasChildren {
+		if err := s.categoryRepository.UpdateParent(ctx, objectID, category.ParentID); err != nil {
+			return fmt.Errorf("failed to move child categories: %w", err)
+		}
+	}
+
 	return s.categoryRepository.DeleteByID(ctx, objectID)
+}
+
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]*CategoryTree, error) {
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[primitive.ObjectID]*CategoryTree)
+	for _, c := range categories {
+		nodes[c.ID] = &CategoryTree{Category: c, Children: []*CategoryTree{}}
+	}
+
+	roots := []*CategoryTree{}
+	for _, c := range categories {
+		node := nodes[c.ID]
+
+		// Categories whose parent no longer exists are shown as roots
+		var parent *CategoryTree
+		if c.ParentID != nil {
+			parent = nodes[*c.ParentID]
+		}
+
+		if parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots, nil
+}
+
+// GetBreadcrumbs returns the path from the root category down to the given
+// category.
+func (s *categoryService) GetBreadcrumbs(ctx context.Context, categoryID string) ([]*Category, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid category id: %v", err)
+	}
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*Category)
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	var path []*Category
+	visited := make(map[primitive.ObjectID]bool)
+
+	current, ok := byID[objectID]
+	if !ok {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	for current != nil && !visited[current.ID] {
+		visited[current.ID] = true
+		path = append([]*Category{current}, path...)
+
+		if current.ParentID == nil {
+			break
+		}
+		current = byID[*current.ParentID]
+	}
+
+	return path, nil
+}
+
+// GetDescendantIDs returns the category id followed by the ids of all its
+// descendants.
+func (s *categoryService) GetDescendantIDs(ctx context.Context, categoryID string) ([]primitive.ObjectID, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid category id: %v", err)
+	}
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return descendantIDs(categories, objectID), nil
+}
+
+func descendantIDs(categories []*Category, rootID primitive.ObjectID) []primitive.ObjectID {
+
+	children := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []primitive.ObjectID{rootID}
+	visited := map[primitive.ObjectID]bool{rootID: true}
+
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+
+	return ids
+}
+
+func containsCategory(categories []*Category, id primitive.ObjectID) bool {
+	for _, c := range categories {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
 }
\ No newline at end of file
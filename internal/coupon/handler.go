@@ -37,7 +37,13 @@ func (h *CouponHandler) CreateCoupon(c *gin.Context) {
 
 func (h *CouponHandler) GetAllCoupons(c *gin.Context) {
 
-	coupons, err := h.CouponService.GetAllCoupons(c)
+	var filter CouponFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	coupons, err := h.CouponService.GetAllCoupons(c, &filter)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -2,6 +2,7 @@ package coupon
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,13 +11,17 @@ import (
 
 type CouponRepository interface {
 	Create(ctx context.Context, coupon *Coupon) error
-	FindAll(ctx context.Context) ([]*Coupon, error)
+	FindAll(ctx context.Context, filter *CouponFilter) ([]*Coupon, error)
 	FindByCode(ctx context.Context, id string) (*Coupon, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	FindAllCouponsByUserID(ctx context.Context, userID primitive.ObjectID) ([]*Coupon, error)
 	CheckCodeCoupon(ctx context.Context, codeCoupon string) (bool, error)
 	AddUserIsUsed(ctx context.Context, userID primitive.ObjectID, codeCoupon string) error
-	
+	FindPendingGrantNotifications(ctx context.Context) ([]*Coupon, error)
+	FindExpiringWithoutReminder(ctx context.Context, before time.Time) ([]*Coupon, error)
+	MarkGrantNotified(ctx context.Context, id primitive.ObjectID) error
+	MarkExpiryReminderSent(ctx context.Context, id primitive.ObjectID) error
+	ArchiveExpired(ctx context.Context) (int64, error)
 }
 
 type couponRepository struct {
@@ -34,11 +39,21 @@ func (r *couponRepository) Create(ctx context.Context, coupon *Coupon) error {
 	return err
 }
 
-func (r *couponRepository) FindAll(ctx context.Context) ([]*Coupon, error) {
+func (r *couponRepository) FindAll(ctx context.Context, filter *CouponFilter) ([]*Coupon, error) {
 
 	var coupons []*Coupon
 
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	query := bson.M{}
+
+	switch filter.Status {
+	case "all":
+	case "archived":
+		query["archived"] = true
+	default:
+		query["archived"] = bson.M{"$ne": true}
+	}
+
+	cursor, err := r.collection.Find(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +81,10 @@ func (r *couponRepository) FindByCode(ctx context.Context, id string) (*Coupon,
 
 func (r *couponRepository) FindAllCouponsByUserID(ctx context.Context, userID primitive.ObjectID) ([]*Coupon, error) {
 
-	filter := bson.M{"allowed_users.user_id": userID}
+	filter := bson.M{
+		"allowed_users": userID,
+		"expired_at":    bson.M{"$gt": time.Now()},
+	}
 
 	var coupons []*Coupon
 
@@ -116,4 +134,98 @@ func (r *couponRepository) Delete(ctx context.Context, id primitive.ObjectID) er
 
 	return nil
 
-}
\ No newline at end of file
+}
+
+// FindPendingGrantNotifications matches private coupons whose grant_notified_at is
+// explicitly null. Coupons created before notifications existed have no such
+// field and are skipped, so old grants are not announced again.
+func (r *couponRepository) FindPendingGrantNotifications(ctx context.Context) ([]*Coupon, error) {
+
+	filter := bson.M{
+		"type":              Private,
+		"archived":          bson.M{"$ne": true},
+		"grant_notified_at": bson.M{"$type": "null"},
+	}
+
+	var coupons []*Coupon
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &coupons); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+func (r *couponRepository) FindExpiringWithoutReminder(ctx context.Context, before time.Time) ([]*Coupon, error) {
+
+	filter := bson.M{
+		"type":     Private,
+		"archived": bson.M{"$ne": true},
+		"expired_at": bson.M{
+			"$gt":  time.Now(),
+			"$lte": before,
+		},
+		"expiry_reminder_sent_at": nil,
+	}
+
+	var coupons []*Coupon
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &coupons); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+func (r *couponRepository) MarkGrantNotified(ctx context.Context, id primitive.ObjectID) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"grant_notified_at": time.Now()}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *couponRepository) MarkExpiryReminderSent(ctx context.Context, id primitive.ObjectID) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"expiry_reminder_sent_at": time.Now()}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *couponRepository) ArchiveExpired(ctx context.Context) (int64, error) {
+
+	now := time.Now()
+
+	filter := bson.M{
+		"archived":   bson.M{"$ne": true},
+		"expired_at": bson.M{"$lt": now},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"archived":    true,
+			"archived_at": now,
+			"updated_at":  now,
+		},
+	}
+
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
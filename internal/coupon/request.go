@@ -22,3 +22,8 @@ type CanUseCouponRequest struct {
 	CouponCode string `json:"coupon_code" bson:"coupon_code"`
 	UserID     string `json:"user_id" bson:"user_id"`
 }
+
+type CouponFilter struct {
+	// live (default), archived or all
+	Status string `form:"status"`
+}
@@ -16,6 +16,8 @@ type CouponResponse struct {
 	AllowedUsers []*UserInfor       `json:"allowed_users" bson:"allowed_users"`
 	Type         string             `json:"type" bson:"type"`
 	ExpiredAt    time.Time          `json:"expired_at" bson:"expired_at"`
+	Archived     bool               `json:"archived" bson:"archived"`
+	ArchivedAt   *time.Time         `json:"archived_at" bson:"archived_at"`
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
 }
@@ -1,9 +1,13 @@
 package coupon
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"math/rand"
+	"modular_monolith/config"
 	"modular_monolith/internal/user"
+	"modular_monolith/pkg/email"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,22 +16,29 @@ import (
 
 type CouponService interface {
 	CreateCoupon(c *gin.Context, req *CreateCouponRequest) error
-	GetAllCoupons(c *gin.Context) ([]*CouponResponse, error)
+	GetAllCoupons(c *gin.Context, filter *CouponFilter) ([]*CouponResponse, error)
 	GetCouponByCode(c *gin.Context, code string) (*CouponResponse, error)
 	GetCouponByUserID(c *gin.Context, userID string) ([]*CouponResponse, error)
 	CanUseCoupon(c *gin.Context, req *CanUseCouponRequest) (*Coupon, error)
 	DeleteCoupon(c *gin.Context, id string) error
+	GrantPrivateCoupon(ctx context.Context, name string, discount float64, userIDs []primitive.ObjectID, expiredAt time.Time) (*Coupon, error)
+	CronCouponNotifications(ctx context.Context) error
 }
 
 type couponService struct {
 	couponRepository CouponRepository
-	userService      user.UserService
+	userRepository   user.UserRepository
+	config           config.CouponConfig
+	emailService     *email.EmailService
 }
 
-func NewCouponService(couponRepository CouponRepository, userService user.UserService) CouponService {
+func NewCouponService(couponRepository CouponRepository, userRepository user.UserRepository, config config.CouponConfig) CouponService {
+	emailService := email.NewEmailService()
 	return &couponService{
 		couponRepository: couponRepository,
-		userService:      userService,
+		userRepository:   userRepository,
+		config:           config,
+		emailService:     emailService,
 	}
 }
 
@@ -73,25 +84,9 @@ func (s *couponService) CreateCoupon(c *gin.Context, req *CreateCouponRequest) e
 		return fmt.Errorf("invalid expired at format: %w", err)
 	}
 
-	const maxAttempts = 5
-	var codeCoupon string
-	for i := 0; i < maxAttempts; i++ {
-
-		codeCoupon = s.generateCodeCoupon(9)
-
-		check, err := s.couponRepository.CheckCodeCoupon(c, codeCoupon)
-		if err != nil {
-			return fmt.Errorf("failed to check code: %w", err)
-		}
-
-		if !check {
-			break
-		}
-
-		if i == maxAttempts-1 {
-			return fmt.Errorf("could not generate unique coupon code after %d attempts", maxAttempts)
-		}
-
+	codeCoupon, err := s.generateUniqueCodeCoupon(c)
+	if err != nil {
+		return err
 	}
 
 	coupon := &Coupon{
@@ -110,9 +105,9 @@ func (s *couponService) CreateCoupon(c *gin.Context, req *CreateCouponRequest) e
 
 }
 
-func (s *couponService) GetAllCoupons(c *gin.Context) ([]*CouponResponse, error) {
+func (s *couponService) GetAllCoupons(c *gin.Context, filter *CouponFilter) ([]*CouponResponse, error) {
 
-	coupons, err := s.couponRepository.FindAll(c)
+	coupons, err := s.couponRepository.FindAll(c, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +128,69 @@ func (s *couponService) GetAllCoupons(c *gin.Context) ([]*CouponResponse, error)
 }
 
 
+func (s *couponService) GrantPrivateCoupon(ctx context.Context, name string, discount float64, userIDs []primitive.ObjectID, expiredAt time.Time) (*Coupon, error) {
+
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if discount <= 0 {
+		return nil, fmt.Errorf("discount must be greater than 0")
+	}
+
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("at least one allowed user is required for private coupon")
+	}
+
+	codeCoupon, err := s.generateUniqueCodeCoupon(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maximumUse := len(userIDs)
+
+	coupon := &Coupon{
+		ID:           primitive.NewObjectID(),
+		CodeCoupon:   codeCoupon,
+		Name:         name,
+		Discount:     discount,
+		MaximumUse:   &maximumUse,
+		UserIsUsed:   []primitive.ObjectID{},
+		AllowedUsers: userIDs,
+		Type:         string(Private),
+		ExpiredAt:    expiredAt,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := s.couponRepository.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+func (s *couponService) generateUniqueCodeCoupon(ctx context.Context) (string, error) {
+
+	const maxAttempts = 5
+
+	for i := 0; i < maxAttempts; i++ {
+
+		codeCoupon := s.generateCodeCoupon(9)
+
+		check, err := s.couponRepository.CheckCodeCoupon(ctx, codeCoupon)
+		if err != nil {
+			return "", fmt.Errorf("failed to check code: %w", err)
+		}
+
+		if !check {
+			return codeCoupon, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not generate unique coupon code after %d attempts", maxAttempts)
+}
+
 func (s *couponService) generateCodeCoupon(length int) string {
 
 	charset := "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -285,7 +343,7 @@ func (s *couponService) getUserInfor(c *gin.Context, coupon *Coupon) (*CouponRes
 
 	var userUsed []*UserInfor
 	for _, userID := range coupon.UserIsUsed {
-		user, err := s.userService.GetUserByID(c, userID.Hex())
+		user, err := s.userRepository.FindByID(c, userID)
 		if err != nil {
 			return nil, fmt.Errorf("cannot get user from UserIsUsed: %w", err)
 		}
@@ -297,7 +355,7 @@ func (s *couponService) getUserInfor(c *gin.Context, coupon *Coupon) (*CouponRes
 
 	var allowedUsers []*UserInfor
 	for _, userID := range coupon.AllowedUsers {
-		user, err := s.userService.GetUserByID(c, userID.Hex())
+		user, err := s.userRepository.FindByID(c, userID)
 		if err != nil {
 			return nil, fmt.Errorf("cannot get user from AllowedUsers: %w", err)
 		}
@@ -317,7 +375,85 @@ func (s *couponService) getUserInfor(c *gin.Context, coupon *Coupon) (*CouponRes
 		AllowedUsers: allowedUsers,
 		Type:         coupon.Type,
 		ExpiredAt:    coupon.ExpiredAt,
+		Archived:     coupon.Archived,
+		ArchivedAt:   coupon.ArchivedAt,
 		CreatedAt:    coupon.CreatedAt,
 		UpdatedAt:    coupon.UpdatedAt,
 	}, nil
-}
\ No newline at end of file
+}
+
+// CronCouponNotifications archives expired coupons, announces newly granted
+// private coupons and reminds allowed users shortly before a coupon expires.
+func (s *couponService) CronCouponNotifications(ctx context.Context) error {
+
+	archived, err := s.couponRepository.ArchiveExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to archive expired coupons: %w", err)
+	}
+
+	if archived > 0 {
+		log.Printf("archived %d expired coupons", archived)
+	}
+
+	granted, err := s.couponRepository.FindPendingGrantNotifications(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find granted coupons: %w", err)
+	}
+
+	for _, coupon := range granted {
+		s.notifyAllowedUsers(ctx, coupon, "You received a new coupon", BuildCouponGrantedEmailHTML)
+
+		if err := s.couponRepository.MarkGrantNotified(ctx, coupon.ID); err != nil {
+			log.Printf("failed to mark coupon %s as notified: %v", coupon.CodeCoupon, err)
+		}
+	}
+
+	before := time.Now().AddDate(0, 0, s.config.ExpiryReminderDays)
+
+	expiring, err := s.couponRepository.FindExpiringWithoutReminder(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to find expiring coupons: %w", err)
+	}
+
+	for _, coupon := range expiring {
+		s.notifyAllowedUsers(ctx, coupon, "Your coupon is about to expire", BuildCouponExpiryReminderEmailHTML)
+
+		if err := s.couponRepository.MarkExpiryReminderSent(ctx, coupon.ID); err != nil {
+			log.Printf("failed to mark coupon %s as reminded: %v", coupon.CodeCoupon, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *couponService) notifyAllowedUsers(ctx context.Context, coupon *Coupon, subject string, build func(Coupon, string, string) string) {
+
+	for _, userID := range coupon.AllowedUsers {
+
+		if s.hasUsed(coupon, userID) {
+			continue
+		}
+
+		user, err := s.userRepository.FindByUserID(ctx, userID)
+		if err != nil || user == nil {
+			log.Printf("failed to find user %s for coupon %s: %v", userID.Hex(), coupon.CodeCoupon, err)
+			continue
+		}
+
+		html := build(*coupon, user.LastName+" "+user.FristName, "Football Shop")
+		if err := s.emailService.SendEmail(user.Email, subject+" #"+coupon.CodeCoupon, html); err != nil {
+			log.Printf("failed to send coupon email to %s: %v", user.Email, err)
+		}
+	}
+}
+
+func (s *couponService) hasUsed(coupon *Coupon, userID primitive.ObjectID) bool {
+
+	for _, used := range coupon.UserIsUsed {
+		if used == userID {
+			return true
+		}
+	}
+
+	return false
+}
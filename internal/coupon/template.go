@@ -0,0 +1,78 @@
+package coupon
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+func BuildCouponGrantedEmailHTML(coupon Coupon, fullName string, brandName string) string {
+	return buildCouponEmailHTML(
+		brandName,
+		"Bạn vừa nhận được một mã giảm giá 🎁",
+		fmt.Sprintf("Xin chào %s, <strong>%s</strong> gửi tặng bạn mã giảm giá dưới đây.", html.EscapeString(fullName), brandName),
+		coupon,
+	)
+}
+
+func BuildCouponExpiryReminderEmailHTML(coupon Coupon, fullName string, brandName string) string {
+	return buildCouponEmailHTML(
+		brandName,
+		"Mã giảm giá của bạn sắp hết hạn ⏰",
+		fmt.Sprintf("Xin chào %s, mã giảm giá của bạn sẽ hết hạn vào <strong>%s</strong>. Đừng bỏ lỡ nhé!", html.EscapeString(fullName), coupon.ExpiredAt.Format("02/01/2006 15:04")),
+		coupon,
+	)
+}
+
+func buildCouponEmailHTML(brandName string, heading string, intro string, coupon Coupon) string {
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body style="margin:0; background:#f6f7f9; font-family:system-ui,-apple-system,Segoe UI,Roboto,Helvetica,Arial,sans-serif;">
+  <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="background:#f6f7f9; padding:24px 0;">
+    <tr>
+      <td align="center">
+        <table role="presentation" width="640" cellspacing="0" cellpadding="0" style="max-width:640px; width:100%%; background:#ffffff; border-radius:16px; overflow:hidden; box-shadow:0 4px 24px rgba(0,0,0,0.06);">
+          <tr>
+            <td style="background:linear-gradient(135deg,#0ea5e9,#6366f1); padding:20px; color:#eaf2ff; font-weight:800; font-size:18px;">%s</td>
+          </tr>
+          <tr>
+            <td style="padding:24px;">
+              <div style="font-size:18px; font-weight:700; color:#111; margin-bottom:6px;">%s</div>
+              <div style="font-size:14px; color:#444;">%s</div>
+            </td>
+          </tr>
+          <tr>
+            <td style="padding:0 24px 24px 24px;">
+              <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="background:#f8fafc; border:1px dashed #6366f1; border-radius:12px;">
+                <tr>
+                  <td style="padding:16px;" align="center">
+                    <div style="font-size:12px; color:#64748b;">%s</div>
+                    <div style="font-size:24px; font-weight:800; letter-spacing:2px; color:#111; margin-top:4px;">%s</div>
+                    <div style="font-size:14px; color:#16a34a; margin-top:4px;">Giảm %s%%</div>
+                    <div style="font-size:12px; color:#64748b; margin-top:4px;">Hạn sử dụng: %s</div>
+                  </td>
+                </tr>
+              </table>
+            </td>
+          </tr>
+        </table>
+      </td>
+    </tr>
+  </table>
+</body>
+</html>`,
+		brandName,
+		brandName,
+		heading,
+		intro,
+		html.EscapeString(coupon.Name),
+		html.EscapeString(coupon.CodeCoupon),
+		strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", coupon.Discount), "0"), "."),
+		coupon.ExpiredAt.Format("02/01/2006 15:04"),
+	)
+}
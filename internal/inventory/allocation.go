@@ -0,0 +1,238 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AllocateOrder takes the stock of every order line from the warehouses in
+// priority order, preferring a single warehouse per line. When a line cannot
+// be allocated, the lines already allocated are released.
+func (s *inventoryService) AllocateOrder(ctx context.Context, lines []OrderLine, change model.StockChange) ([][]model.StockAllocation, error) {
+
+	warehouses, err := s.repository.FindWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations [][]model.StockAllocation
+
+	for i, line := range lines {
+		allocated, err := s.allocateLine(ctx, line, warehouses, change)
+		if err != nil {
+			done := make([]OrderLine, i)
+			for j := range done {
+				done[j] = lines[j]
+				done[j].Allocations = allocations[j]
+			}
+
+			if err := s.ReleaseOrder(ctx, done, rollbackChange(change)); err != nil {
+				log.Printf("failed to release stock: %v", err)
+			}
+			return nil, err
+		}
+
+		allocations = append(allocations, allocated)
+	}
+
+	return allocations, nil
+}
+
+func (s *inventoryService) allocateLine(ctx context.Context, line OrderLine, warehouses []*Warehouse, change model.StockChange) ([]model.StockAllocation, error) {
+
+	p, err := s.productRepository.FindByID(ctx, line.ProductID)
+	if err != nil || p == nil {
+		return nil, fmt.Errorf("product %s not found", line.ProductID.Hex())
+	}
+
+	// Components need not be sold on their own, only the bundle must be
+	sold := p
+	if line.BundleID != nil {
+		sold, err = s.productRepository.FindByID(ctx, *line.BundleID)
+		if err != nil || sold == nil {
+			return nil, fmt.Errorf("product %s not found", line.BundleID.Hex())
+		}
+	}
+
+	if !sold.IsVisible() {
+		return nil, fmt.Errorf("product %s is no longer available", sold.ProductName)
+	}
+
+	variant := p.FindVariant(line.VariantID)
+	if variant == nil {
+		return nil, fmt.Errorf("variant %s not found", line.VariantID.Hex())
+	}
+
+	if len(variant.Components) > 0 {
+		return nil, fmt.Errorf("bundle %s must be allocated by its components", p.ProductName)
+	}
+
+	levels, err := s.repository.FindLevelsByProductID(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	onHand := make(map[primitive.ObjectID]int)
+	for _, level := range levels {
+		if level.VariantID == variant.ID {
+			onHand[level.WarehouseID] = level.OnHand
+		}
+	}
+
+	plan := planAllocation(line.Quantity, warehouses, onHand)
+	if plan == nil {
+		return nil, fmt.Errorf("not enough stock for variant %s", variant.SKU)
+	}
+
+	for i, allocation := range plan {
+		err := s.adjustLevel(ctx, allocation.WarehouseID, p.ID, variant.ID, variant.SKU, -allocation.Quantity, change)
+		if err != nil {
+			// Another order took the stock since it was read
+			s.returnStock(ctx, p, variant, plan[:i], rollbackChange(change))
+			return nil, fmt.Errorf("not enough stock for variant %s", variant.SKU)
+		}
+	}
+
+	s.mirrorVariantStock(ctx, p.ID, variant, -line.Quantity)
+
+	return plan, nil
+}
+
+func rollbackChange(change model.StockChange) model.StockChange {
+	change.Type = model.MovementCancellation
+	change.Reason = "allocation rolled back"
+	return change
+}
+
+// planAllocation picks the warehouses a quantity is taken from, or returns nil
+// when the active warehouses do not hold enough.
+func planAllocation(quantity int, warehouses []*Warehouse, onHand map[primitive.ObjectID]int) []model.StockAllocation {
+
+	for _, warehouse := range warehouses {
+		if warehouse.Active && onHand[warehouse.ID] >= quantity {
+			return []model.StockAllocation{{WarehouseID: warehouse.ID, Quantity: quantity}}
+		}
+	}
+
+	var plan []model.StockAllocation
+	remaining := quantity
+
+	for _, warehouse := range warehouses {
+		if remaining == 0 {
+			break
+		}
+
+		take := min(onHand[warehouse.ID], remaining)
+		if !warehouse.Active || take <= 0 {
+			continue
+		}
+
+		plan = append(plan, model.StockAllocation{WarehouseID: warehouse.ID, Quantity: take})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil
+	}
+
+	return plan
+}
+
+// ReleaseOrder puts the stock of the order lines back where it was taken from.
+func (s *inventoryService) ReleaseOrder(ctx context.Context, lines []OrderLine, change model.StockChange) error {
+
+	var failed error
+
+	for _, line := range lines {
+		p, err := s.productRepository.FindByID(ctx, line.ProductID)
+		if err != nil || p == nil {
+			failed = fmt.Errorf("product %s not found", line.ProductID.Hex())
+			continue
+		}
+
+		variant := p.FindVariant(line.VariantID)
+		if variant == nil {
+			failed = fmt.Errorf("variant %s not found", line.VariantID.Hex())
+			continue
+		}
+
+		s.returnStock(ctx, p, variant, line.Allocations, change)
+	}
+
+	return failed
+}
+
+// HandleOrderStatus is called whenever an order changes status. Cancelled
+// orders and failed payments give their stock back and refunded orders take
+// their returns back. An order that comes back after that is allocated anew
+// and the new allocations are returned for the caller to save.
+func (s *inventoryService) HandleOrderStatus(ctx context.Context, orderID primitive.ObjectID, status string, lines []OrderLine) ([][]model.StockAllocation, error) {
+
+	movements, err := s.movementRepository.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Orders placed before the ledger have nothing to give back
+	if len(movements) == 0 {
+		return nil, nil
+	}
+
+	taken := 0
+	for _, movement := range movements {
+		taken -= movement.Quantity
+	}
+
+	switch status {
+	case string(model.OrderCancelled), string(model.OrderRefunded), string(model.PaymentFailed):
+		if taken <= 0 {
+			return nil, nil
+		}
+
+		movementType := model.MovementCancellation
+		if status == string(model.OrderRefunded) {
+			movementType = model.MovementReturn
+		}
+
+		change := model.StockChange{Type: movementType, Reason: "order " + status, OrderID: &orderID}
+
+		return nil, s.ReleaseOrder(ctx, lines, change)
+
+	default:
+		if taken > 0 {
+			return nil, nil
+		}
+
+		change := model.StockChange{Type: model.MovementSale, Reason: "order reopened", OrderID: &orderID}
+
+		return s.AllocateOrder(ctx, lines, change)
+	}
+}
+
+func (s *inventoryService) returnStock(ctx context.Context, p *product.Product, variant *product.Variant, allocations []model.StockAllocation, change model.StockChange) {
+
+	active, err := s.activeWarehouses(ctx)
+	if err != nil {
+		log.Printf("failed to load warehouses: %v", err)
+	}
+
+	returned := 0
+
+	for _, allocation := range allocations {
+		if err := s.adjustLevel(ctx, allocation.WarehouseID, p.ID, variant.ID, variant.SKU, allocation.Quantity, change); err != nil {
+			log.Printf("failed to return %d of %s to warehouse %s: %v", allocation.Quantity, variant.SKU, allocation.WarehouseID.Hex(), err)
+			continue
+		}
+
+		if active[allocation.WarehouseID] {
+			returned += allocation.Quantity
+		}
+	}
+
+	s.mirrorVariantStock(ctx, p.ID, variant, returned)
+}
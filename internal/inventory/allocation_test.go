@@ -0,0 +1,210 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type levelKey struct {
+	warehouseID primitive.ObjectID
+	variantID   primitive.ObjectID
+}
+
+// fakeInventoryRepository keeps the stock levels in memory and, like the
+// real repository, refuses to take more than a warehouse holds.
+type fakeInventoryRepository struct {
+	InventoryRepository
+	warehouses []*Warehouse
+	levels     map[levelKey]int
+	productID  primitive.ObjectID
+}
+
+func (r *fakeInventoryRepository) FindWarehouses(ctx context.Context) ([]*Warehouse, error) {
+	return r.warehouses, nil
+}
+
+func (r *fakeInventoryRepository) FindLevelsByProductID(ctx context.Context, productID primitive.ObjectID) ([]*StockLevel, error) {
+	var levels []*StockLevel
+	for key, onHand := range r.levels {
+		levels = append(levels, &StockLevel{WarehouseID: key.warehouseID, ProductID: productID, VariantID: key.variantID, OnHand: onHand})
+	}
+	return levels, nil
+}
+
+func (r *fakeInventoryRepository) AdjustLevel(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, sku string, quantity int) error {
+	key := levelKey{warehouseID, variantID}
+	if r.levels[key]+quantity < 0 {
+		return fmt.Errorf("not enough stock of %s at the warehouse", sku)
+	}
+	r.levels[key] += quantity
+	return nil
+}
+
+type fakeMovementRepository struct {
+	MovementRepository
+	movements []*Movement
+}
+
+func (r *fakeMovementRepository) Create(ctx context.Context, movement *Movement) error {
+	r.movements = append(r.movements, movement)
+	return nil
+}
+
+type fakeProductRepository struct {
+	product.ProductRepository
+	products map[primitive.ObjectID]*product.Product
+}
+
+func (r *fakeProductRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*product.Product, error) {
+	return r.products[id], nil
+}
+
+func (r *fakeProductRepository) AdjustVariantStock(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, size string, quantity int) error {
+	return nil
+}
+
+func (r *fakeProductRepository) FindBundlesByComponent(ctx context.Context, variantID primitive.ObjectID) ([]*product.Product, error) {
+	return nil, nil
+}
+
+func TestPlanAllocation(t *testing.T) {
+
+	main := &Warehouse{ID: primitive.NewObjectID(), Active: true}
+	second := &Warehouse{ID: primitive.NewObjectID(), Active: true}
+	closed := &Warehouse{ID: primitive.NewObjectID(), Active: false}
+	warehouses := []*Warehouse{main, second, closed}
+
+	tests := []struct {
+		name     string
+		quantity int
+		onHand   map[primitive.ObjectID]int
+		want     []model.StockAllocation
+	}{
+		{
+			name:     "first warehouse holds everything",
+			quantity: 3,
+			onHand:   map[primitive.ObjectID]int{main.ID: 5, second.ID: 5},
+			want:     []model.StockAllocation{{WarehouseID: main.ID, Quantity: 3}},
+		},
+		{
+			name:     "single warehouse preferred over a split",
+			quantity: 4,
+			onHand:   map[primitive.ObjectID]int{main.ID: 2, second.ID: 4},
+			want:     []model.StockAllocation{{WarehouseID: second.ID, Quantity: 4}},
+		},
+		{
+			name:     "split in priority order",
+			quantity: 5,
+			onHand:   map[primitive.ObjectID]int{main.ID: 2, second.ID: 4},
+			want: []model.StockAllocation{
+				{WarehouseID: main.ID, Quantity: 2},
+				{WarehouseID: second.ID, Quantity: 3},
+			},
+		},
+		{
+			name:     "inactive warehouse is skipped",
+			quantity: 3,
+			onHand:   map[primitive.ObjectID]int{main.ID: 1, closed.ID: 10},
+			want:     nil,
+		},
+		{
+			name:     "not enough stock",
+			quantity: 7,
+			onHand:   map[primitive.ObjectID]int{main.ID: 2, second.ID: 4},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planAllocation(tt.quantity, warehouses, tt.onHand)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planAllocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateOrder(t *testing.T) {
+
+	main := &Warehouse{ID: primitive.NewObjectID(), Active: true}
+	second := &Warehouse{ID: primitive.NewObjectID(), Active: true}
+
+	shirt := product.Variant{ID: primitive.NewObjectID(), SKU: "SHIRT-M"}
+	socks := product.Variant{ID: primitive.NewObjectID(), SKU: "SOCKS-L"}
+	p := &product.Product{ID: primitive.NewObjectID(), ProductName: "Kit", Variants: []product.Variant{shirt, socks}}
+
+	tests := []struct {
+		name       string
+		lines      []OrderLine
+		want       [][]model.StockAllocation
+		wantErr    bool
+		wantLevels map[levelKey]int
+	}{
+		{
+			name: "lines split across warehouses",
+			lines: []OrderLine{
+				{ProductID: p.ID, VariantID: shirt.ID, Quantity: 4},
+				{ProductID: p.ID, VariantID: socks.ID, Quantity: 1},
+			},
+			want: [][]model.StockAllocation{
+				{{WarehouseID: main.ID, Quantity: 3}, {WarehouseID: second.ID, Quantity: 1}},
+				{{WarehouseID: main.ID, Quantity: 1}},
+			},
+			wantLevels: map[levelKey]int{
+				{main.ID, shirt.ID}:   0,
+				{second.ID, shirt.ID}: 1,
+				{main.ID, socks.ID}:   1,
+			},
+		},
+		{
+			name: "failed line releases the lines before it",
+			lines: []OrderLine{
+				{ProductID: p.ID, VariantID: shirt.ID, Quantity: 4},
+				{ProductID: p.ID, VariantID: socks.ID, Quantity: 3},
+			},
+			wantErr: true,
+			wantLevels: map[levelKey]int{
+				{main.ID, shirt.ID}:   3,
+				{second.ID, shirt.ID}: 2,
+				{main.ID, socks.ID}:   2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeInventoryRepository{
+				warehouses: []*Warehouse{main, second},
+				levels: map[levelKey]int{
+					{main.ID, shirt.ID}:   3,
+					{second.ID, shirt.ID}: 2,
+					{main.ID, socks.ID}:   2,
+				},
+			}
+
+			s := &inventoryService{
+				repository:         repository,
+				movementRepository: &fakeMovementRepository{},
+				productRepository:  &fakeProductRepository{products: map[primitive.ObjectID]*product.Product{p.ID: p}},
+			}
+
+			got, err := s.AllocateOrder(context.Background(), tt.lines, model.StockChange{Type: model.MovementSale})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AllocateOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllocateOrder() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(repository.levels, tt.wantLevels) {
+				t.Errorf("levels = %v, want %v", repository.levels, tt.wantLevels)
+			}
+		})
+	}
+}
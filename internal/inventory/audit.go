@@ -0,0 +1,481 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/user"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxReportDays bounds the date range of a movement report.
+const maxReportDays = 366
+
+// CreateStockTake starts a count of the given products, or of everything, at
+// a warehouse. Only one count per warehouse can be open.
+func (s *inventoryService) CreateStockTake(ctx context.Context, userID string, req *CreateStockTakeRequest) (*StockTake, error) {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := s.findWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, open, err := s.stockTakeRepository.FindAll(ctx, bson.M{"warehouse_id": warehouse.ID, "status": StockTakeOpen}, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if open > 0 {
+		return nil, fmt.Errorf("warehouse %s already has an open stock take", warehouse.Code)
+	}
+
+	var lines []StockTakeLine
+
+	if len(req.ProductIDs) == 0 {
+		levels, err := s.repository.FindLevelsByWarehouseID(ctx, warehouse.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, level := range levels {
+			lines = append(lines, StockTakeLine{
+				ProductID:      level.ProductID,
+				VariantID:      level.VariantID,
+				SKU:            level.SKU,
+				SystemQuantity: level.OnHand,
+			})
+		}
+	} else {
+		for _, id := range req.ProductIDs {
+			productLines, err := s.stockTakeLines(ctx, warehouse.ID, id)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, productLines...)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("nothing to count at warehouse %s", warehouse.Code)
+	}
+
+	now := time.Now()
+
+	stockTake := &StockTake{
+		ID:          primitive.NewObjectID(),
+		WarehouseID: warehouse.ID,
+		Status:      StockTakeOpen,
+		Note:        req.Note,
+		Lines:       lines,
+		CreatedBy:   staff.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.stockTakeRepository.Create(ctx, stockTake); err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+func (s *inventoryService) stockTakeLines(ctx context.Context, warehouseID primitive.ObjectID, productID string) ([]StockTakeLine, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	p, err := s.productRepository.FindByID(ctx, objectID)
+	if err != nil || p == nil {
+		return nil, fmt.Errorf("product %s not found", productID)
+	}
+
+	if p.IsBundle() {
+		return nil, fmt.Errorf("bundle %s is counted through its components", p.ProductName)
+	}
+
+	levels, err := s.repository.FindLevelsByProductID(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	onHand := make(map[primitive.ObjectID]int)
+	for _, level := range levels {
+		if level.WarehouseID == warehouseID {
+			onHand[level.VariantID] = level.OnHand
+		}
+	}
+
+	var lines []StockTakeLine
+	for _, v := range p.Variants {
+		lines = append(lines, StockTakeLine{
+			ProductID:      p.ID,
+			VariantID:      v.ID,
+			SKU:            v.SKU,
+			SystemQuantity: onHand[v.ID],
+		})
+	}
+
+	return lines, nil
+}
+
+func (s *inventoryService) RecordCounts(ctx context.Context, userID string, id string, req *StockTakeCountsRequest) (*StockTake, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	stockTake, err := s.findOpenStockTake(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Counts) == 0 {
+		return nil, fmt.Errorf("counts are required")
+	}
+
+	index := make(map[string]int)
+	for i, line := range stockTake.Lines {
+		index[line.VariantID.Hex()] = i
+	}
+
+	levels, err := s.repository.FindLevelsByWarehouseID(ctx, stockTake.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	onHand := make(map[primitive.ObjectID]int)
+	for _, level := range levels {
+		onHand[level.VariantID] = level.OnHand
+	}
+
+	for _, count := range req.Counts {
+		i, ok := index[count.VariantID]
+		if !ok {
+			return nil, fmt.Errorf("variant %s is not part of the stock take", count.VariantID)
+		}
+
+		if count.Counted < 0 {
+			return nil, fmt.Errorf("counted quantity of %s cannot be negative", stockTake.Lines[i].SKU)
+		}
+
+		if stockTake.Lines[i].Posted {
+			return nil, fmt.Errorf("count of %s is already posted", stockTake.Lines[i].SKU)
+		}
+
+		counted := count.Counted
+		stockTake.Lines[i].CountedQuantity = &counted
+		stockTake.Lines[i].SystemQuantity = onHand[stockTake.Lines[i].VariantID]
+	}
+
+	stockTake.UpdatedAt = time.Now()
+
+	if err := s.stockTakeRepository.Update(ctx, stockTake); err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+// PostStockTake books the difference between each count and the stock at
+// the time of counting, so later sales and receipts are kept. Lines are marked
+// posted one by one, so posting again after a failure only books the rest.
+func (s *inventoryService) PostStockTake(ctx context.Context, userID string, id string) (*StockTake, error) {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stockTake, err := s.findOpenStockTake(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := s.repository.FindWarehouseByID(ctx, stockTake.WarehouseID)
+	if err != nil || warehouse == nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	change := model.StockChange{
+		Type:        model.MovementStockTake,
+		Reason:      "stock take",
+		ActorID:     &staff.ID,
+		ReferenceID: &stockTake.ID,
+	}
+
+	counted := 0
+
+	for i, line := range stockTake.Lines {
+		if line.CountedQuantity == nil {
+			continue
+		}
+		counted++
+
+		if line.Posted {
+			continue
+		}
+
+		difference := *line.CountedQuantity - line.SystemQuantity
+
+		if difference != 0 {
+			if err := s.adjustLevel(ctx, warehouse.ID, line.ProductID, line.VariantID, line.SKU, difference, change); err != nil {
+				stockTake.UpdatedAt = time.Now()
+				if updateErr := s.stockTakeRepository.Update(ctx, stockTake); updateErr != nil {
+					log.Printf("failed to save stock take %s: %v", stockTake.ID.Hex(), updateErr)
+				}
+				return nil, fmt.Errorf("failed to post count of %s: %v", line.SKU, err)
+			}
+
+			if warehouse.Active {
+				s.mirrorStock(ctx, line.ProductID, line.VariantID, difference)
+			}
+		}
+
+		stockTake.Lines[i].Difference = difference
+		stockTake.Lines[i].Posted = true
+	}
+
+	if counted == 0 {
+		return nil, fmt.Errorf("no line has been counted")
+	}
+
+	now := time.Now()
+	stockTake.Status = StockTakePosted
+	stockTake.PostedBy = &staff.ID
+	stockTake.PostedAt = &now
+	stockTake.UpdatedAt = now
+
+	if err := s.stockTakeRepository.Update(ctx, stockTake); err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+func (s *inventoryService) CancelStockTake(ctx context.Context, userID string, id string) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	stockTake, err := s.findOpenStockTake(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	stockTake.Status = StockTakeCancelled
+	stockTake.UpdatedAt = time.Now()
+
+	return s.stockTakeRepository.Update(ctx, stockTake)
+}
+
+func (s *inventoryService) GetStockTake(ctx context.Context, userID string, id string) (*StockTake, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	return s.findStockTake(ctx, id)
+}
+
+func (s *inventoryService) GetStockTakes(ctx context.Context, userID string, filter *StockTakeFilter) (*StockTakesResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	} else if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	query := bson.M{}
+
+	if filter.WarehouseID != "" {
+		warehouseID, err := primitive.ObjectIDFromHex(filter.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid warehouse id: %v", err)
+		}
+		query["warehouse_id"] = warehouseID
+	}
+
+	switch filter.Status {
+	case "":
+	case StockTakeOpen, StockTakePosted, StockTakeCancelled:
+		query["status"] = filter.Status
+	default:
+		return nil, fmt.Errorf("invalid status %q", filter.Status)
+	}
+
+	stockTakes, total, err := s.stockTakeRepository.FindAll(ctx, query, filter.Page, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if stockTakes == nil {
+		stockTakes = []*StockTake{}
+	}
+
+	result := &StockTakesResponse{
+		StockTakes: stockTakes,
+		Pagination: product.Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		},
+	}
+
+	return result, nil
+}
+
+func (s *inventoryService) findStockTake(ctx context.Context, id string) (*StockTake, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stock take id: %v", err)
+	}
+
+	stockTake, err := s.stockTakeRepository.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stockTake == nil {
+		return nil, fmt.Errorf("stock take not found")
+	}
+
+	return stockTake, nil
+}
+
+func (s *inventoryService) findOpenStockTake(ctx context.Context, id string) (*StockTake, error) {
+
+	stockTake, err := s.findStockTake(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if stockTake.Status != StockTakeOpen {
+		return nil, fmt.Errorf("stock take is %s", stockTake.Status)
+	}
+
+	return stockTake, nil
+}
+
+// GetMovementReport lists the movements of a product between two dates, 30
+// days up to today by default.
+func (s *inventoryService) GetMovementReport(ctx context.Context, userID string, req *MovementReportRequest) (*MovementReportResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	productID, err := primitive.ObjectIDFromHex(req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -30)
+	to := today
+
+	if req.From != "" {
+		if from, err = time.Parse("2006-01-02", req.From); err != nil {
+			return nil, fmt.Errorf("invalid from date: %v", err)
+		}
+	}
+
+	if req.To != "" {
+		if to, err = time.Parse("2006-01-02", req.To); err != nil {
+			return nil, fmt.Errorf("invalid to date: %v", err)
+		}
+	}
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", maxReportDays)
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 50
+	} else if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	query := bson.M{
+		"product_id": productID,
+		"created_at": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)},
+	}
+
+	if req.WarehouseID != "" {
+		warehouseID, err := primitive.ObjectIDFromHex(req.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid warehouse id: %v", err)
+		}
+		query["warehouse_id"] = warehouseID
+	}
+
+	if req.Type != "" {
+		query["type"] = req.Type
+	}
+
+	totals, err := s.movementRepository.SumByType(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, total, err := s.movementRepository.FindAll(ctx, query, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if movements == nil {
+		movements = []*Movement{}
+	}
+
+	net := 0
+	for _, quantity := range totals {
+		net += quantity
+	}
+
+	result := &MovementReportResponse{
+		ProductID: productID,
+		From:      from,
+		To:        to,
+		Totals:    totals,
+		Net:       net,
+		Movements: movements,
+		Pagination: product.Pagination{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: (total + int64(req.Limit) - 1) / int64(req.Limit),
+		},
+	}
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"context"
+	"log"
+	"modular_monolith/internal/product"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bundleAvailable is the number of complete bundles the sellable stock of
+// the components makes up.
+func (s *inventoryService) bundleAvailable(ctx context.Context, variant *product.Variant) (int, error) {
+
+	available := -1
+
+	for _, component := range variant.Components {
+		stock, err := s.levelStock(ctx, component.ProductID, component.VariantID)
+		if err != nil {
+			return 0, err
+		}
+
+		sets := stock / component.Quantity
+		if available < 0 || sets < available {
+			available = sets
+		}
+	}
+
+	return max(available, 0), nil
+}
+
+// refreshBundle saves the stock derived from the components on the bundle.
+func (s *inventoryService) refreshBundle(ctx context.Context, bundle *product.Product) error {
+
+	stock := make(map[primitive.ObjectID]int)
+
+	for i := range bundle.Variants {
+		available, err := s.bundleAvailable(ctx, &bundle.Variants[i])
+		if err != nil {
+			return err
+		}
+		stock[bundle.Variants[i].ID] = available
+	}
+
+	bundle.ApplyStock(stock)
+
+	return s.productRepository.SetVariantStock(ctx, bundle.ID, bundle.Variants, bundle.Sizes)
+}
+
+// refreshBundlesOf refreshes the bundles a variant is a component of after its
+// stock changed.
+func (s *inventoryService) refreshBundlesOf(ctx context.Context, variantID primitive.ObjectID) {
+
+	bundles, err := s.productRepository.FindBundlesByComponent(ctx, variantID)
+	if err != nil {
+		log.Printf("failed to find bundles of variant %s: %v", variantID.Hex(), err)
+		return
+	}
+
+	for _, bundle := range bundles {
+		if err := s.refreshBundle(ctx, bundle); err != nil {
+			log.Printf("failed to refresh stock of bundle %s: %v", bundle.ID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package inventory
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	InventoryService InventoryService
+}
+
+func NewInventoryHandler(inventoryService InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		InventoryService: inventoryService,
+	}
+}
+
+func (h *InventoryHandler) GetWarehouses(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	warehouses, err := h.InventoryService.GetWarehouses(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", warehouses)
+}
+
+func (h *InventoryHandler) CreateWarehouse(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	warehouse, err := h.InventoryService.CreateWarehouse(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", warehouse)
+}
+
+func (h *InventoryHandler) UpdateWarehouse(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.InventoryService.UpdateWarehouse(c, userID, c.Param("id"), &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *InventoryHandler) GetProductStock(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	stock, err := h.InventoryService.GetProductStock(c, userID, c.Param("product_id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stock)
+}
+
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.InventoryService.AdjustStock(c, userID, &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *InventoryHandler) TransferStock(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	transfer, err := h.InventoryService.TransferStock(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", transfer)
+}
+
+func (h *InventoryHandler) GetTransfers(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter TransferFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	transfers, err := h.InventoryService.GetTransfers(c, userID, &filter)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", transfers)
+}
+
+func (h *InventoryHandler) CreateStockTake(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	stockTake, err := h.InventoryService.CreateStockTake(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", stockTake)
+}
+
+func (h *InventoryHandler) GetStockTakes(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter StockTakeFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	stockTakes, err := h.InventoryService.GetStockTakes(c, userID, &filter)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stockTakes)
+}
+
+func (h *InventoryHandler) GetStockTake(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	stockTake, err := h.InventoryService.GetStockTake(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stockTake)
+}
+
+func (h *InventoryHandler) RecordCounts(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req StockTakeCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	stockTake, err := h.InventoryService.RecordCounts(c, userID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stockTake)
+}
+
+func (h *InventoryHandler) PostStockTake(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	stockTake, err := h.InventoryService.PostStockTake(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stockTake)
+}
+
+func (h *InventoryHandler) CancelStockTake(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.InventoryService.CancelStockTake(c, userID, c.Param("id")); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *InventoryHandler) GetMovementReport(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req MovementReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	report, err := h.InventoryService.GetMovementReport(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", report)
+}
+
+func (h *InventoryHandler) SetThreshold(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SetThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.InventoryService.SetThreshold(c, userID, &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *InventoryHandler) GetLowStock(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.InventoryService.GetLowStock(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", alerts)
+}
+
+func (h *InventoryHandler) GetReorderSuggestions(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.InventoryService.GetReorderSuggestions(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", suggestions)
+}
@@ -0,0 +1,123 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Movement is one entry of the stock ledger. Quantity is negative when stock
+// leaves the warehouse.
+type Movement struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	WarehouseID primitive.ObjectID  `json:"warehouse_id" bson:"warehouse_id"`
+	ProductID   primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID   primitive.ObjectID  `json:"variant_id" bson:"variant_id"`
+	SKU         string              `json:"sku" bson:"sku"`
+	Type        string              `json:"type" bson:"type"`
+	Quantity    int                 `json:"quantity" bson:"quantity"`
+	Reason      string              `json:"reason" bson:"reason"`
+	ActorID     *primitive.ObjectID `json:"actor_id" bson:"actor_id"`
+	OrderID     *primitive.ObjectID `json:"order_id" bson:"order_id"`
+	ReferenceID *primitive.ObjectID `json:"reference_id" bson:"reference_id"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+}
+
+type MovementRepository interface {
+	Create(ctx context.Context, movement *Movement) error
+	FindAll(ctx context.Context, filter bson.M, page int, limit int) ([]*Movement, int64, error)
+	SumByType(ctx context.Context, filter bson.M) (map[string]int, error)
+	FindByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]*Movement, error)
+}
+
+type movementRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMovementRepository(collection *mongo.Collection) MovementRepository {
+	return &movementRepository{
+		collection: collection,
+	}
+}
+
+func (r *movementRepository) Create(ctx context.Context, movement *Movement) error {
+	_, err := r.collection.InsertOne(ctx, movement)
+	return err
+}
+
+func (r *movementRepository) FindAll(ctx context.Context, filter bson.M, page int, limit int) ([]*Movement, int64, error) {
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var movements []*Movement
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// SumByType adds up the quantities of the matching movements per type.
+func (r *movementRepository) SumByType(ctx context.Context, filter bson.M) (map[string]int, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$type",
+			"quantity": bson.M{"$sum": "$quantity"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Type     string `bson:"_id"`
+		Quantity int    `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int)
+	for _, result := range results {
+		totals[result.Type] = result.Quantity
+	}
+
+	return totals, nil
+}
+
+func (r *movementRepository) FindByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]*Movement, error) {
+
+	var movements []*Movement
+
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &movements); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
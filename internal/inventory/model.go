@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"modular_monolith/internal/shared/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultWarehouseCode is the warehouse stock lands in when it is set on the
+// product rather than on a location.
+const DefaultWarehouseCode = "MAIN"
+
+type Warehouse struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Code      string             `json:"code" bson:"code"`
+	Name      string             `json:"name" bson:"name"`
+	Address   string             `json:"address" bson:"address"`
+	Priority  int                `json:"priority" bson:"priority"`
+	Active    bool               `json:"active" bson:"active"`
+	IsDefault bool               `json:"is_default" bson:"is_default"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// StockLevel is the on-hand quantity of one variant at one warehouse.
+type StockLevel struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	WarehouseID primitive.ObjectID `json:"warehouse_id" bson:"warehouse_id"`
+	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID   primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU         string             `json:"sku" bson:"sku"`
+	OnHand      int                `json:"on_hand" bson:"on_hand"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type Transfer struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	FromWarehouseID primitive.ObjectID `json:"from_warehouse_id" bson:"from_warehouse_id"`
+	ToWarehouseID   primitive.ObjectID `json:"to_warehouse_id" bson:"to_warehouse_id"`
+	ProductID       primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID       primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU             string             `json:"sku" bson:"sku"`
+	Quantity        int                `json:"quantity" bson:"quantity"`
+	Note            string             `json:"note" bson:"note"`
+	CreatedBy       primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// OrderLine is an order line to allocate or release. Allocations are only
+// needed to release it. BundleID is set on the lines a bundle is split into.
+type OrderLine struct {
+	ProductID   primitive.ObjectID
+	VariantID   primitive.ObjectID
+	Quantity    int
+	Allocations []model.StockAllocation
+	BundleID    *primitive.ObjectID
+}
@@ -0,0 +1,269 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/user"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetThreshold sets the low-stock threshold of a variant. A nil threshold
+// removes it, so the configured default applies again.
+func (s *inventoryService) SetThreshold(ctx context.Context, userID string, req *SetThresholdRequest) error {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
+
+	_, variant, err := s.findVariant(ctx, req.ProductID, req.VariantID)
+	if err != nil {
+		return err
+	}
+
+	if req.Threshold == nil {
+		return s.thresholdRepository.DeleteByVariantID(ctx, variant.ID)
+	}
+
+	if *req.Threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+
+	productID, _ := primitive.ObjectIDFromHex(req.ProductID)
+
+	return s.thresholdRepository.Upsert(ctx, &StockThreshold{
+		ID:        primitive.NewObjectID(),
+		ProductID: productID,
+		VariantID: variant.ID,
+		SKU:       variant.SKU,
+		Threshold: *req.Threshold,
+		UpdatedBy: staff.ID,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// GetLowStock lists the variants at or below their threshold, emptiest first.
+func (s *inventoryService) GetLowStock(ctx context.Context, userID string) ([]*StockAlert, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	return s.lowStock(ctx)
+}
+
+// GetReorderSuggestions lists the variants to reorder now, soonest to run out
+// first.
+func (s *inventoryService) GetReorderSuggestions(ctx context.Context, userID string) ([]*StockAlert, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.stockAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []*StockAlert{}
+	for _, alert := range alerts {
+		if alert.ReorderQuantity > 0 {
+			suggestions = append(suggestions, alert)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return daysOfStock(suggestions[i]) < daysOfStock(suggestions[j])
+	})
+
+	return suggestions, nil
+}
+
+// CronLowStockDigest emails the staff a digest of the variants that are out
+// of stock or running low.
+func (s *inventoryService) CronLowStockDigest(ctx context.Context) error {
+
+	alerts, err := s.lowStock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compute low stock: %w", err)
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	recipients, err := s.alertRecipients(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find alert recipients: %w", err)
+	}
+
+	html := BuildLowStockDigestEmailHTML(alerts, time.Now(), "Football Shop")
+	subject := fmt.Sprintf("Low stock digest: %d variants need attention", len(alerts))
+
+	for _, recipient := range recipients {
+		if err := s.emailService.SendEmail(recipient, subject, html); err != nil {
+			log.Printf("failed to send low stock digest to %s: %v", recipient, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *inventoryService) lowStock(ctx context.Context) ([]*StockAlert, error) {
+
+	alerts, err := s.stockAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	low := []*StockAlert{}
+	for _, alert := range alerts {
+		if alert.Available <= alert.Threshold {
+			low = append(low, alert)
+		}
+	}
+
+	sort.SliceStable(low, func(i, j int) bool {
+		return low[i].Available < low[j].Available
+	})
+
+	return low, nil
+}
+
+// stockAlerts computes the stock position of every sellable variant. Stock
+// still to arrive on open purchase orders is not suggested again.
+func (s *inventoryService) stockAlerts(ctx context.Context) ([]*StockAlert, error) {
+
+	active, err := s.activeWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var warehouseIDs []primitive.ObjectID
+	for id := range active {
+		warehouseIDs = append(warehouseIDs, id)
+	}
+
+	available, err := s.repository.SumLevelsByVariant(ctx, warehouseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds, err := s.thresholdRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdByVariant := make(map[primitive.ObjectID]int)
+	for _, threshold := range thresholds {
+		thresholdByVariant[threshold.VariantID] = threshold.Threshold
+	}
+
+	onOrder, err := s.purchaseOrderRepository.SumOpenQuantities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	velocityDays := max(s.config.VelocityDays, 1)
+
+	orders, err := s.orderRepository.FindPurchasedSince(ctx, time.Now().AddDate(0, 0, -velocityDays))
+	if err != nil {
+		return nil, err
+	}
+
+	sold := make(map[primitive.ObjectID]int)
+	for _, order := range orders {
+		for _, item := range order.OrderItems {
+			sold[item.VariantID] += item.Quantity
+			for _, component := range item.Components {
+				sold[component.VariantID] += component.Quantity * item.Quantity
+			}
+		}
+	}
+
+	products, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{Admin: true})
+	if err != nil {
+		return nil, err
+	}
+
+	horizon := float64(s.config.ReorderLeadDays + s.config.ReorderCoverDays)
+
+	var alerts []*StockAlert
+
+	for _, p := range products {
+		// Bundles are restocked through their components
+		if p.Status == product.ProductStatusArchived || p.IsBundle() {
+			continue
+		}
+
+		for _, variant := range p.Variants {
+			threshold, ok := thresholdByVariant[variant.ID]
+			if !ok {
+				threshold = s.config.LowStockThreshold
+			}
+
+			alert := &StockAlert{
+				ProductID:   p.ID,
+				ProductName: p.ProductName,
+				VariantID:   variant.ID,
+				SKU:         variant.SKU,
+				Attributes:  variant.Attributes,
+				Available:   available[variant.ID],
+				Threshold:   threshold,
+				OnOrder:     onOrder[variant.ID],
+				SoldPerDay:  float64(sold[variant.ID]) / float64(velocityDays),
+			}
+
+			if alert.SoldPerDay > 0 {
+				days := math.Round(float64(alert.Available)/alert.SoldPerDay*10) / 10
+				alert.DaysOfStock = &days
+			}
+
+			target := int(math.Ceil(alert.SoldPerDay*horizon)) + threshold
+			alert.ReorderQuantity = max(target-alert.Available-alert.OnOrder, 0)
+
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// alertRecipients returns the configured alert emails, or the email of every
+// staff member when none are configured.
+func (s *inventoryService) alertRecipients(ctx context.Context) ([]string, error) {
+
+	if len(s.config.AlertEmails) > 0 {
+		return s.config.AlertEmails, nil
+	}
+
+	users, err := s.userRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, u := range users {
+		if u.IsStaff() && u.Email != "" {
+			recipients = append(recipients, u.Email)
+		}
+	}
+
+	return recipients, nil
+}
+
+// daysOfStock sorts variants without sales after those that are selling.
+func daysOfStock(alert *StockAlert) float64 {
+
+	if alert.DaysOfStock == nil {
+		return math.Inf(1)
+	}
+
+	return *alert.DaysOfStock
+}
@@ -0,0 +1,249 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InventoryRepository interface {
+	CreateWarehouse(ctx context.Context, warehouse *Warehouse) error
+	UpdateWarehouse(ctx context.Context, warehouse *Warehouse) error
+	FindWarehouses(ctx context.Context) ([]*Warehouse, error)
+	FindWarehouseByID(ctx context.Context, id primitive.ObjectID) (*Warehouse, error)
+	FindWarehouseByCode(ctx context.Context, code string) (*Warehouse, error)
+	FindDefaultWarehouse(ctx context.Context) (*Warehouse, error)
+	UnsetDefaultWarehouse(ctx context.Context, exceptID primitive.ObjectID) error
+	FindLevelsByProductID(ctx context.Context, productID primitive.ObjectID) ([]*StockLevel, error)
+	FindLevelsByWarehouseID(ctx context.Context, warehouseID primitive.ObjectID) ([]*StockLevel, error)
+	CountLevelsByProductID(ctx context.Context, productID primitive.ObjectID) (int64, error)
+	SumLevelsByVariant(ctx context.Context, warehouseIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error)
+	AdjustLevel(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, sku string, quantity int) error
+	DeleteLevelsExcept(ctx context.Context, productID primitive.ObjectID, variantIDs []primitive.ObjectID) error
+	CreateTransfer(ctx context.Context, transfer *Transfer) error
+	FindTransfers(ctx context.Context, filter bson.M, page int, limit int) ([]*Transfer, int64, error)
+}
+
+type inventoryRepository struct {
+	warehouses *mongo.Collection
+	levels     *mongo.Collection
+	transfers  *mongo.Collection
+}
+
+func NewInventoryRepository(warehouses *mongo.Collection, levels *mongo.Collection, transfers *mongo.Collection) InventoryRepository {
+	return &inventoryRepository{
+		warehouses: warehouses,
+		levels:     levels,
+		transfers:  transfers,
+	}
+}
+
+func (r *inventoryRepository) CreateWarehouse(ctx context.Context, warehouse *Warehouse) error {
+	_, err := r.warehouses.InsertOne(ctx, warehouse)
+	return err
+}
+
+func (r *inventoryRepository) UpdateWarehouse(ctx context.Context, warehouse *Warehouse) error {
+	_, err := r.warehouses.ReplaceOne(ctx, bson.M{"_id": warehouse.ID}, warehouse)
+	return err
+}
+
+// FindWarehouses returns every warehouse in allocation order.
+func (r *inventoryRepository) FindWarehouses(ctx context.Context) ([]*Warehouse, error) {
+
+	var warehouses []*Warehouse
+
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}, {Key: "code", Value: 1}})
+
+	cursor, err := r.warehouses.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &warehouses); err != nil {
+		return nil, err
+	}
+
+	return warehouses, nil
+}
+
+func (r *inventoryRepository) findWarehouse(ctx context.Context, filter bson.M) (*Warehouse, error) {
+
+	var warehouse *Warehouse
+
+	err := r.warehouses.FindOne(ctx, filter).Decode(&warehouse)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *inventoryRepository) FindWarehouseByID(ctx context.Context, id primitive.ObjectID) (*Warehouse, error) {
+	return r.findWarehouse(ctx, bson.M{"_id": id})
+}
+
+func (r *inventoryRepository) FindWarehouseByCode(ctx context.Context, code string) (*Warehouse, error) {
+	return r.findWarehouse(ctx, bson.M{"code": code})
+}
+
+func (r *inventoryRepository) FindDefaultWarehouse(ctx context.Context) (*Warehouse, error) {
+	return r.findWarehouse(ctx, bson.M{"is_default": true})
+}
+
+func (r *inventoryRepository) UnsetDefaultWarehouse(ctx context.Context, exceptID primitive.ObjectID) error {
+
+	filter := bson.M{"is_default": true, "_id": bson.M{"$ne": exceptID}}
+	update := bson.M{"$set": bson.M{"is_default": false, "updated_at": time.Now()}}
+
+	_, err := r.warehouses.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *inventoryRepository) findLevels(ctx context.Context, filter bson.M) ([]*StockLevel, error) {
+
+	var levels []*StockLevel
+
+	cursor, err := r.levels.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &levels); err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+func (r *inventoryRepository) FindLevelsByProductID(ctx context.Context, productID primitive.ObjectID) ([]*StockLevel, error) {
+	return r.findLevels(ctx, bson.M{"product_id": productID})
+}
+
+func (r *inventoryRepository) FindLevelsByWarehouseID(ctx context.Context, warehouseID primitive.ObjectID) ([]*StockLevel, error) {
+	return r.findLevels(ctx, bson.M{"warehouse_id": warehouseID, "on_hand": bson.M{"$ne": 0}})
+}
+
+func (r *inventoryRepository) CountLevelsByProductID(ctx context.Context, productID primitive.ObjectID) (int64, error) {
+	return r.levels.CountDocuments(ctx, bson.M{"product_id": productID})
+}
+
+// SumLevelsByVariant adds up the stock held at the given warehouses per
+// variant.
+func (r *inventoryRepository) SumLevelsByVariant(ctx context.Context, warehouseIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"warehouse_id": bson.M{"$in": warehouseIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$variant_id",
+			"on_hand": bson.M{"$sum": "$on_hand"},
+		}}},
+	}
+
+	cursor, err := r.levels.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		VariantID primitive.ObjectID `bson:"_id"`
+		OnHand    int                `bson:"on_hand"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[primitive.ObjectID]int)
+	for _, result := range results {
+		totals[result.VariantID] = result.OnHand
+	}
+
+	return totals, nil
+}
+
+// AdjustLevel adds quantity to the stock of a variant at a warehouse, creating
+// the level on first receipt. Removing more than is on hand fails without
+// changing anything.
+func (r *inventoryRepository) AdjustLevel(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, sku string, quantity int) error {
+
+	filter := bson.M{
+		"warehouse_id": warehouseID,
+		"product_id":   productID,
+		"variant_id":   variantID,
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"on_hand": quantity},
+		"$set": bson.M{"sku": sku, "updated_at": time.Now()},
+	}
+
+	if quantity < 0 {
+		filter["on_hand"] = bson.M{"$gte": -quantity}
+
+		res, err := r.levels.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if res.MatchedCount == 0 {
+			return fmt.Errorf("not enough stock of %s at the warehouse", sku)
+		}
+
+		return nil
+	}
+
+	update["$setOnInsert"] = bson.M{"_id": primitive.NewObjectID()}
+
+	_, err := r.levels.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteLevelsExcept removes the levels of variants no longer on the product.
+func (r *inventoryRepository) DeleteLevelsExcept(ctx context.Context, productID primitive.ObjectID, variantIDs []primitive.ObjectID) error {
+
+	filter := bson.M{"product_id": productID}
+	if len(variantIDs) > 0 {
+		filter["variant_id"] = bson.M{"$nin": variantIDs}
+	}
+
+	_, err := r.levels.DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *inventoryRepository) CreateTransfer(ctx context.Context, transfer *Transfer) error {
+	_, err := r.transfers.InsertOne(ctx, transfer)
+	return err
+}
+
+func (r *inventoryRepository) FindTransfers(ctx context.Context, filter bson.M, page int, limit int) ([]*Transfer, int64, error) {
+
+	total, err := r.transfers.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.transfers.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var transfers []*Transfer
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
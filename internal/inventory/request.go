@@ -0,0 +1,81 @@
+package inventory
+
+type CreateWarehouseRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Priority int    `json:"priority"`
+}
+
+type UpdateWarehouseRequest struct {
+	Name      *string `json:"name"`
+	Address   *string `json:"address"`
+	Priority  *int    `json:"priority"`
+	Active    *bool   `json:"active"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+type AdjustStockRequest struct {
+	WarehouseID string `json:"warehouse_id"`
+	ProductID   string `json:"product_id"`
+	VariantID   string `json:"variant_id"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+type TransferRequest struct {
+	FromWarehouseID string `json:"from_warehouse_id"`
+	ToWarehouseID   string `json:"to_warehouse_id"`
+	ProductID       string `json:"product_id"`
+	VariantID       string `json:"variant_id"`
+	Quantity        int    `json:"quantity"`
+	Note            string `json:"note"`
+}
+
+type TransferFilter struct {
+	ProductID   string `form:"product_id"`
+	WarehouseID string `form:"warehouse_id"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+type CreateStockTakeRequest struct {
+	WarehouseID string   `json:"warehouse_id"`
+	ProductIDs  []string `json:"product_ids"`
+	Note        string   `json:"note"`
+}
+
+type StockTakeCount struct {
+	VariantID string `json:"variant_id"`
+	Counted   int    `json:"counted"`
+}
+
+type StockTakeCountsRequest struct {
+	Counts []StockTakeCount `json:"counts"`
+}
+
+type StockTakeFilter struct {
+	WarehouseID string `form:"warehouse_id"`
+	Status      string `form:"status"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+// MovementReportRequest dates are inclusive and formatted as 2006-01-02.
+type MovementReportRequest struct {
+	ProductID   string `form:"product_id"`
+	WarehouseID string `form:"warehouse_id"`
+	Type        string `form:"type"`
+	From        string `form:"from"`
+	To          string `form:"to"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+// SetThresholdRequest clears the threshold of the variant when Threshold is
+// null.
+type SetThresholdRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Threshold *int   `json:"threshold"`
+}
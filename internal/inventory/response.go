@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"modular_monolith/internal/product"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LocationStock struct {
+	WarehouseID   primitive.ObjectID `json:"warehouse_id"`
+	WarehouseCode string             `json:"warehouse_code"`
+	WarehouseName string             `json:"warehouse_name"`
+	Active        bool               `json:"active"`
+	OnHand        int                `json:"on_hand"`
+}
+
+type VariantStock struct {
+	VariantID  primitive.ObjectID `json:"variant_id"`
+	SKU        string             `json:"sku"`
+	Attributes map[string]string  `json:"attributes"`
+	Available  int                `json:"available"`
+	Locations  []LocationStock    `json:"locations"`
+}
+
+type ProductStockResponse struct {
+	ProductID   primitive.ObjectID `json:"product_id"`
+	ProductName string             `json:"product_name"`
+	Available   int                `json:"available"`
+	Variants    []VariantStock     `json:"variants"`
+}
+
+type TransfersResponse struct {
+	Transfers  []*Transfer        `json:"transfers"`
+	Pagination product.Pagination `json:"pagination"`
+}
+
+type StockTakesResponse struct {
+	StockTakes []*StockTake       `json:"stock_takes"`
+	Pagination product.Pagination `json:"pagination"`
+}
+
+// MovementReportResponse lists the movements of a product in a date range.
+// Totals add up the quantities per movement type over the whole range.
+type MovementReportResponse struct {
+	ProductID  primitive.ObjectID `json:"product_id"`
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	Totals     map[string]int     `json:"totals"`
+	Net        int                `json:"net"`
+	Movements  []*Movement        `json:"movements"`
+	Pagination product.Pagination `json:"pagination"`
+}
+
+// StockAlert is the stock position of a variant. DaysOfStock is nil when the
+// variant did not sell over the velocity period.
+type StockAlert struct {
+	ProductID       primitive.ObjectID `json:"product_id"`
+	ProductName     string             `json:"product_name"`
+	VariantID       primitive.ObjectID `json:"variant_id"`
+	SKU             string             `json:"sku"`
+	Attributes      map[string]string  `json:"attributes"`
+	Available       int                `json:"available"`
+	Threshold       int                `json:"threshold"`
+	OnOrder         int                `json:"on_order"`
+	SoldPerDay      float64            `json:"sold_per_day"`
+	DaysOfStock     *float64           `json:"days_of_stock"`
+	ReorderQuantity int                `json:"reorder_quantity"`
+}
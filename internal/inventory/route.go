@@ -0,0 +1,30 @@
+package inventory
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *InventoryHandler) {
+	inventoryGroup := r.Group("/api/v1/inventory", middleware.JWTAuthMiddleware())
+	{
+		inventoryGroup.GET("/warehouses", handler.GetWarehouses)
+		inventoryGroup.POST("/warehouses", handler.CreateWarehouse)
+		inventoryGroup.PUT("/warehouses/:id", handler.UpdateWarehouse)
+		inventoryGroup.GET("/products/:product_id", handler.GetProductStock)
+		inventoryGroup.POST("/adjustments", handler.AdjustStock)
+		inventoryGroup.GET("/transfers", handler.GetTransfers)
+		inventoryGroup.POST("/transfers", handler.TransferStock)
+		inventoryGroup.GET("/movements", handler.GetMovementReport)
+		inventoryGroup.GET("/stock-takes", handler.GetStockTakes)
+		inventoryGroup.POST("/stock-takes", handler.CreateStockTake)
+		inventoryGroup.GET("/stock-takes/:id", handler.GetStockTake)
+		inventoryGroup.PUT("/stock-takes/:id/counts", handler.RecordCounts)
+		inventoryGroup.POST("/stock-takes/:id/post", handler.PostStockTake)
+		inventoryGroup.DELETE("/stock-takes/:id", handler.CancelStockTake)
+		inventoryGroup.PUT("/thresholds", handler.SetThreshold)
+		inventoryGroup.GET("/low-stock", handler.GetLowStock)
+		inventoryGroup.GET("/reorder-suggestions", handler.GetReorderSuggestions)
+	}
+}
@@ -0,0 +1,829 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"modular_monolith/config"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/shared/ports"
+	"modular_monolith/internal/user"
+	"modular_monolith/pkg/email"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InventoryService interface {
+	GetWarehouses(ctx context.Context, userID string) ([]*Warehouse, error)
+	CreateWarehouse(ctx context.Context, userID string, req *CreateWarehouseRequest) (*Warehouse, error)
+	UpdateWarehouse(ctx context.Context, userID string, id string, req *UpdateWarehouseRequest) error
+	GetProductStock(ctx context.Context, userID string, productID string) (*ProductStockResponse, error)
+	AdjustStock(ctx context.Context, userID string, req *AdjustStockRequest) error
+	ReceiveStock(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, quantity int, change model.StockChange) error
+	TransferStock(ctx context.Context, userID string, req *TransferRequest) (*Transfer, error)
+	GetTransfers(ctx context.Context, userID string, filter *TransferFilter) (*TransfersResponse, error)
+	AvailableStock(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID) (int, error)
+	AllocateOrder(ctx context.Context, lines []OrderLine, change model.StockChange) ([][]model.StockAllocation, error)
+	ReleaseOrder(ctx context.Context, lines []OrderLine, change model.StockChange) error
+	HandleOrderStatus(ctx context.Context, orderID primitive.ObjectID, status string, lines []OrderLine) ([][]model.StockAllocation, error)
+	SyncProductStock(ctx context.Context, productID primitive.ObjectID, change model.StockChange) error
+	CreateStockTake(ctx context.Context, userID string, req *CreateStockTakeRequest) (*StockTake, error)
+	RecordCounts(ctx context.Context, userID string, id string, req *StockTakeCountsRequest) (*StockTake, error)
+	PostStockTake(ctx context.Context, userID string, id string) (*StockTake, error)
+	CancelStockTake(ctx context.Context, userID string, id string) error
+	GetStockTake(ctx context.Context, userID string, id string) (*StockTake, error)
+	GetStockTakes(ctx context.Context, userID string, filter *StockTakeFilter) (*StockTakesResponse, error)
+	GetMovementReport(ctx context.Context, userID string, req *MovementReportRequest) (*MovementReportResponse, error)
+	SetThreshold(ctx context.Context, userID string, req *SetThresholdRequest) error
+	GetLowStock(ctx context.Context, userID string) ([]*StockAlert, error)
+	GetReorderSuggestions(ctx context.Context, userID string) ([]*StockAlert, error)
+	CronLowStockDigest(ctx context.Context) error
+	MigrateStock(ctx context.Context) error
+}
+
+type inventoryService struct {
+	repository              InventoryRepository
+	movementRepository      MovementRepository
+	stockTakeRepository     StockTakeRepository
+	thresholdRepository     ThresholdRepository
+	productRepository       product.ProductRepository
+	orderRepository         ports.OrderRepository
+	purchaseOrderRepository ports.PurchaseOrderRepository
+	userRepository          user.UserRepository
+	config                  config.InventoryConfig
+	emailService            *email.EmailService
+}
+
+func NewInventoryService(repository InventoryRepository, movementRepository MovementRepository, stockTakeRepository StockTakeRepository, thresholdRepository ThresholdRepository, productRepository product.ProductRepository, orderRepository ports.OrderRepository, purchaseOrderRepository ports.PurchaseOrderRepository, userRepository user.UserRepository, config config.InventoryConfig) InventoryService {
+	emailService := email.NewEmailService()
+	return &inventoryService{
+		repository:              repository,
+		movementRepository:      movementRepository,
+		stockTakeRepository:     stockTakeRepository,
+		thresholdRepository:     thresholdRepository,
+		productRepository:       productRepository,
+		orderRepository:         orderRepository,
+		purchaseOrderRepository: purchaseOrderRepository,
+		userRepository:          userRepository,
+		config:                  config,
+		emailService:            emailService,
+	}
+}
+
+func (s *inventoryService) GetWarehouses(ctx context.Context, userID string) ([]*Warehouse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	warehouses, err := s.repository.FindWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if warehouses == nil {
+		warehouses = []*Warehouse{}
+	}
+
+	return warehouses, nil
+}
+
+func (s *inventoryService) CreateWarehouse(ctx context.Context, userID string, req *CreateWarehouseRequest) (*Warehouse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	existing, err := s.repository.FindWarehouseByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, fmt.Errorf("warehouse %s already exists", code)
+	}
+
+	now := time.Now()
+
+	warehouse := &Warehouse{
+		ID:        primitive.NewObjectID(),
+		Code:      code,
+		Name:      strings.TrimSpace(req.Name),
+		Address:   req.Address,
+		Priority:  req.Priority,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repository.CreateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (s *inventoryService) UpdateWarehouse(ctx context.Context, userID string, id string, req *UpdateWarehouseRequest) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	warehouse, err := s.findWarehouse(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return fmt.Errorf("name cannot be empty")
+		}
+		warehouse.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Address != nil {
+		warehouse.Address = *req.Address
+	}
+
+	if req.Priority != nil {
+		warehouse.Priority = *req.Priority
+	}
+
+	if req.IsDefault != nil {
+		if !*req.IsDefault && warehouse.IsDefault {
+			return fmt.Errorf("make another warehouse the default instead")
+		}
+		warehouse.IsDefault = *req.IsDefault
+	}
+
+	wasActive := warehouse.Active
+	if req.Active != nil {
+		warehouse.Active = *req.Active
+	}
+
+	if warehouse.IsDefault && !warehouse.Active {
+		return fmt.Errorf("the default warehouse cannot be deactivated")
+	}
+
+	warehouse.UpdatedAt = time.Now()
+
+	if err := s.repository.UpdateWarehouse(ctx, warehouse); err != nil {
+		return err
+	}
+
+	if warehouse.IsDefault {
+		if err := s.repository.UnsetDefaultWarehouse(ctx, warehouse.ID); err != nil {
+			return err
+		}
+	}
+
+	// Stock at inactive warehouses cannot be sold, so it leaves or rejoins
+	// the stock shown on the product
+	if wasActive != warehouse.Active {
+		sign := 1
+		if !warehouse.Active {
+			sign = -1
+		}
+
+		levels, err := s.repository.FindLevelsByWarehouseID(ctx, warehouse.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, level := range levels {
+			s.mirrorStock(ctx, level.ProductID, level.VariantID, sign*level.OnHand)
+		}
+	}
+
+	return nil
+}
+
+func (s *inventoryService) GetProductStock(ctx context.Context, userID string, productID string) (*ProductStockResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	p, err := s.productRepository.FindByID(ctx, objectID)
+	if err != nil || p == nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	warehouses, err := s.repository.FindWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := s.repository.FindLevelsByProductID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	onHand := make(map[primitive.ObjectID]map[primitive.ObjectID]int)
+	for _, level := range levels {
+		if onHand[level.VariantID] == nil {
+			onHand[level.VariantID] = make(map[primitive.ObjectID]int)
+		}
+		onHand[level.VariantID][level.WarehouseID] = level.OnHand
+	}
+
+	result := &ProductStockResponse{
+		ProductID:   p.ID,
+		ProductName: p.ProductName,
+		Variants:    []VariantStock{},
+	}
+
+	for _, v := range p.Variants {
+
+		variant := VariantStock{
+			VariantID:  v.ID,
+			SKU:        v.SKU,
+			Attributes: v.Attributes,
+			Locations:  []LocationStock{},
+		}
+
+		// Bundles hold no stock, only what their components allow
+		if len(v.Components) > 0 {
+			if variant.Available, err = s.bundleAvailable(ctx, &v); err != nil {
+				return nil, err
+			}
+			result.Available += variant.Available
+			result.Variants = append(result.Variants, variant)
+			continue
+		}
+
+		for _, warehouse := range warehouses {
+			quantity, ok := onHand[v.ID][warehouse.ID]
+			if !ok {
+				continue
+			}
+
+			if warehouse.Active {
+				variant.Available += quantity
+			}
+
+			variant.Locations = append(variant.Locations, LocationStock{
+				WarehouseID:   warehouse.ID,
+				WarehouseCode: warehouse.Code,
+				WarehouseName: warehouse.Name,
+				Active:        warehouse.Active,
+				OnHand:        quantity,
+			})
+		}
+
+		result.Available += variant.Available
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result, nil
+}
+
+// AdjustStock adds or removes stock of a variant at one warehouse.
+func (s *inventoryService) AdjustStock(ctx context.Context, userID string, req *AdjustStockRequest) error {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
+
+	if req.Quantity == 0 {
+		return fmt.Errorf("quantity cannot be 0")
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	warehouse, err := s.findWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	p, variant, err := s.findVariant(ctx, req.ProductID, req.VariantID)
+	if err != nil {
+		return err
+	}
+
+	change := model.StockChange{
+		Type:    model.MovementAdjustment,
+		Reason:  strings.TrimSpace(req.Reason),
+		ActorID: &staff.ID,
+	}
+
+	if err := s.adjustLevel(ctx, warehouse.ID, p.ID, variant.ID, variant.SKU, req.Quantity, change); err != nil {
+		return err
+	}
+
+	if warehouse.Active {
+		s.mirrorStock(ctx, p.ID, variant.ID, req.Quantity)
+	}
+
+	return nil
+}
+
+// ReceiveStock books goods received from outside the shop into a warehouse.
+func (s *inventoryService) ReceiveStock(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, quantity int, change model.StockChange) error {
+
+	if quantity <= 0 {
+		return fmt.Errorf("quantity must be greater than 0")
+	}
+
+	warehouse, err := s.repository.FindWarehouseByID(ctx, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	if warehouse == nil {
+		return fmt.Errorf("warehouse not found")
+	}
+
+	p, variant, err := s.findVariant(ctx, productID.Hex(), variantID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if err := s.adjustLevel(ctx, warehouse.ID, p.ID, variant.ID, variant.SKU, quantity, change); err != nil {
+		return err
+	}
+
+	if warehouse.Active {
+		s.mirrorVariantStock(ctx, p.ID, variant, quantity)
+	}
+
+	return nil
+}
+
+func (s *inventoryService) TransferStock(ctx context.Context, userID string, req *TransferRequest) (*Transfer, error) {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than 0")
+	}
+
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, fmt.Errorf("source and destination warehouses must differ")
+	}
+
+	from, err := s.findWarehouse(ctx, req.FromWarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.findWarehouse(ctx, req.ToWarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, variant, err := s.findVariant(ctx, req.ProductID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	transferID := primitive.NewObjectID()
+
+	change := model.StockChange{
+		Type:        model.MovementTransfer,
+		Reason:      fmt.Sprintf("transfer %s to %s", from.Code, to.Code),
+		ActorID:     &staff.ID,
+		ReferenceID: &transferID,
+	}
+
+	if err := s.adjustLevel(ctx, from.ID, p.ID, variant.ID, variant.SKU, -req.Quantity, change); err != nil {
+		return nil, fmt.Errorf("not enough stock of %s at %s", variant.SKU, from.Code)
+	}
+
+	if err := s.adjustLevel(ctx, to.ID, p.ID, variant.ID, variant.SKU, req.Quantity, change); err != nil {
+		if err := s.adjustLevel(ctx, from.ID, p.ID, variant.ID, variant.SKU, req.Quantity, change); err != nil {
+			log.Printf("failed to return %d of %s to %s: %v", req.Quantity, variant.SKU, from.Code, err)
+		}
+		return nil, err
+	}
+
+	if from.Active != to.Active {
+		quantity := req.Quantity
+		if from.Active {
+			quantity = -quantity
+		}
+		s.mirrorStock(ctx, p.ID, variant.ID, quantity)
+	}
+
+	transfer := &Transfer{
+		ID:              transferID,
+		FromWarehouseID: from.ID,
+		ToWarehouseID:   to.ID,
+		ProductID:       p.ID,
+		VariantID:       variant.ID,
+		SKU:             variant.SKU,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+		CreatedBy:       staff.ID,
+		CreatedAt:       time.Now(),
+	}
+
+	if err := s.repository.CreateTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *inventoryService) GetTransfers(ctx context.Context, userID string, filter *TransferFilter) (*TransfersResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	} else if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	query := bson.M{}
+
+	if filter.ProductID != "" {
+		productID, err := primitive.ObjectIDFromHex(filter.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product id: %v", err)
+		}
+		query["product_id"] = productID
+	}
+
+	if filter.WarehouseID != "" {
+		warehouseID, err := primitive.ObjectIDFromHex(filter.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid warehouse id: %v", err)
+		}
+		query["$or"] = bson.A{
+			bson.M{"from_warehouse_id": warehouseID},
+			bson.M{"to_warehouse_id": warehouseID},
+		}
+	}
+
+	transfers, total, err := s.repository.FindTransfers(ctx, query, filter.Page, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfers == nil {
+		transfers = []*Transfer{}
+	}
+
+	result := &TransfersResponse{
+		Transfers: transfers,
+		Pagination: product.Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		},
+	}
+
+	return result, nil
+}
+
+// AvailableStock is the stock of a variant that can be sold, i.e. the sum
+// over active warehouses, or the number of bundles the components make up.
+func (s *inventoryService) AvailableStock(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID) (int, error) {
+
+	p, err := s.productRepository.FindByID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if p != nil {
+		if variant := p.FindVariant(variantID); variant != nil && len(variant.Components) > 0 {
+			return s.bundleAvailable(ctx, variant)
+		}
+	}
+
+	return s.levelStock(ctx, productID, variantID)
+}
+
+func (s *inventoryService) levelStock(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID) (int, error) {
+
+	active, err := s.activeWarehouses(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	levels, err := s.repository.FindLevelsByProductID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	available := 0
+	for _, level := range levels {
+		if level.VariantID == variantID && active[level.WarehouseID] {
+			available += level.OnHand
+		}
+	}
+
+	return available, nil
+}
+
+// SyncProductStock books the opening stock of new variants into the default
+// warehouse, drops the levels of removed variants and refreshes the stock
+// shown on the product from the warehouses.
+func (s *inventoryService) SyncProductStock(ctx context.Context, productID primitive.ObjectID, change model.StockChange) error {
+
+	p, err := s.productRepository.FindByID(ctx, productID)
+	if err != nil || p == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if p.IsBundle() {
+		return s.refreshBundle(ctx, p)
+	}
+
+	active, err := s.activeWarehouses(ctx)
+	if err != nil {
+		return err
+	}
+
+	levels, err := s.repository.FindLevelsByProductID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	stocked := make(map[primitive.ObjectID]bool)
+	stock := make(map[primitive.ObjectID]int)
+
+	for _, level := range levels {
+		stocked[level.VariantID] = true
+		if active[level.WarehouseID] {
+			stock[level.VariantID] += level.OnHand
+		}
+	}
+
+	var variantIDs []primitive.ObjectID
+	var defaultWarehouse *Warehouse
+
+	for _, v := range p.Variants {
+		variantIDs = append(variantIDs, v.ID)
+
+		if stocked[v.ID] || v.Stock <= 0 {
+			continue
+		}
+
+		if defaultWarehouse == nil {
+			if defaultWarehouse, err = s.repository.FindDefaultWarehouse(ctx); err != nil {
+				return err
+			}
+			if defaultWarehouse == nil {
+				return fmt.Errorf("no default warehouse")
+			}
+		}
+
+		if err := s.adjustLevel(ctx, defaultWarehouse.ID, p.ID, v.ID, v.SKU, v.Stock, change); err != nil {
+			return err
+		}
+
+		if active[defaultWarehouse.ID] {
+			stock[v.ID] += v.Stock
+		}
+	}
+
+	if err := s.repository.DeleteLevelsExcept(ctx, p.ID, variantIDs); err != nil {
+		return err
+	}
+
+	p.ApplyStock(stock)
+
+	if err := s.productRepository.SetVariantStock(ctx, p.ID, p.Variants, p.Sizes); err != nil {
+		return err
+	}
+
+	for _, v := range p.Variants {
+		s.refreshBundlesOf(ctx, v.ID)
+	}
+
+	return nil
+}
+
+// MigrateStock creates the default warehouse and moves the stock of products
+// that have no stock levels yet into it.
+func (s *inventoryService) MigrateStock(ctx context.Context) error {
+
+	if err := s.ensureDefaultWarehouse(ctx); err != nil {
+		return err
+	}
+
+	products, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{Admin: true, IncludeDeleted: true})
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+
+	for _, p := range products {
+		count, err := s.repository.CountLevelsByProductID(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 || len(p.Variants) == 0 || p.IsBundle() {
+			continue
+		}
+
+		change := model.StockChange{Type: model.MovementAdjustment, Reason: "opening stock"}
+
+		if err := s.SyncProductStock(ctx, p.ID, change); err != nil {
+			return fmt.Errorf("failed to migrate stock of product %s: %w", p.ID.Hex(), err)
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("inventory: moved the stock of %d products to the default warehouse", migrated)
+	}
+
+	return nil
+}
+
+func (s *inventoryService) ensureDefaultWarehouse(ctx context.Context) error {
+
+	warehouse, err := s.repository.FindDefaultWarehouse(ctx)
+	if err != nil || warehouse != nil {
+		return err
+	}
+
+	warehouse, err = s.repository.FindWarehouseByCode(ctx, DefaultWarehouseCode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if warehouse != nil {
+		warehouse.IsDefault = true
+		warehouse.Active = true
+		warehouse.UpdatedAt = now
+		return s.repository.UpdateWarehouse(ctx, warehouse)
+	}
+
+	return s.repository.CreateWarehouse(ctx, &Warehouse{
+		ID:        primitive.NewObjectID(),
+		Code:      DefaultWarehouseCode,
+		Name:      "Main warehouse",
+		Active:    true,
+		IsDefault: true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// adjustLevel changes the stock of a variant at a warehouse and records the
+// change in the ledger. Order status handling reads what an order holds from
+// the ledger, so a change that cannot be recorded is undone and fails.
+func (s *inventoryService) adjustLevel(ctx context.Context, warehouseID primitive.ObjectID, productID primitive.ObjectID, variantID primitive.ObjectID, sku string, quantity int, change model.StockChange) error {
+
+	if err := s.repository.AdjustLevel(ctx, warehouseID, productID, variantID, sku, quantity); err != nil {
+		return err
+	}
+
+	movement := &Movement{
+		ID:          primitive.NewObjectID(),
+		WarehouseID: warehouseID,
+		ProductID:   productID,
+		VariantID:   variantID,
+		SKU:         sku,
+		Type:        change.Type,
+		Quantity:    quantity,
+		Reason:      change.Reason,
+		ActorID:     change.ActorID,
+		OrderID:     change.OrderID,
+		ReferenceID: change.ReferenceID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.movementRepository.Create(ctx, movement); err != nil {
+		if undoErr := s.repository.AdjustLevel(ctx, warehouseID, productID, variantID, sku, -quantity); undoErr != nil {
+			log.Printf("failed to undo unrecorded stock change of %s: %v", sku, undoErr)
+		}
+		return fmt.Errorf("failed to record stock movement of %s: %w", sku, err)
+	}
+
+	return nil
+}
+
+func (s *inventoryService) activeWarehouses(ctx context.Context) (map[primitive.ObjectID]bool, error) {
+
+	warehouses, err := s.repository.FindWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[primitive.ObjectID]bool)
+	for _, warehouse := range warehouses {
+		if warehouse.Active {
+			active[warehouse.ID] = true
+		}
+	}
+
+	return active, nil
+}
+
+// mirrorStock applies a change of sellable stock to the product document.
+func (s *inventoryService) mirrorStock(ctx context.Context, productID primitive.ObjectID, variantID primitive.ObjectID, quantity int) {
+
+	if quantity == 0 {
+		return
+	}
+
+	p, err := s.productRepository.FindByID(ctx, productID)
+	if err != nil || p == nil {
+		return
+	}
+
+	if variant := p.FindVariant(variantID); variant != nil {
+		s.mirrorVariantStock(ctx, productID, variant, quantity)
+	}
+}
+
+func (s *inventoryService) mirrorVariantStock(ctx context.Context, productID primitive.ObjectID, variant *product.Variant, quantity int) {
+
+	if quantity == 0 {
+		return
+	}
+
+	if err := s.productRepository.AdjustVariantStock(ctx, productID, variant.ID, variant.Attributes["size"], quantity); err != nil {
+		log.Printf("failed to update stock of product %s: %v", productID.Hex(), err)
+	}
+
+	s.refreshBundlesOf(ctx, variant.ID)
+}
+
+func (s *inventoryService) findWarehouse(ctx context.Context, id string) (*Warehouse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid warehouse id: %v", err)
+	}
+
+	warehouse, err := s.repository.FindWarehouseByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if warehouse == nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	return warehouse, nil
+}
+
+func (s *inventoryService) findVariant(ctx context.Context, productID string, variantID string) (*product.Product, *product.Variant, error) {
+
+	objectProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	objectVariantID, err := primitive.ObjectIDFromHex(variantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid variant id: %v", err)
+	}
+
+	p, err := s.productRepository.FindByID(ctx, objectProductID)
+	if err != nil || p == nil {
+		return nil, nil, fmt.Errorf("product not found")
+	}
+
+	variant := p.FindVariant(objectVariantID)
+	if variant == nil {
+		return nil, nil, fmt.Errorf("variant not found")
+	}
+
+	if len(variant.Components) > 0 {
+		return nil, nil, fmt.Errorf("the stock of bundle %s comes from its components", p.ProductName)
+	}
+
+	return p, variant, nil
+}
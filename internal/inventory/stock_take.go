@@ -0,0 +1,111 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	StockTakeOpen      = "open"
+	StockTakePosted    = "posted"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTake is a count of the stock at one warehouse. SystemQuantity is what
+// the warehouse held when the line was counted; Difference is filled in when
+// the line is posted.
+type StockTake struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	WarehouseID primitive.ObjectID  `json:"warehouse_id" bson:"warehouse_id"`
+	Status      string              `json:"status" bson:"status"`
+	Note        string              `json:"note" bson:"note"`
+	Lines       []StockTakeLine     `json:"lines" bson:"lines"`
+	CreatedBy   primitive.ObjectID  `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+	PostedBy    *primitive.ObjectID `json:"posted_by" bson:"posted_by"`
+	PostedAt    *time.Time          `json:"posted_at" bson:"posted_at"`
+}
+
+type StockTakeLine struct {
+	ProductID       primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID       primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU             string             `json:"sku" bson:"sku"`
+	SystemQuantity  int                `json:"system_quantity" bson:"system_quantity"`
+	CountedQuantity *int               `json:"counted_quantity" bson:"counted_quantity"`
+	Difference      int                `json:"difference" bson:"difference"`
+	Posted          bool               `json:"posted" bson:"posted"`
+}
+
+type StockTakeRepository interface {
+	Create(ctx context.Context, stockTake *StockTake) error
+	Update(ctx context.Context, stockTake *StockTake) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*StockTake, error)
+	FindAll(ctx context.Context, filter bson.M, page int, limit int) ([]*StockTake, int64, error)
+}
+
+type stockTakeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStockTakeRepository(collection *mongo.Collection) StockTakeRepository {
+	return &stockTakeRepository{
+		collection: collection,
+	}
+}
+
+func (r *stockTakeRepository) Create(ctx context.Context, stockTake *StockTake) error {
+	_, err := r.collection.InsertOne(ctx, stockTake)
+	return err
+}
+
+func (r *stockTakeRepository) Update(ctx context.Context, stockTake *StockTake) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": stockTake.ID}, stockTake)
+	return err
+}
+
+func (r *stockTakeRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*StockTake, error) {
+
+	var stockTake *StockTake
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&stockTake)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+func (r *stockTakeRepository) FindAll(ctx context.Context, filter bson.M, page int, limit int) ([]*StockTake, int64, error) {
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"lines": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var stockTakes []*StockTake
+	if err := cursor.All(ctx, &stockTakes); err != nil {
+		return nil, 0, err
+	}
+
+	return stockTakes, total, nil
+}
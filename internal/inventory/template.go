@@ -0,0 +1,137 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func BuildLowStockDigestEmailHTML(alerts []*StockAlert, date time.Time, brandName string) string {
+
+	var outOfStock, low []*StockAlert
+	for _, alert := range alerts {
+		if alert.Available <= 0 {
+			outOfStock = append(outOfStock, alert)
+		} else {
+			low = append(low, alert)
+		}
+	}
+
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body style="margin:0; background:#f6f7f9; font-family:system-ui,-apple-system,Segoe UI,Roboto,Helvetica,Arial,sans-serif;">
+  <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="background:#f6f7f9; padding:24px 0;">
+    <tr>
+      <td align="center">
+        <table role="presentation" width="640" cellspacing="0" cellpadding="0" style="max-width:640px; width:100%%; background:#ffffff; border-radius:16px; overflow:hidden; box-shadow:0 4px 24px rgba(0,0,0,0.06);">
+          <tr>
+            <td style="background:linear-gradient(135deg,#0ea5e9,#6366f1); padding:20px; color:#eaf2ff; font-weight:800; font-size:18px;">%s</td>
+          </tr>
+          <tr>
+            <td style="padding:24px;">
+              <div style="font-size:18px; font-weight:700; color:#111; margin-bottom:6px;">Báo cáo tồn kho thấp ngày %s 📦</div>
+              <div style="font-size:14px; color:#444;">Có <strong>%d</strong> sản phẩm hết hàng và <strong>%d</strong> sản phẩm sắp hết hàng.</div>
+            </td>
+          </tr>
+          %s
+          %s
+        </table>
+      </td>
+    </tr>
+  </table>
+</body>
+</html>`,
+		brandName,
+		brandName,
+		date.Format("02/01/2006"),
+		len(outOfStock),
+		len(low),
+		buildStockAlertSection("Hết hàng", "#dc2626", outOfStock),
+		buildStockAlertSection("Sắp hết hàng", "#d97706", low),
+	)
+}
+
+func buildStockAlertSection(title string, color string, alerts []*StockAlert) string {
+
+	if len(alerts) == 0 {
+		return ""
+	}
+
+	var rows strings.Builder
+	for _, alert := range alerts {
+		daysLeft := "-"
+		if alert.DaysOfStock != nil {
+			daysLeft = fmt.Sprintf("%.1f", *alert.DaysOfStock)
+		}
+
+		fmt.Fprintf(&rows, `
+                <tr>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; color:#111;">%s<div style="font-size:12px; color:#64748b;">%s</div></td>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; color:%s;" align="right">%d / %d</td>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; color:#111;" align="right">%.1f</td>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; color:#111;" align="right">%s</td>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; color:#111;" align="right">%d</td>
+                  <td style="padding:8px; border-top:1px solid #e5e7eb; font-size:13px; font-weight:700; color:#111;" align="right">%d</td>
+                </tr>`,
+			htmlEscape(alert.ProductName),
+			htmlEscape(variantLabel(alert)),
+			color,
+			alert.Available,
+			alert.Threshold,
+			alert.SoldPerDay,
+			daysLeft,
+			alert.OnOrder,
+			alert.ReorderQuantity,
+		)
+	}
+
+	return fmt.Sprintf(`<tr>
+            <td style="padding:0 24px 24px 24px;">
+              <div style="font-size:15px; font-weight:700; color:%s; margin-bottom:8px;">%s (%d)</div>
+              <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="border:1px solid #e5e7eb; border-radius:12px;">
+                <tr>
+                  <td style="padding:8px; font-size:12px; color:#64748b;">Sản phẩm</td>
+                  <td style="padding:8px; font-size:12px; color:#64748b;" align="right">Tồn / Ngưỡng</td>
+                  <td style="padding:8px; font-size:12px; color:#64748b;" align="right">Bán/ngày</td>
+                  <td style="padding:8px; font-size:12px; color:#64748b;" align="right">Số ngày còn</td>
+                  <td style="padding:8px; font-size:12px; color:#64748b;" align="right">Đang về</td>
+                  <td style="padding:8px; font-size:12px; color:#64748b;" align="right">Đề xuất nhập</td>
+                </tr>%s
+              </table>
+            </td>
+          </tr>`,
+		color,
+		title,
+		len(alerts),
+		rows.String(),
+	)
+}
+
+func variantLabel(alert *StockAlert) string {
+
+	keys := make([]string, 0, len(alert.Attributes))
+	for key := range alert.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{alert.SKU}
+	for _, key := range keys {
+		parts = append(parts, key+": "+alert.Attributes[key])
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+func htmlEscape(s string) string {
+	r := strings.NewReplacer(
+		"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;",
+	)
+	return r.Replace(s)
+}
@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StockThreshold overrides the configured low-stock threshold of a variant.
+type StockThreshold struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU       string             `json:"sku" bson:"sku"`
+	Threshold int                `json:"threshold" bson:"threshold"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type ThresholdRepository interface {
+	Upsert(ctx context.Context, threshold *StockThreshold) error
+	DeleteByVariantID(ctx context.Context, variantID primitive.ObjectID) error
+	FindAll(ctx context.Context) ([]*StockThreshold, error)
+}
+
+type thresholdRepository struct {
+	collection *mongo.Collection
+}
+
+func NewThresholdRepository(collection *mongo.Collection) ThresholdRepository {
+	return &thresholdRepository{
+		collection: collection,
+	}
+}
+
+func (r *thresholdRepository) Upsert(ctx context.Context, threshold *StockThreshold) error {
+
+	update := bson.M{
+		"$set": bson.M{
+			"product_id": threshold.ProductID,
+			"sku":        threshold.SKU,
+			"threshold":  threshold.Threshold,
+			"updated_by": threshold.UpdatedBy,
+			"updated_at": threshold.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"_id": threshold.ID},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"variant_id": threshold.VariantID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *thresholdRepository) DeleteByVariantID(ctx context.Context, variantID primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"variant_id": variantID})
+	return err
+}
+
+func (r *thresholdRepository) FindAll(ctx context.Context) ([]*StockThreshold, error) {
+
+	var thresholds []*StockThreshold
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &thresholds); err != nil {
+		return nil, err
+	}
+
+	return thresholds, nil
+}
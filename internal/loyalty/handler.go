@@ -0,0 +1,56 @@
+package loyalty
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyHandler struct {
+	LoyaltyService LoyaltyService
+}
+
+func NewLoyaltyHandler(loyaltyService LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		LoyaltyService: loyaltyService,
+	}
+}
+
+func (h *LoyaltyHandler) GetAccount(c *gin.Context) {
+
+	currentUserID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.Param("user_id")
+
+	account, err := h.LoyaltyService.GetAccount(c, currentUserID, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", account)
+
+}
+
+func (h *LoyaltyHandler) GetHistory(c *gin.Context) {
+
+	currentUserID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.Param("user_id")
+
+	history, err := h.LoyaltyService.GetHistory(c, currentUserID, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", history)
+
+}
@@ -0,0 +1,37 @@
+package loyalty
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TransactionType string
+
+const (
+	Earn         TransactionType = "earn"
+	Redeem       TransactionType = "redeem"
+	ReverseEarn  TransactionType = "reverse_earn"
+	RefundRedeem TransactionType = "refund_redeem"
+)
+
+type LoyaltyAccount struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Balance        int                `json:"balance" bson:"balance"`
+	LifetimePoints int                `json:"lifetime_points" bson:"lifetime_points"`
+	Tier           string             `json:"tier" bson:"tier"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type PointTransaction struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id"`
+	UserID       primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	OrderID      *primitive.ObjectID `json:"order_id" bson:"order_id"`
+	Type         TransactionType     `json:"type" bson:"type"`
+	Points       int                 `json:"points" bson:"points"`
+	BalanceAfter int                 `json:"balance_after" bson:"balance_after"`
+	Note         string              `json:"note" bson:"note"`
+	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,146 @@
+package loyalty
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoyaltyRepository interface {
+	FindAccountByUserID(ctx context.Context, userID primitive.ObjectID) (*LoyaltyAccount, error)
+	IncrementBalance(ctx context.Context, userID primitive.ObjectID, points int, lifetimePoints int) (*LoyaltyAccount, error)
+	UpdateTier(ctx context.Context, userID primitive.ObjectID, tier string) error
+	CreateTransaction(ctx context.Context, transaction *PointTransaction) error
+	FindTransactionsByUserID(ctx context.Context, userID primitive.ObjectID) ([]*PointTransaction, error)
+	FindTransactionsByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]*PointTransaction, error)
+}
+
+type loyaltyRepository struct {
+	accounts     *mongo.Collection
+	transactions *mongo.Collection
+}
+
+func NewLoyaltyRepository(accounts *mongo.Collection, transactions *mongo.Collection) LoyaltyRepository {
+	return &loyaltyRepository{
+		accounts:     accounts,
+		transactions: transactions,
+	}
+}
+
+func (r *loyaltyRepository) FindAccountByUserID(ctx context.Context, userID primitive.ObjectID) (*LoyaltyAccount, error) {
+
+	filter := bson.M{"user_id": userID}
+
+	var account *LoyaltyAccount
+
+	err := r.accounts.FindOne(ctx, filter).Decode(&account)
+	if err == mongo.ErrNoDocuments {
+
+		account = &LoyaltyAccount{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		if _, err := r.accounts.InsertOne(ctx, account); err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (r *loyaltyRepository) IncrementBalance(ctx context.Context, userID primitive.ObjectID, points int, lifetimePoints int) (*LoyaltyAccount, error) {
+
+	if _, err := r.FindAccountByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"user_id": userID}
+	if points < 0 {
+		filter["balance"] = bson.M{"$gte": -points}
+	}
+
+	update := bson.M{
+		"$inc": bson.M{
+			"balance":         points,
+			"lifetime_points": lifetimePoints,
+		},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var account LoyaltyAccount
+
+	err := r.accounts.FindOneAndUpdate(ctx, filter, update, opts).Decode(&account)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("not enough points")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *loyaltyRepository) UpdateTier(ctx context.Context, userID primitive.ObjectID, tier string) error {
+
+	filter := bson.M{"user_id": userID}
+	update := bson.M{"$set": bson.M{"tier": tier}}
+
+	_, err := r.accounts.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *loyaltyRepository) CreateTransaction(ctx context.Context, transaction *PointTransaction) error {
+	_, err := r.transactions.InsertOne(ctx, transaction)
+	return err
+}
+
+func (r *loyaltyRepository) FindTransactionsByUserID(ctx context.Context, userID primitive.ObjectID) ([]*PointTransaction, error) {
+
+	var transactions []*PointTransaction
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.transactions.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *loyaltyRepository) FindTransactionsByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]*PointTransaction, error) {
+
+	var transactions []*PointTransaction
+
+	cursor, err := r.transactions.Find(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,12 @@
+package loyalty
+
+type LoyaltyAccountResponse struct {
+	UserID           string  `json:"user_id"`
+	Balance          int     `json:"balance"`
+	LifetimePoints   int     `json:"lifetime_points"`
+	Tier             string  `json:"tier"`
+	TierMultiplier   float64 `json:"tier_multiplier"`
+	NextTier         *string `json:"next_tier"`
+	PointsToNextTier int     `json:"points_to_next_tier"`
+	RedeemValue      float64 `json:"redeem_value"`
+}
@@ -0,0 +1,15 @@
+package loyalty
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *LoyaltyHandler) {
+	loyaltyGroup := r.Group("/api/v1/loyalty")
+	{
+		loyaltyGroup.GET("/:user_id", middleware.JWTAuthMiddleware(), handler.GetAccount)
+		loyaltyGroup.GET("/:user_id/history", middleware.JWTAuthMiddleware(), handler.GetHistory)
+	}
+}
@@ -0,0 +1,294 @@
+package loyalty
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"modular_monolith/config"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/user"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LoyaltyService interface {
+	GetAccount(ctx context.Context, currentUserID string, userID string) (*LoyaltyAccountResponse, error)
+	GetHistory(ctx context.Context, currentUserID string, userID string) ([]*PointTransaction, error)
+	RedeemPoints(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, points int, orderTotal float64) (float64, error)
+	HandleOrderStatus(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, status string, orderTotal float64) error
+}
+
+type loyaltyService struct {
+	repository     LoyaltyRepository
+	userRepository user.UserRepository
+	config         config.LoyaltyConfig
+}
+
+func NewLoyaltyService(repository LoyaltyRepository, userRepository user.UserRepository, cfg config.LoyaltyConfig) LoyaltyService {
+	sort.Slice(cfg.Tiers, func(i, j int) bool {
+		return cfg.Tiers[i].MinPoints < cfg.Tiers[j].MinPoints
+	})
+	return &loyaltyService{
+		repository:     repository,
+		userRepository: userRepository,
+		config:         cfg,
+	}
+}
+
+func (s *loyaltyService) GetAccount(ctx context.Context, currentUserID string, userID string) (*LoyaltyAccountResponse, error) {
+
+	objectID, err := s.accountOwner(ctx, currentUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.repository.FindAccountByUserID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tier, next := s.resolveTier(account.LifetimePoints)
+
+	res := &LoyaltyAccountResponse{
+		UserID:         account.UserID.Hex(),
+		Balance:        account.Balance,
+		LifetimePoints: account.LifetimePoints,
+		Tier:           tier.Name,
+		TierMultiplier: tier.Multiplier,
+		RedeemValue:    float64(account.Balance) * s.config.RedeemRate,
+	}
+
+	if next != nil {
+		res.NextTier = &next.Name
+		res.PointsToNextTier = next.MinPoints - account.LifetimePoints
+	}
+
+	return res, nil
+}
+
+func (s *loyaltyService) GetHistory(ctx context.Context, currentUserID string, userID string) ([]*PointTransaction, error) {
+
+	objectID, err := s.accountOwner(ctx, currentUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.FindTransactionsByUserID(ctx, objectID)
+}
+
+// accountOwner returns the id of the account to read. Users can only read their
+// own account; staff can read anyone's.
+func (s *loyaltyService) accountOwner(ctx context.Context, currentUserID string, userID string) (primitive.ObjectID, error) {
+
+	if userID == "" {
+		return primitive.NilObjectID, fmt.Errorf("user_id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid user_id: %v", err)
+	}
+
+	if userID != currentUserID {
+		if _, err := user.RequireStaff(ctx, s.userRepository, currentUserID); err != nil {
+			return primitive.NilObjectID, fmt.Errorf("you can only view your own loyalty account")
+		}
+	}
+
+	return objectID, nil
+}
+
+func (s *loyaltyService) RedeemPoints(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, points int, orderTotal float64) (float64, error) {
+
+	if points <= 0 {
+		return 0, fmt.Errorf("points must be greater than 0")
+	}
+
+	if s.config.RedeemRate <= 0 {
+		return 0, fmt.Errorf("redeeming points is disabled")
+	}
+
+	maxDiscount := orderTotal * s.config.MaxRedeemPercent / 100
+	maxPoints := int(math.Floor(maxDiscount / s.config.RedeemRate))
+	if points > maxPoints {
+		return 0, fmt.Errorf("you can redeem at most %d points for this order", maxPoints)
+	}
+
+	account, err := s.repository.IncrementBalance(ctx, userID, -points, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.repository.CreateTransaction(ctx, &PointTransaction{
+		ID:           primitive.NewObjectID(),
+		UserID:       userID,
+		OrderID:      &orderID,
+		Type:         Redeem,
+		Points:       -points,
+		BalanceAfter: account.Balance,
+		Note:         "Redeemed at checkout",
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(points) * s.config.RedeemRate, nil
+}
+
+// HandleOrderStatus is called whenever an order changes status. Completed orders
+// earn points once; cancelled or refunded orders and failed payments give back
+// everything the order earned or redeemed.
+func (s *loyaltyService) HandleOrderStatus(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, status string, orderTotal float64) error {
+
+	transactions, err := s.repository.FindTransactionsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	totals := map[TransactionType]int{}
+	for _, t := range transactions {
+		totals[t.Type] += t.Points
+	}
+
+	switch status {
+	case string(model.OrderPaid), string(model.PaymentSuccess):
+		if totals[Earn]+totals[ReverseEarn] > 0 {
+			return nil
+		}
+		return s.earn(ctx, userID, orderID, orderTotal)
+
+	case string(model.OrderCancelled), string(model.OrderRefunded), string(model.PaymentFailed):
+		if earned := totals[Earn] + totals[ReverseEarn]; earned > 0 {
+			if err := s.reverseEarn(ctx, userID, orderID, earned); err != nil {
+				return err
+			}
+		}
+		if redeemed := -(totals[Redeem] + totals[RefundRedeem]); redeemed > 0 {
+			if err := s.refundRedeem(ctx, userID, orderID, redeemed); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *loyaltyService) earn(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, orderTotal float64) error {
+
+	account, err := s.repository.FindAccountByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tier, _ := s.resolveTier(account.LifetimePoints)
+
+	points := int(math.Floor(orderTotal * s.config.EarnRate * tier.Multiplier))
+	if points <= 0 {
+		return nil
+	}
+
+	account, err = s.repository.IncrementBalance(ctx, userID, points, points)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.CreateTransaction(ctx, &PointTransaction{
+		ID:           primitive.NewObjectID(),
+		UserID:       userID,
+		OrderID:      &orderID,
+		Type:         Earn,
+		Points:       points,
+		BalanceAfter: account.Balance,
+		Note:         fmt.Sprintf("Earned from order (%s tier)", tier.Name),
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.refreshTier(ctx, account)
+}
+
+func (s *loyaltyService) reverseEarn(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, earned int) error {
+
+	account, err := s.repository.FindAccountByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Points already spent elsewhere cannot be taken back, so never go below zero
+	points := earned
+	if account.Balance < points {
+		points = account.Balance
+	}
+
+	account, err = s.repository.IncrementBalance(ctx, userID, -points, -earned)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.CreateTransaction(ctx, &PointTransaction{
+		ID:           primitive.NewObjectID(),
+		UserID:       userID,
+		OrderID:      &orderID,
+		Type:         ReverseEarn,
+		Points:       -earned,
+		BalanceAfter: account.Balance,
+		Note:         fmt.Sprintf("Reversed %d points (%d deducted from balance)", earned, points),
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.refreshTier(ctx, account)
+}
+
+func (s *loyaltyService) refundRedeem(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, redeemed int) error {
+
+	account, err := s.repository.IncrementBalance(ctx, userID, redeemed, 0)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.CreateTransaction(ctx, &PointTransaction{
+		ID:           primitive.NewObjectID(),
+		UserID:       userID,
+		OrderID:      &orderID,
+		Type:         RefundRedeem,
+		Points:       redeemed,
+		BalanceAfter: account.Balance,
+		Note:         "Refunded redeemed points",
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (s *loyaltyService) refreshTier(ctx context.Context, account *LoyaltyAccount) error {
+
+	tier, _ := s.resolveTier(account.LifetimePoints)
+	if tier.Name == account.Tier {
+		return nil
+	}
+
+	return s.repository.UpdateTier(ctx, account.UserID, tier.Name)
+}
+
+func (s *loyaltyService) resolveTier(lifetimePoints int) (config.LoyaltyTier, *config.LoyaltyTier) {
+
+	current := config.LoyaltyTier{Multiplier: 1}
+	var next *config.LoyaltyTier
+
+	for i, tier := range s.config.Tiers {
+		if lifetimePoints >= tier.MinPoints {
+			current = tier
+			continue
+		}
+		next = &s.config.Tiers[i]
+		break
+	}
+
+	return current, next
+}
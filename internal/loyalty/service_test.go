@@ -0,0 +1,207 @@
+package loyalty
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/config"
+	"modular_monolith/internal/shared/model"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeLoyaltyRepository keeps one account and its transactions in memory and,
+// like the real repository, refuses to take the balance below zero.
+type fakeLoyaltyRepository struct {
+	account      LoyaltyAccount
+	transactions []*PointTransaction
+}
+
+func (r *fakeLoyaltyRepository) FindAccountByUserID(ctx context.Context, userID primitive.ObjectID) (*LoyaltyAccount, error) {
+	account := r.account
+	return &account, nil
+}
+
+func (r *fakeLoyaltyRepository) IncrementBalance(ctx context.Context, userID primitive.ObjectID, points int, lifetimePoints int) (*LoyaltyAccount, error) {
+	if r.account.Balance+points < 0 {
+		return nil, fmt.Errorf("not enough points")
+	}
+	r.account.Balance += points
+	r.account.LifetimePoints += lifetimePoints
+	account := r.account
+	return &account, nil
+}
+
+func (r *fakeLoyaltyRepository) UpdateTier(ctx context.Context, userID primitive.ObjectID, tier string) error {
+	r.account.Tier = tier
+	return nil
+}
+
+func (r *fakeLoyaltyRepository) CreateTransaction(ctx context.Context, transaction *PointTransaction) error {
+	r.transactions = append(r.transactions, transaction)
+	return nil
+}
+
+func (r *fakeLoyaltyRepository) FindTransactionsByUserID(ctx context.Context, userID primitive.ObjectID) ([]*PointTransaction, error) {
+	return r.transactions, nil
+}
+
+func (r *fakeLoyaltyRepository) FindTransactionsByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]*PointTransaction, error) {
+	var transactions []*PointTransaction
+	for _, t := range r.transactions {
+		if t.OrderID != nil && *t.OrderID == orderID {
+			transactions = append(transactions, t)
+		}
+	}
+	return transactions, nil
+}
+
+func TestHandleOrderStatus(t *testing.T) {
+
+	cfg := config.LoyaltyConfig{
+		EarnRate:         0.001,
+		RedeemRate:       100,
+		MaxRedeemPercent: 50,
+		Tiers: []config.LoyaltyTier{
+			{Name: "bronze", MinPoints: 0, Multiplier: 1},
+			{Name: "silver", MinPoints: 1000, Multiplier: 1.5},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		account      LoyaltyAccount
+		redeem       int
+		statuses     []string
+		wantBalance  int
+		wantLifetime int
+		wantTier     string
+	}{
+		{
+			name:         "paid order earns once",
+			account:      LoyaltyAccount{Balance: 10},
+			statuses:     []string{string(model.OrderPaid), string(model.PaymentSuccess)},
+			wantBalance:  510,
+			wantLifetime: 500,
+			wantTier:     "bronze",
+		},
+		{
+			name:         "tier multiplier applies and promotes",
+			account:      LoyaltyAccount{Balance: 0, LifetimePoints: 1000, Tier: "silver"},
+			statuses:     []string{string(model.OrderPaid)},
+			wantBalance:  750,
+			wantLifetime: 1750,
+			wantTier:     "silver",
+		},
+		{
+			name:         "earning crosses into the next tier",
+			account:      LoyaltyAccount{Balance: 0, LifetimePoints: 600, Tier: "bronze"},
+			statuses:     []string{string(model.OrderPaid)},
+			wantBalance:  500,
+			wantLifetime: 1100,
+			wantTier:     "silver",
+		},
+		{
+			name:         "cancel reverses what was earned",
+			account:      LoyaltyAccount{Balance: 10},
+			statuses:     []string{string(model.OrderPaid), string(model.OrderCancelled)},
+			wantBalance:  10,
+			wantLifetime: 0,
+			wantTier:     "bronze",
+		},
+		{
+			name:         "refund refunds redeemed points once",
+			account:      LoyaltyAccount{Balance: 300},
+			redeem:       200,
+			statuses:     []string{string(model.OrderRefunded), string(model.OrderCancelled)},
+			wantBalance:  300,
+			wantLifetime: 0,
+			wantTier:     "",
+		},
+		{
+			name:         "failed payment refunds redeemed points",
+			account:      LoyaltyAccount{Balance: 300},
+			redeem:       200,
+			statuses:     []string{string(model.PaymentFailed)},
+			wantBalance:  300,
+			wantLifetime: 0,
+			wantTier:     "",
+		},
+		{
+			name:         "reversal never takes the balance below zero",
+			account:      LoyaltyAccount{Balance: 0},
+			redeem:       0,
+			statuses:     []string{string(model.OrderPaid), "spend", string(model.OrderRefunded)},
+			wantBalance:  0,
+			wantLifetime: 0,
+			wantTier:     "bronze",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeLoyaltyRepository{account: tt.account}
+			s := NewLoyaltyService(repository, nil, cfg)
+
+			ctx := context.Background()
+			userID, orderID := primitive.NewObjectID(), primitive.NewObjectID()
+			orderTotal := 500000.0
+
+			if tt.redeem > 0 {
+				discount, err := s.RedeemPoints(ctx, userID, orderID, tt.redeem, orderTotal)
+				if err != nil {
+					t.Fatalf("RedeemPoints() error = %v", err)
+				}
+				if want := float64(tt.redeem) * cfg.RedeemRate; discount != want {
+					t.Errorf("RedeemPoints() = %v, want %v", discount, want)
+				}
+			}
+
+			for _, status := range tt.statuses {
+				// Points spent on another order in between
+				if status == "spend" {
+					repository.account.Balance -= 400
+					continue
+				}
+				if err := s.HandleOrderStatus(ctx, userID, orderID, status, orderTotal); err != nil {
+					t.Fatalf("HandleOrderStatus(%s) error = %v", status, err)
+				}
+			}
+
+			if repository.account.Balance != tt.wantBalance {
+				t.Errorf("balance = %d, want %d", repository.account.Balance, tt.wantBalance)
+			}
+			if repository.account.LifetimePoints != tt.wantLifetime {
+				t.Errorf("lifetime points = %d, want %d", repository.account.LifetimePoints, tt.wantLifetime)
+			}
+			if repository.account.Tier != tt.wantTier {
+				t.Errorf("tier = %q, want %q", repository.account.Tier, tt.wantTier)
+			}
+		})
+	}
+}
+
+func TestRedeemPointsLimit(t *testing.T) {
+
+	repository := &fakeLoyaltyRepository{account: LoyaltyAccount{Balance: 10000}}
+	s := NewLoyaltyService(repository, nil, config.LoyaltyConfig{RedeemRate: 100, MaxRedeemPercent: 50})
+
+	tests := []struct {
+		name    string
+		points  int
+		wantErr bool
+	}{
+		{name: "up to half the order", points: 2500},
+		{name: "more than half the order", points: 2501, wantErr: true},
+		{name: "no points", points: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RedeemPoints(context.Background(), primitive.NewObjectID(), primitive.NewObjectID(), tt.points, 500000)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RedeemPoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package order
 
 import (
+	"modular_monolith/internal/shared/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,7 +13,9 @@ const (
 	Pending    OrderStatus = "pending"
 	Paid       OrderStatus = "paid"
 	Processing OrderStatus = "processing"
+	Delivered  OrderStatus = "delivered"
 	Cancelled  OrderStatus = "cancelled"
+	Refunded   OrderStatus = "refunded"
 )
 
 type Order struct {
@@ -24,6 +27,8 @@ type Order struct {
 	TotalPrice      float64            `json:"total_price" bson:"total_price"`
 	Status          OrderStatus        `json:"status" bson:"status"`
 	Discount        *float64           `json:"discount" bson:"discount"`
+	PointsRedeemed  int                `json:"points_redeemed" bson:"points_redeemed"`
+	PointsDiscount  float64            `json:"points_discount" bson:"points_discount"`
 	ShippingAddress ShippingAddress    `json:"shipping_address" bson:"shipping_address"`
 	CustomerNote    *string            `json:"customer_note" bson:"customer_note"`
 	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
@@ -31,13 +36,18 @@ type Order struct {
 }
 
 type OrderItem struct {
-	ProductID    primitive.ObjectID `json:"product_id" bson:"product_id"`
-	ProductName  string             `json:"product_name" bson:"product_name"`
-	ProductImage string             `json:"product_image" bson:"product_image"`
-	Quantity     int                `json:"quantity" bson:"quantity"`
-	Price        float64            `json:"price" bson:"price"`
-	Size         string             `json:"size" bson:"size"`
-	TotalPrice   float64            `json:"total_price" bson:"total_price"`
+	ProductID    primitive.ObjectID      `json:"product_id" bson:"product_id"`
+	VariantID    primitive.ObjectID      `json:"variant_id" bson:"variant_id"`
+	SKU          string                  `json:"sku" bson:"sku"`
+	Attributes   map[string]string       `json:"attributes" bson:"attributes"`
+	ProductName  string                  `json:"product_name" bson:"product_name"`
+	ProductImage string                  `json:"product_image" bson:"product_image"`
+	Quantity     int                     `json:"quantity" bson:"quantity"`
+	Price        float64                 `json:"price" bson:"price"`
+	Size         string                  `json:"size" bson:"size"`
+	TotalPrice   float64                 `json:"total_price" bson:"total_price"`
+	Allocations  []model.StockAllocation `json:"allocations" bson:"allocations"`
+	Components   []model.OrderComponent  `json:"components,omitempty" bson:"components,omitempty"`
 }
 
 type ShippingAddress struct {
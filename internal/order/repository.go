@@ -2,22 +2,31 @@ package order
 
 import (
 	"context"
+	"fmt"
+	"modular_monolith/internal/shared/model"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type OrderRepository interface{
+type OrderRepository interface {
 	Create(ctx context.Context, order *Order) (string, error)
 	FindAll(ctx context.Context) ([]Order, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*Order, error)
 	UpdateByID(ctx context.Context, id primitive.ObjectID, status string) error
+	UpdateAllocations(ctx context.Context, id primitive.ObjectID, items []OrderItem) error
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
 	FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*Order, error)
+	FindPurchasedOrder(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (*model.Order, error)
+	FindPurchasedOrders(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) ([]*model.Order, error)
+	FindPurchasedSince(ctx context.Context, since time.Time) ([]*model.Order, error)
+	FindPurchasedByUserID(ctx context.Context, userID primitive.ObjectID) ([]*model.Order, error)
 }
 
-type orderRepository struct{
+type orderRepository struct {
 	collection *mongo.Collection
 }
 
@@ -47,9 +56,9 @@ func (r *orderRepository) FindAll(ctx context.Context) ([]Order, error) {
 	if err = cursor.All(ctx, &orders); err != nil {
 		return nil, err
 	}
-	
+
 	return orders, nil
-	
+
 }
 
 func (r *orderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*Order, error) {
@@ -61,7 +70,7 @@ func (r *orderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (
 	if err := r.collection.FindOne(ctx, filter).Decode(&order); err != nil {
 		return nil, err
 	}
-	
+
 	return &order, nil
 
 }
@@ -72,7 +81,23 @@ func (r *orderRepository) UpdateByID(ctx context.Context, id primitive.ObjectID,
 	update := bson.M{"$set": bson.M{"status": status}}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
-	
+
+	return err
+
+}
+
+func (r *orderRepository) UpdateAllocations(ctx context.Context, id primitive.ObjectID, items []OrderItem) error {
+
+	set := bson.M{}
+	for i, item := range items {
+		set[fmt.Sprintf("order_items.%d.allocations", i)] = item.Allocations
+		for j, component := range item.Components {
+			set[fmt.Sprintf("order_items.%d.components.%d.allocations", i, j)] = component.Allocations
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+
 	return err
 
 }
@@ -82,9 +107,9 @@ func (r *orderRepository) DeleteByID(ctx context.Context, id primitive.ObjectID)
 	filter := bson.M{"_id": id}
 
 	_, err := r.collection.DeleteOne(ctx, filter)
-	
+
 	return err
-	
+
 }
 
 func (r *orderRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*Order, error) {
@@ -101,7 +126,75 @@ func (r *orderRepository) FindByUserID(ctx context.Context, userID primitive.Obj
 	if err = cursor.All(ctx, &orders); err != nil {
 		return nil, err
 	}
-	
+
 	return orders, nil
 
-}
\ No newline at end of file
+}
+
+// FindPurchasedOrder returns the most recent paid or delivered order of the user
+// that contains the product, or nil when the user never bought it.
+func (r *orderRepository) FindPurchasedOrder(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (*model.Order, error) {
+
+	filter := bson.M{
+		"user_id":                userID,
+		"order_items.product_id": productID,
+		"status": bson.M{"$in": []string{
+			string(model.OrderPaid),
+			string(model.PaymentSuccess),
+			string(model.OrderDelivered),
+		}},
+	}
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	var order model.Order
+
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&order); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &order, nil
+
+}
+
+// FindPurchasedOrders returns every paid or delivered order of the user that
+// contains the product, most recent first.
+func (r *orderRepository) FindPurchasedOrders(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) ([]*model.Order, error) {
+	return r.findPurchased(ctx, bson.M{"user_id": userID, "order_items.product_id": productID})
+}
+
+// FindPurchasedSince returns the paid or delivered orders placed since the
+// given time.
+func (r *orderRepository) FindPurchasedSince(ctx context.Context, since time.Time) ([]*model.Order, error) {
+	return r.findPurchased(ctx, bson.M{"created_at": bson.M{"$gte": since}})
+}
+
+func (r *orderRepository) FindPurchasedByUserID(ctx context.Context, userID primitive.ObjectID) ([]*model.Order, error) {
+	return r.findPurchased(ctx, bson.M{"user_id": userID})
+}
+
+func (r *orderRepository) findPurchased(ctx context.Context, filter bson.M) ([]*model.Order, error) {
+
+	filter["status"] = bson.M{"$in": []string{
+		string(model.OrderPaid),
+		string(model.PaymentSuccess),
+		string(model.OrderDelivered),
+	}}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []*model.Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
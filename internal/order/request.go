@@ -1,13 +1,14 @@
 package order
 
 type CreateOrderRequest struct {
-	UserID     string  `json:"user_id" bson:"user_id"`
-	Type       string  `json:"type" bson:"type"`
-	Name       string  `json:"name" bson:"name"`
-	Email      string  `json:"email" bson:"email"`
-	Phone      string  `json:"phone" bson:"phone"`
-	Address    string  `json:"address" bson:"address"`
-	CouponCode *string `json:"coupon_code" bson:"coupon_code"`
+	UserID       string  `json:"user_id" bson:"user_id"`
+	Type         string  `json:"type" bson:"type"`
+	Name         string  `json:"name" bson:"name"`
+	Email        string  `json:"email" bson:"email"`
+	Phone        string  `json:"phone" bson:"phone"`
+	Address      string  `json:"address" bson:"address"`
+	CouponCode   *string `json:"coupon_code" bson:"coupon_code"`
+	RedeemPoints int     `json:"redeem_points" bson:"redeem_points"`
 }
 
 type UpdateOrderRequest struct {
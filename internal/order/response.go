@@ -16,6 +16,8 @@ type OrderResponse struct {
 	TotalPrice      float64            `json:"total_price" bson:"total_price"`
 	Status          OrderStatus        `json:"status" bson:"status"`
 	Discount        *float64           `json:"discount" bson:"discount"`
+	PointsRedeemed  int                `json:"points_redeemed" bson:"points_redeemed"`
+	PointsDiscount  float64            `json:"points_discount" bson:"points_discount"`
 	ShippingAddress ShippingAddress    `json:"shipping_address" bson:"shipping_address"`
 	CustomerNote    *string            `json:"customer_note" bson:"customer_note"`
 	Payment         *model.Payment     `json:"payment" bson:"payment"`
@@ -6,9 +6,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"modular_monolith/internal/cart"
 	"modular_monolith/internal/coupon"
+	"modular_monolith/internal/inventory"
+	"modular_monolith/internal/loyalty"
 	"modular_monolith/internal/product"
+	"modular_monolith/internal/referral"
 	"modular_monolith/internal/shared/model"
 	"modular_monolith/internal/shared/ports"
 	"modular_monolith/pkg/email"
@@ -34,10 +39,13 @@ type orderService struct {
 	couponRepository  coupon.CouponRepository
 	paymentRepository ports.PaymentRepository
 	productRepository product.ProductRepository
+	inventoryService  inventory.InventoryService
+	loyaltyService    loyalty.LoyaltyService
+	referralService   referral.ReferralService
 	EmailService      *email.EmailService
 }
 
-func NewOrderService(orderRepo OrderRepository, cartService cart.CartService, couponRepository coupon.CouponRepository, paymentRepository ports.PaymentRepository, productRepository product.ProductRepository) OrderService {
+func NewOrderService(orderRepo OrderRepository, cartService cart.CartService, couponRepository coupon.CouponRepository, paymentRepository ports.PaymentRepository, productRepository product.ProductRepository, inventoryService inventory.InventoryService, loyaltyService loyalty.LoyaltyService, referralService referral.ReferralService) OrderService {
 	emailService := email.NewEmailService()
 	return &orderService{
 		orderRepo:         orderRepo,
@@ -45,6 +53,9 @@ func NewOrderService(orderRepo OrderRepository, cartService cart.CartService, co
 		couponRepository:  couponRepository,
 		paymentRepository: paymentRepository,
 		productRepository: productRepository,
+		inventoryService:  inventoryService,
+		loyaltyService:    loyaltyService,
+		referralService:   referralService,
 		EmailService:      emailService,
 	}
 }
@@ -84,26 +95,62 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	}
 
 	var orderItems []OrderItem
-	
+	var totalPrice float64
+
 	for _, cart := range carts.CartItems {
 
-		err = s.productRepository.UpdateQuantityByID(ctx, cart.ProductID, cart.Size, -cart.Quantity)
-		if err != nil {
-			return "", fmt.Errorf("product %s (size %s) is out of stock or insufficient", cart.ProductName, cart.Size)
+		p, err := s.productRepository.FindByID(ctx, cart.ProductID)
+		if err != nil || p == nil {
+			return "", fmt.Errorf("product %s not found", cart.ProductName)
+		}
+
+		// Cart lines added before variants existed only carry a size
+		if cart.VariantID.IsZero() {
+			if variant := p.FindVariantBySize(cart.Size); variant != nil {
+				cart.VariantID = variant.ID
+				cart.SKU = variant.SKU
+				cart.Attributes = variant.Attributes
+			}
 		}
 
+		variant := p.FindVariant(cart.VariantID)
+		if variant == nil {
+			return "", fmt.Errorf("product %s (size %s) is no longer available", cart.ProductName, cart.Size)
+		}
+
+		// The cart keeps the price of when the line was added
+		price := p.VariantPrice(variant)
+
 		orderItem := &OrderItem{
 			ProductID:    cart.ProductID,
+			VariantID:    cart.VariantID,
+			SKU:          cart.SKU,
+			Attributes:   cart.Attributes,
 			ProductName:  cart.ProductName,
 			Quantity:     cart.Quantity,
-			Price:        cart.Price,
-			TotalPrice:   cart.TotalPrice,
+			Price:        price,
+			TotalPrice:   price * float64(cart.Quantity),
 			ProductImage: cart.ImageUrl,
 			Size:         cart.Size,
 		}
+
+		for _, component := range variant.Components {
+			orderItem.Components = append(orderItem.Components, model.OrderComponent{
+				ProductID:   component.ProductID,
+				VariantID:   component.VariantID,
+				SKU:         component.SKU,
+				ProductName: component.ProductName,
+				Attributes:  component.Attributes,
+				Quantity:    component.Quantity,
+			})
+		}
+
+		totalPrice += orderItem.TotalPrice
 		orderItems = append(orderItems, *orderItem)
 	}
 
+	totalPrice = math.Round(totalPrice*100) / 100
+
 	if req.CouponCode != nil {
 		coupon, err := s.couponRepository.FindByCode(ctx, *req.CouponCode)
 		if err != nil {
@@ -112,7 +159,7 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 			}
 			return "", err
 		}
-		priceDiscount := carts.TotalPrice - (carts.TotalPrice * coupon.Discount / 100)
+		priceDiscount := totalPrice - (totalPrice * coupon.Discount / 100)
 		orderData = &Order{
 			ID:        primitive.NewObjectID(),
 			UserID:    userID,
@@ -145,7 +192,7 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 				Address: req.Address,
 			},
 			Status:     Pending,
-			TotalPrice: carts.TotalPrice,
+			TotalPrice: totalPrice,
 			OrderItems: orderItems,
 			CreatedAt:  time.Now(),
 			UpdatedAt:  time.Now(),
@@ -153,8 +200,34 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 
 	}
 
+	sale := model.StockChange{Type: model.MovementSale, Reason: "order " + orderData.OrderCode, ActorID: &userID, OrderID: &orderData.ID}
+
+	allocations, err := s.inventoryService.AllocateOrder(ctx, OrderLines(orderItems), sale)
+	if err != nil {
+		return "", err
+	}
+
+	setAllocations(orderData.OrderItems, allocations)
+
+	if req.RedeemPoints > 0 {
+		pointsDiscount, err := s.loyaltyService.RedeemPoints(ctx, userID, orderData.ID, req.RedeemPoints, orderData.TotalPrice)
+		if err != nil {
+			s.releaseStock(ctx, orderData)
+			return "", err
+		}
+		orderData.PointsRedeemed = req.RedeemPoints
+		orderData.PointsDiscount = pointsDiscount
+		orderData.TotalPrice -= pointsDiscount
+	}
+
 	id, err := s.orderRepo.Create(ctx, orderData)
 	if err != nil {
+		s.releaseStock(ctx, orderData)
+		if orderData.PointsRedeemed > 0 {
+			if err := s.loyaltyService.HandleOrderStatus(ctx, userID, orderData.ID, string(model.OrderCancelled), orderData.TotalPrice); err != nil {
+				log.Printf("failed to refund points of order %s: %v", orderData.OrderCode, err)
+			}
+		}
 		return "", err
 	}
 
@@ -181,6 +254,83 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 
 }
 
+// OrderLines turns order items into the lines the inventory allocates and
+// releases. A bundle becomes one line per component.
+func OrderLines(items []OrderItem) []inventory.OrderLine {
+
+	var lines []inventory.OrderLine
+	for _, item := range items {
+		if len(item.Components) == 0 {
+			lines = append(lines, inventory.OrderLine{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				Quantity:    item.Quantity,
+				Allocations: item.Allocations,
+			})
+			continue
+		}
+
+		for _, component := range item.Components {
+			lines = append(lines, inventory.OrderLine{
+				ProductID:   component.ProductID,
+				VariantID:   component.VariantID,
+				Quantity:    component.Quantity * item.Quantity,
+				Allocations: component.Allocations,
+				BundleID:    &item.ProductID,
+			})
+		}
+	}
+
+	return lines
+}
+
+// setAllocations stores the allocations of OrderLines back on the items and
+// bundle components.
+func setAllocations(items []OrderItem, allocations [][]model.StockAllocation) {
+
+	next := 0
+	for i := range items {
+		if len(items[i].Components) == 0 {
+			items[i].Allocations = allocations[next]
+			next++
+			continue
+		}
+
+		for j := range items[i].Components {
+			items[i].Components[j].Allocations = allocations[next]
+			next++
+		}
+	}
+}
+
+// releaseStock gives back the stock of an order that could not be placed.
+func (s *orderService) releaseStock(ctx context.Context, orderData *Order) {
+
+	change := model.StockChange{Type: model.MovementCancellation, Reason: "order not placed", OrderID: &orderData.ID}
+
+	if err := s.inventoryService.ReleaseOrder(ctx, OrderLines(orderData.OrderItems), change); err != nil {
+		log.Printf("failed to release stock of order %s: %v", orderData.OrderCode, err)
+	}
+}
+
+// SyncOrderStock forwards an order status to the inventory and saves the
+// allocations of an order allocated again.
+func SyncOrderStock(ctx context.Context, inventoryService inventory.InventoryService, orderRepo OrderRepository, orderData *Order, status string) error {
+
+	allocations, err := inventoryService.HandleOrderStatus(ctx, orderData.ID, status, OrderLines(orderData.OrderItems))
+	if err != nil {
+		return err
+	}
+
+	if allocations == nil {
+		return nil
+	}
+
+	setAllocations(orderData.OrderItems, allocations)
+
+	return orderRepo.UpdateAllocations(ctx, orderData.ID, orderData.OrderItems)
+}
+
 func (s *orderService) generateOrderCode() string {
 
 	timestamp := time.Now().Format("20060102-150405")
@@ -228,12 +378,14 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]*OrderResponse, erro
 				Phone:   order.ShippingAddress.Phone,
 				Address: order.ShippingAddress.Address,
 			},
-			Status:     order.Status,
-			TotalPrice: order.TotalPrice,
-			OrderItems: order.OrderItems,
-			CreatedAt:  order.CreatedAt,
-			UpdatedAt:  order.UpdatedAt,
-			Payment:    payment,
+			Status:         order.Status,
+			TotalPrice:     order.TotalPrice,
+			OrderItems:     order.OrderItems,
+			PointsRedeemed: order.PointsRedeemed,
+			PointsDiscount: order.PointsDiscount,
+			CreatedAt:      order.CreatedAt,
+			UpdatedAt:      order.UpdatedAt,
+			Payment:        payment,
 		})
 	}
 
@@ -277,12 +429,14 @@ func (s *orderService) GetOrderByID(ctx context.Context, id string) (*OrderRespo
 			Phone:   order.ShippingAddress.Phone,
 			Address: order.ShippingAddress.Address,
 		},
-		Status:     order.Status,
-		TotalPrice: order.TotalPrice,
-		OrderItems: order.OrderItems,
-		CreatedAt:  order.CreatedAt,
-		UpdatedAt:  order.UpdatedAt,
-		Payment:    payment,
+		Status:         order.Status,
+		TotalPrice:     order.TotalPrice,
+		OrderItems:     order.OrderItems,
+		PointsRedeemed: order.PointsRedeemed,
+		PointsDiscount: order.PointsDiscount,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+		Payment:        payment,
 	}
 
 	return data, nil
@@ -299,10 +453,38 @@ func (s *orderService) UpdateOrder(ctx context.Context, req *UpdateOrderRequest,
 		return fmt.Errorf("invalid id: %v", err)
 	}
 
+	order, err := s.orderRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	// A reopened order may no longer find stock
+	if err := SyncOrderStock(ctx, s.inventoryService, s.orderRepo, order, req.Status); err != nil {
+		return err
+	}
+
+	// The hooks only apply what their ledgers have not seen yet, so they run
+	// before the status is saved and a failed update can be sent again
+	if err := s.orderHooks(ctx, order, req.Status); err != nil {
+		if syncErr := SyncOrderStock(ctx, s.inventoryService, s.orderRepo, order, string(order.Status)); syncErr != nil {
+			log.Printf("failed to restore stock of order %s: %v", order.OrderCode, syncErr)
+		}
+		return err
+	}
+
 	return s.orderRepo.UpdateByID(ctx, objectID, req.Status)
 
 }
 
+func (s *orderService) orderHooks(ctx context.Context, order *Order, status string) error {
+
+	if err := s.loyaltyService.HandleOrderStatus(ctx, order.UserID, order.ID, status, order.TotalPrice); err != nil {
+		return err
+	}
+
+	return s.referralService.HandleOrderStatus(ctx, order.UserID, order.ID, status)
+}
+
 func (s *orderService) DeleteOrder(ctx context.Context, id string) error {
 
 	if id == "" {
@@ -314,6 +496,20 @@ func (s *orderService) DeleteOrder(ctx context.Context, id string) error {
 		return fmt.Errorf("invalid id: %v", err)
 	}
 
+	order, err := s.orderRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	// Give back the stock and points of the order as a cancellation would
+	if err := SyncOrderStock(ctx, s.inventoryService, s.orderRepo, order, string(model.OrderCancelled)); err != nil {
+		return err
+	}
+
+	if err := s.orderHooks(ctx, order, string(model.OrderCancelled)); err != nil {
+		return err
+	}
+
 	return s.orderRepo.DeleteByID(ctx, objectID)
 
 }
@@ -360,12 +556,14 @@ func (s *orderService) GetOrderByUserID(ctx context.Context, userID string) ([]*
 				Phone:   order.ShippingAddress.Phone,
 				Address: order.ShippingAddress.Address,
 			},
-			Status:     order.Status,
-			TotalPrice: order.TotalPrice,
-			OrderItems: order.OrderItems,
-			CreatedAt:  order.CreatedAt,
-			UpdatedAt:  order.UpdatedAt,
-			Payment:    payment,
+			Status:         order.Status,
+			TotalPrice:     order.TotalPrice,
+			OrderItems:     order.OrderItems,
+			PointsRedeemed: order.PointsRedeemed,
+			PointsDiscount: order.PointsDiscount,
+			CreatedAt:      order.CreatedAt,
+			UpdatedAt:      order.UpdatedAt,
+			Payment:        payment,
 		})
 	}
 
@@ -0,0 +1,86 @@
+package order
+
+import (
+	"modular_monolith/internal/inventory"
+	"modular_monolith/internal/shared/model"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOrderLines(t *testing.T) {
+
+	shirtID, shirtVariantID := primitive.NewObjectID(), primitive.NewObjectID()
+	bundleID, bundleVariantID := primitive.NewObjectID(), primitive.NewObjectID()
+	socksID, socksVariantID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	tests := []struct {
+		name  string
+		items []OrderItem
+		want  []inventory.OrderLine
+	}{
+		{
+			name:  "plain item",
+			items: []OrderItem{{ProductID: shirtID, VariantID: shirtVariantID, Quantity: 2}},
+			want:  []inventory.OrderLine{{ProductID: shirtID, VariantID: shirtVariantID, Quantity: 2}},
+		},
+		{
+			name: "bundle quantity multiplies its components",
+			items: []OrderItem{{
+				ProductID: bundleID,
+				VariantID: bundleVariantID,
+				Quantity:  3,
+				Components: []model.OrderComponent{
+					{ProductID: shirtID, VariantID: shirtVariantID, Quantity: 1},
+					{ProductID: socksID, VariantID: socksVariantID, Quantity: 2},
+				},
+			}},
+			want: []inventory.OrderLine{
+				{ProductID: shirtID, VariantID: shirtVariantID, Quantity: 3, BundleID: &bundleID},
+				{ProductID: socksID, VariantID: socksVariantID, Quantity: 6, BundleID: &bundleID},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OrderLines(tt.items)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderLines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAllocations(t *testing.T) {
+
+	warehouseID := primitive.NewObjectID()
+	allocation := func(quantity int) []model.StockAllocation {
+		return []model.StockAllocation{{WarehouseID: warehouseID, Quantity: quantity}}
+	}
+
+	items := []OrderItem{
+		{Quantity: 1},
+		{Quantity: 2, Components: []model.OrderComponent{{Quantity: 1}, {Quantity: 3}}},
+		{Quantity: 4},
+	}
+
+	setAllocations(items, [][]model.StockAllocation{allocation(1), allocation(2), allocation(6), allocation(4)})
+
+	if !reflect.DeepEqual(items[0].Allocations, allocation(1)) {
+		t.Errorf("item 0 allocations = %v, want %v", items[0].Allocations, allocation(1))
+	}
+	if !reflect.DeepEqual(items[1].Components[0].Allocations, allocation(2)) {
+		t.Errorf("component 0 allocations = %v, want %v", items[1].Components[0].Allocations, allocation(2))
+	}
+	if !reflect.DeepEqual(items[1].Components[1].Allocations, allocation(6)) {
+		t.Errorf("component 1 allocations = %v, want %v", items[1].Components[1].Allocations, allocation(6))
+	}
+	if items[1].Allocations != nil {
+		t.Errorf("bundle item allocations = %v, want none", items[1].Allocations)
+	}
+	if !reflect.DeepEqual(items[2].Allocations, allocation(4)) {
+		t.Errorf("item 2 allocations = %v, want %v", items[2].Allocations, allocation(4))
+	}
+}
@@ -56,7 +56,7 @@ func BuildOrderEmailHTML(order Order, brandName string) string {
 	}
 
 	discountAmount := 0.0
-	if order.Discount != nil {
+	if order.Discount != nil || order.PointsDiscount > 0 {
 		discountAmount = subtotal - order.TotalPrice
 		if discountAmount < 0 {
 			discountAmount = 0
@@ -90,7 +90,7 @@ func BuildOrderEmailHTML(order Order, brandName string) string {
 							</td>
 							<td style="padding-left:12px;">
 								<div style="font-weight:600; color:#111">%s</div>
-								<div style="font-size:12px; color:#666; margin-top:2px;">Size: %s &nbsp;•&nbsp; SL: %d</div>
+								<div style="font-size:12px; color:#666; margin-top:2px;">Size: %s &nbsp;•&nbsp; SL: %d</div>%s
 							</td>
 							<td align="right" valign="top" style="white-space:nowrap;">
 								<div style="font-size:13px; color:#666;">%s</div>
@@ -100,7 +100,7 @@ func BuildOrderEmailHTML(order Order, brandName string) string {
 					</table>
 				</td>
 			</tr>
-		`, img, htmlEscape(it.ProductName), htmlEscape(it.Size), it.Quantity, formatVND(it.Price), formatVND(lineTotal)))
+		`, img, htmlEscape(it.ProductName), htmlEscape(it.Size), it.Quantity, bundleContentsHTML(it), formatVND(it.Price), formatVND(lineTotal)))
 	}
 
 	// Template KHÔNG còn logo → chỉ hiển thị brand + mã đơn
@@ -233,3 +233,22 @@ func htmlEscape(s string) string {
 	return r.Replace(s)
   
 }
+
+// bundleContentsHTML lists what is in a bundle under its order line.
+func bundleContentsHTML(it OrderItem) string {
+	if len(it.Components) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, c := range it.Components {
+		part := fmt.Sprintf("%d × %s", c.Quantity, htmlEscape(c.ProductName))
+		if size := c.Attributes["size"]; size != "" {
+			part += " (" + htmlEscape(size) + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	return fmt.Sprintf(`
+								<div style="font-size:12px; color:#666; margin-top:2px;">Gồm: %s</div>`, strings.Join(parts, ", "))
+}
@@ -9,7 +9,10 @@ import (
 	"fmt"
 	"log"
 	"modular_monolith/config"
+	"modular_monolith/internal/inventory"
+	"modular_monolith/internal/loyalty"
 	"modular_monolith/internal/order"
+	"modular_monolith/internal/referral"
 	"modular_monolith/pkg/email"
 	"net/url"
 	"os"
@@ -38,16 +41,22 @@ type PaymentService interface {
 type paymentService struct {
 	orderRepository   order.OrderRepository
 	paymentRepository PaymentRepository
+	inventoryService  inventory.InventoryService
+	loyaltyService    loyalty.LoyaltyService
+	referralService   referral.ReferralService
 	config            config.VNPayConfig
 	emailServie       *email.EmailService
 }
 
-func NewPaymentService(paymentRepository PaymentRepository, orderRepository order.OrderRepository, config config.VNPayConfig) PaymentService {
+func NewPaymentService(paymentRepository PaymentRepository, orderRepository order.OrderRepository, inventoryService inventory.InventoryService, loyaltyService loyalty.LoyaltyService, referralService referral.ReferralService, config config.VNPayConfig) PaymentService {
 	emailService := email.NewEmailService()
 	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
 	return &paymentService{
 		paymentRepository: paymentRepository,
 		orderRepository:   orderRepository,
+		inventoryService:  inventoryService,
+		loyaltyService:    loyaltyService,
+		referralService:   referralService,
 		config:            config,
 		emailServie:       emailService,
 	}
@@ -152,6 +161,8 @@ func (s *paymentService) ConfirmPayment(ctx context.Context, paymentIntentID str
 		return err
 	}
 
+	s.syncOrderRewards(ctx, payment.OrderID, string(newStatus))
+
 	return nil
 }
 
@@ -257,6 +268,11 @@ func (s *paymentService) RepurchaseOrder(ctx context.Context, req *VNPayRequest,
 		return nil, fmt.Errorf("order not found")
 	}
 
+	// A cancelled order gave its stock back and takes it again
+	if err := order.SyncOrderStock(ctx, s.inventoryService, s.orderRepository, existingOrder, string(Pending)); err != nil {
+		return nil, err
+	}
+
 	existingPayment, _ := s.paymentRepository.FindByOrderID(ctx, orderID)
 	if existingPayment != nil {
 		err := s.paymentRepository.DeletePayment(ctx, existingPayment.ID)
@@ -470,9 +486,35 @@ func (s *paymentService) HandleVNPayIPN(ctx context.Context, callback *VNPayCall
 		}
 	}
 
+	s.syncOrderRewards(ctx, payment.OrderID, string(payment.Status))
+
 	return s.paymentRepository.UpdateStatus(ctx, paymentID, payment.Status)
 }
 
+// syncOrderRewards forwards an order status written by a payment callback to the
+// inventory, loyalty and referral hooks. Failures are only logged so the
+// provider does not retry.
+func (s *paymentService) syncOrderRewards(ctx context.Context, orderID primitive.ObjectID, status string) {
+
+	orderData, err := s.orderRepository.FindByID(ctx, orderID)
+	if err != nil {
+		log.Printf("failed to find order for rewards: %v", err)
+		return
+	}
+
+	if err := order.SyncOrderStock(ctx, s.inventoryService, s.orderRepository, orderData, status); err != nil {
+		log.Printf("failed to update order stock: %v", err)
+	}
+
+	if err := s.loyaltyService.HandleOrderStatus(ctx, orderData.UserID, orderData.ID, status, orderData.TotalPrice); err != nil {
+		log.Printf("failed to update loyalty points: %v", err)
+	}
+
+	if err := s.referralService.HandleOrderStatus(ctx, orderData.UserID, orderData.ID, status); err != nil {
+		log.Printf("failed to update referral: %v", err)
+	}
+}
+
 func (s *paymentService) VerifyCallback(callback *VNPayCallback) (bool, error) {
 
 	params := map[string]string{
@@ -504,6 +546,8 @@ func (s *paymentService) CronPaymentExpiration(ctx context.Context) error {
 
 	for _, payment := range payments {
 		if payment.ExpiredAt.Before(nowVN()) {
+			s.syncOrderRewards(ctx, payment.OrderID, string(order.Cancelled))
+
 			err = s.paymentRepository.DeletePayment(ctx, payment.ID)
 			if err != nil {
 				log.Printf("failed to update payment status: %v", err)
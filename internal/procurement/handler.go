@@ -0,0 +1,234 @@
+package procurement
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProcurementHandler struct {
+	ProcurementService ProcurementService
+}
+
+func NewProcurementHandler(procurementService ProcurementService) *ProcurementHandler {
+	return &ProcurementHandler{
+		ProcurementService: procurementService,
+	}
+}
+
+func (h *ProcurementHandler) GetSuppliers(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SupplierFilter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	suppliers, err := h.ProcurementService.GetSuppliers(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", suppliers)
+}
+
+func (h *ProcurementHandler) GetSupplier(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	supplier, err := h.ProcurementService.GetSupplier(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", supplier)
+}
+
+func (h *ProcurementHandler) CreateSupplier(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	supplier, err := h.ProcurementService.CreateSupplier(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", supplier)
+}
+
+func (h *ProcurementHandler) UpdateSupplier(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.ProcurementService.UpdateSupplier(c, userID, c.Param("id"), &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ProcurementHandler) GetPurchaseOrders(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req PurchaseOrderFilter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	purchaseOrders, err := h.ProcurementService.GetPurchaseOrders(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", purchaseOrders)
+}
+
+func (h *ProcurementHandler) GetPurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	purchaseOrder, err := h.ProcurementService.GetPurchaseOrder(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", purchaseOrder)
+}
+
+func (h *ProcurementHandler) CreatePurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	purchaseOrder, err := h.ProcurementService.CreatePurchaseOrder(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", purchaseOrder)
+}
+
+func (h *ProcurementHandler) UpdatePurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	purchaseOrder, err := h.ProcurementService.UpdatePurchaseOrder(c, userID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", purchaseOrder)
+}
+
+func (h *ProcurementHandler) SendPurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	purchaseOrder, err := h.ProcurementService.SendPurchaseOrder(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", purchaseOrder)
+}
+
+func (h *ProcurementHandler) ReceivePurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	purchaseOrder, err := h.ProcurementService.ReceivePurchaseOrder(c, userID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", purchaseOrder)
+}
+
+func (h *ProcurementHandler) CancelPurchaseOrder(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ProcurementService.CancelPurchaseOrder(c, userID, c.Param("id")); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
@@ -0,0 +1,75 @@
+package procurement
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StatusDraft             = "draft"
+	StatusSent              = "sent"
+	StatusPartiallyReceived = "partially_received"
+	StatusReceived          = "received"
+	StatusCancelled         = "cancelled"
+)
+
+type Supplier struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	Name         string             `json:"name" bson:"name"`
+	ContactName  string             `json:"contact_name" bson:"contact_name"`
+	Email        string             `json:"email" bson:"email"`
+	Phone        string             `json:"phone" bson:"phone"`
+	Address      string             `json:"address" bson:"address"`
+	LeadTimeDays int                `json:"lead_time_days" bson:"lead_time_days"`
+	Note         string             `json:"note" bson:"note"`
+	Active       bool               `json:"active" bson:"active"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// PurchaseOrder is an order of goods from a supplier, delivered to one
+// warehouse. Goods may arrive in several receipts.
+type PurchaseOrder struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	Code        string              `json:"code" bson:"code"`
+	SupplierID  primitive.ObjectID  `json:"supplier_id" bson:"supplier_id"`
+	WarehouseID primitive.ObjectID  `json:"warehouse_id" bson:"warehouse_id"`
+	Status      string              `json:"status" bson:"status"`
+	Lines       []PurchaseOrderLine `json:"lines" bson:"lines"`
+	Receipts    []Receipt           `json:"receipts" bson:"receipts"`
+	TotalCost   float64             `json:"total_cost" bson:"total_cost"`
+	Note        string              `json:"note" bson:"note"`
+	ExpectedAt  *time.Time          `json:"expected_at" bson:"expected_at"`
+	CreatedBy   primitive.ObjectID  `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+	SentAt      *time.Time          `json:"sent_at" bson:"sent_at"`
+	ReceivedAt  *time.Time          `json:"received_at" bson:"received_at"`
+}
+
+type PurchaseOrderLine struct {
+	ProductID        primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID        primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU              string             `json:"sku" bson:"sku"`
+	ProductName      string             `json:"product_name" bson:"product_name"`
+	Size             string             `json:"size" bson:"size"`
+	Quantity         int                `json:"quantity" bson:"quantity"`
+	ReceivedQuantity int                `json:"received_quantity" bson:"received_quantity"`
+	UnitCost         float64            `json:"unit_cost" bson:"unit_cost"`
+}
+
+// Receipt is one delivery of goods against a purchase order.
+type Receipt struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	Lines      []ReceiptLine      `json:"lines" bson:"lines"`
+	Note       string             `json:"note" bson:"note"`
+	ReceivedBy primitive.ObjectID `json:"received_by" bson:"received_by"`
+	ReceivedAt time.Time          `json:"received_at" bson:"received_at"`
+}
+
+type ReceiptLine struct {
+	VariantID primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU       string             `json:"sku" bson:"sku"`
+	Quantity  int                `json:"quantity" bson:"quantity"`
+}
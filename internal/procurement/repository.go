@@ -0,0 +1,175 @@
+package procurement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProcurementRepository interface {
+	CreateSupplier(ctx context.Context, supplier *Supplier) error
+	UpdateSupplier(ctx context.Context, supplier *Supplier) error
+	FindSupplierByID(ctx context.Context, id primitive.ObjectID) (*Supplier, error)
+	FindSuppliers(ctx context.Context, filter bson.M) ([]*Supplier, error)
+	CreatePurchaseOrder(ctx context.Context, purchaseOrder *PurchaseOrder) error
+	UpdatePurchaseOrder(ctx context.Context, purchaseOrder *PurchaseOrder, lastUpdatedAt time.Time) error
+	FindPurchaseOrderByID(ctx context.Context, id primitive.ObjectID) (*PurchaseOrder, error)
+	FindPurchaseOrders(ctx context.Context, filter bson.M, page int, limit int) ([]*PurchaseOrder, int64, error)
+	SumOpenQuantities(ctx context.Context) (map[primitive.ObjectID]int, error)
+}
+
+type procurementRepository struct {
+	suppliers      *mongo.Collection
+	purchaseOrders *mongo.Collection
+}
+
+func NewProcurementRepository(suppliers *mongo.Collection, purchaseOrders *mongo.Collection) ProcurementRepository {
+	return &procurementRepository{
+		suppliers:      suppliers,
+		purchaseOrders: purchaseOrders,
+	}
+}
+
+func (r *procurementRepository) CreateSupplier(ctx context.Context, supplier *Supplier) error {
+	_, err := r.suppliers.InsertOne(ctx, supplier)
+	return err
+}
+
+func (r *procurementRepository) UpdateSupplier(ctx context.Context, supplier *Supplier) error {
+	_, err := r.suppliers.ReplaceOne(ctx, bson.M{"_id": supplier.ID}, supplier)
+	return err
+}
+
+func (r *procurementRepository) FindSupplierByID(ctx context.Context, id primitive.ObjectID) (*Supplier, error) {
+
+	var supplier *Supplier
+
+	err := r.suppliers.FindOne(ctx, bson.M{"_id": id}).Decode(&supplier)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (r *procurementRepository) FindSuppliers(ctx context.Context, filter bson.M) ([]*Supplier, error) {
+
+	var suppliers []*Supplier
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.suppliers.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &suppliers); err != nil {
+		return nil, err
+	}
+
+	return suppliers, nil
+}
+
+func (r *procurementRepository) CreatePurchaseOrder(ctx context.Context, purchaseOrder *PurchaseOrder) error {
+	_, err := r.purchaseOrders.InsertOne(ctx, purchaseOrder)
+	return err
+}
+
+// UpdatePurchaseOrder saves the purchase order unless it was changed since it
+// was read, so two receipts of the same goods cannot both be booked.
+func (r *procurementRepository) UpdatePurchaseOrder(ctx context.Context, purchaseOrder *PurchaseOrder, lastUpdatedAt time.Time) error {
+
+	res, err := r.purchaseOrders.ReplaceOne(ctx, bson.M{"_id": purchaseOrder.ID, "updated_at": lastUpdatedAt}, purchaseOrder)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("purchase order %s was changed meanwhile, please try again", purchaseOrder.Code)
+	}
+
+	return nil
+}
+
+func (r *procurementRepository) FindPurchaseOrderByID(ctx context.Context, id primitive.ObjectID) (*PurchaseOrder, error) {
+
+	var purchaseOrder *PurchaseOrder
+
+	err := r.purchaseOrders.FindOne(ctx, bson.M{"_id": id}).Decode(&purchaseOrder)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+func (r *procurementRepository) FindPurchaseOrders(ctx context.Context, filter bson.M, page int, limit int) ([]*PurchaseOrder, int64, error) {
+
+	total, err := r.purchaseOrders.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"receipts": 0})
+
+	cursor, err := r.purchaseOrders.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var purchaseOrders []*PurchaseOrder
+	if err := cursor.All(ctx, &purchaseOrders); err != nil {
+		return nil, 0, err
+	}
+
+	return purchaseOrders, total, nil
+}
+
+// SumOpenQuantities returns the quantity per variant that sent purchase orders
+// are still to deliver.
+func (r *procurementRepository) SumOpenQuantities(ctx context.Context) (map[primitive.ObjectID]int, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$in": []string{StatusSent, StatusPartiallyReceived}}}}},
+		{{Key: "$unwind", Value: "$lines"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$lines.variant_id",
+			"quantity": bson.M{"$sum": bson.M{"$subtract": []string{"$lines.quantity", "$lines.received_quantity"}}},
+		}}},
+	}
+
+	cursor, err := r.purchaseOrders.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		VariantID primitive.ObjectID `bson:"_id"`
+		Quantity  int                `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[primitive.ObjectID]int)
+	for _, result := range results {
+		totals[result.VariantID] = result.Quantity
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,71 @@
+package procurement
+
+type CreateSupplierRequest struct {
+	Name         string `json:"name"`
+	ContactName  string `json:"contact_name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	Note         string `json:"note"`
+}
+
+type UpdateSupplierRequest struct {
+	Name         *string `json:"name"`
+	ContactName  *string `json:"contact_name"`
+	Email        *string `json:"email"`
+	Phone        *string `json:"phone"`
+	Address      *string `json:"address"`
+	LeadTimeDays *int    `json:"lead_time_days"`
+	Note         *string `json:"note"`
+	Active       *bool   `json:"active"`
+}
+
+type SupplierFilter struct {
+	IncludeInactive bool `form:"include_inactive"`
+}
+
+// PurchaseOrderLineRequest picks the variant by VariantID, or by Size when
+// VariantID is empty.
+type PurchaseOrderLineRequest struct {
+	ProductID string  `json:"product_id"`
+	VariantID string  `json:"variant_id"`
+	Size      string  `json:"size"`
+	Quantity  int     `json:"quantity"`
+	UnitCost  float64 `json:"unit_cost"`
+}
+
+// CreatePurchaseOrderRequest delivers to the default warehouse when
+// WarehouseID is empty. ExpectedAt is formatted as 2006-01-02.
+type CreatePurchaseOrderRequest struct {
+	SupplierID  string                     `json:"supplier_id"`
+	WarehouseID string                     `json:"warehouse_id"`
+	Lines       []PurchaseOrderLineRequest `json:"lines"`
+	Note        string                     `json:"note"`
+	ExpectedAt  string                     `json:"expected_at"`
+}
+
+// UpdatePurchaseOrderRequest replaces the lines when Lines is not null.
+type UpdatePurchaseOrderRequest struct {
+	WarehouseID *string                    `json:"warehouse_id"`
+	Lines       []PurchaseOrderLineRequest `json:"lines"`
+	Note        *string                    `json:"note"`
+	ExpectedAt  *string                    `json:"expected_at"`
+}
+
+type ReceiveLineRequest struct {
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ReceiveRequest struct {
+	Lines []ReceiveLineRequest `json:"lines"`
+	Note  string               `json:"note"`
+}
+
+type PurchaseOrderFilter struct {
+	SupplierID string `form:"supplier_id"`
+	Status     string `form:"status"`
+	Page       int    `form:"page"`
+	Limit      int    `form:"limit"`
+}
@@ -0,0 +1,8 @@
+package procurement
+
+import "modular_monolith/internal/product"
+
+type PurchaseOrdersResponse struct {
+	PurchaseOrders []*PurchaseOrder   `json:"purchase_orders"`
+	Pagination     product.Pagination `json:"pagination"`
+}
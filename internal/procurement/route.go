@@ -0,0 +1,24 @@
+package procurement
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *ProcurementHandler) {
+	procurementGroup := r.Group("/api/v1/procurement", middleware.JWTAuthMiddleware())
+	{
+		procurementGroup.GET("/suppliers", handler.GetSuppliers)
+		procurementGroup.POST("/suppliers", handler.CreateSupplier)
+		procurementGroup.GET("/suppliers/:id", handler.GetSupplier)
+		procurementGroup.PUT("/suppliers/:id", handler.UpdateSupplier)
+		procurementGroup.GET("/purchase-orders", handler.GetPurchaseOrders)
+		procurementGroup.POST("/purchase-orders", handler.CreatePurchaseOrder)
+		procurementGroup.GET("/purchase-orders/:id", handler.GetPurchaseOrder)
+		procurementGroup.PUT("/purchase-orders/:id", handler.UpdatePurchaseOrder)
+		procurementGroup.POST("/purchase-orders/:id/send", handler.SendPurchaseOrder)
+		procurementGroup.POST("/purchase-orders/:id/receive", handler.ReceivePurchaseOrder)
+		procurementGroup.DELETE("/purchase-orders/:id", handler.CancelPurchaseOrder)
+	}
+}
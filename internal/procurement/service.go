@@ -0,0 +1,697 @@
+package procurement
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"modular_monolith/internal/inventory"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/user"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProcurementService interface {
+	GetSuppliers(ctx context.Context, userID string, filter *SupplierFilter) ([]*Supplier, error)
+	GetSupplier(ctx context.Context, userID string, id string) (*Supplier, error)
+	CreateSupplier(ctx context.Context, userID string, req *CreateSupplierRequest) (*Supplier, error)
+	UpdateSupplier(ctx context.Context, userID string, id string, req *UpdateSupplierRequest) error
+	GetPurchaseOrders(ctx context.Context, userID string, filter *PurchaseOrderFilter) (*PurchaseOrdersResponse, error)
+	GetPurchaseOrder(ctx context.Context, userID string, id string) (*PurchaseOrder, error)
+	CreatePurchaseOrder(ctx context.Context, userID string, req *CreatePurchaseOrderRequest) (*PurchaseOrder, error)
+	UpdatePurchaseOrder(ctx context.Context, userID string, id string, req *UpdatePurchaseOrderRequest) (*PurchaseOrder, error)
+	SendPurchaseOrder(ctx context.Context, userID string, id string) (*PurchaseOrder, error)
+	ReceivePurchaseOrder(ctx context.Context, userID string, id string, req *ReceiveRequest) (*PurchaseOrder, error)
+	CancelPurchaseOrder(ctx context.Context, userID string, id string) error
+}
+
+type procurementService struct {
+	repository          ProcurementRepository
+	inventoryRepository inventory.InventoryRepository
+	inventoryService    inventory.InventoryService
+	productRepository   product.ProductRepository
+	userRepository      user.UserRepository
+}
+
+func NewProcurementService(repository ProcurementRepository, inventoryRepository inventory.InventoryRepository, inventoryService inventory.InventoryService, productRepository product.ProductRepository, userRepository user.UserRepository) ProcurementService {
+	return &procurementService{
+		repository:          repository,
+		inventoryRepository: inventoryRepository,
+		inventoryService:    inventoryService,
+		productRepository:   productRepository,
+		userRepository:      userRepository,
+	}
+}
+
+func (s *procurementService) GetSuppliers(ctx context.Context, userID string, filter *SupplierFilter) ([]*Supplier, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	query := bson.M{}
+	if !filter.IncludeInactive {
+		query["active"] = true
+	}
+
+	suppliers, err := s.repository.FindSuppliers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if suppliers == nil {
+		suppliers = []*Supplier{}
+	}
+
+	return suppliers, nil
+}
+
+func (s *procurementService) GetSupplier(ctx context.Context, userID string, id string) (*Supplier, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	return s.findSupplier(ctx, id)
+}
+
+func (s *procurementService) CreateSupplier(ctx context.Context, userID string, req *CreateSupplierRequest) (*Supplier, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if req.LeadTimeDays < 0 {
+		return nil, fmt.Errorf("lead time must not be negative")
+	}
+
+	now := time.Now()
+
+	supplier := &Supplier{
+		ID:           primitive.NewObjectID(),
+		Name:         strings.TrimSpace(req.Name),
+		ContactName:  req.ContactName,
+		Email:        strings.TrimSpace(req.Email),
+		Phone:        req.Phone,
+		Address:      req.Address,
+		LeadTimeDays: req.LeadTimeDays,
+		Note:         req.Note,
+		Active:       true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.repository.CreateSupplier(ctx, supplier); err != nil {
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (s *procurementService) UpdateSupplier(ctx context.Context, userID string, id string, req *UpdateSupplierRequest) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	supplier, err := s.findSupplier(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return fmt.Errorf("name is required")
+		}
+		supplier.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.LeadTimeDays != nil {
+		if *req.LeadTimeDays < 0 {
+			return fmt.Errorf("lead time must not be negative")
+		}
+		supplier.LeadTimeDays = *req.LeadTimeDays
+	}
+
+	if req.ContactName != nil {
+		supplier.ContactName = *req.ContactName
+	}
+	if req.Email != nil {
+		supplier.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.Phone != nil {
+		supplier.Phone = *req.Phone
+	}
+	if req.Address != nil {
+		supplier.Address = *req.Address
+	}
+	if req.Note != nil {
+		supplier.Note = *req.Note
+	}
+	if req.Active != nil {
+		supplier.Active = *req.Active
+	}
+
+	supplier.UpdatedAt = time.Now()
+
+	return s.repository.UpdateSupplier(ctx, supplier)
+}
+
+func (s *procurementService) GetPurchaseOrders(ctx context.Context, userID string, filter *PurchaseOrderFilter) (*PurchaseOrdersResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	} else if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	query := bson.M{}
+
+	if filter.SupplierID != "" {
+		supplierID, err := primitive.ObjectIDFromHex(filter.SupplierID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supplier id: %v", err)
+		}
+		query["supplier_id"] = supplierID
+	}
+
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	purchaseOrders, total, err := s.repository.FindPurchaseOrders(ctx, query, filter.Page, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseOrders == nil {
+		purchaseOrders = []*PurchaseOrder{}
+	}
+
+	result := &PurchaseOrdersResponse{
+		PurchaseOrders: purchaseOrders,
+		Pagination: product.Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		},
+	}
+
+	return result, nil
+}
+
+func (s *procurementService) GetPurchaseOrder(ctx context.Context, userID string, id string) (*PurchaseOrder, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	return s.findPurchaseOrder(ctx, id)
+}
+
+func (s *procurementService) CreatePurchaseOrder(ctx context.Context, userID string, req *CreatePurchaseOrderRequest) (*PurchaseOrder, error) {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier, err := s.findSupplier(ctx, req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !supplier.Active {
+		return nil, fmt.Errorf("supplier %s is not active", supplier.Name)
+	}
+
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.buildLines(ctx, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedAt, err := parseExpectedAt(req.ExpectedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	purchaseOrder := &PurchaseOrder{
+		ID:          primitive.NewObjectID(),
+		Code:        generatePurchaseOrderCode(),
+		SupplierID:  supplier.ID,
+		WarehouseID: warehouseID,
+		Status:      StatusDraft,
+		Lines:       lines,
+		Receipts:    []Receipt{},
+		TotalCost:   totalCost(lines),
+		Note:        req.Note,
+		ExpectedAt:  expectedAt,
+		CreatedBy:   staff.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repository.CreatePurchaseOrder(ctx, purchaseOrder); err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+// UpdatePurchaseOrder edits a purchase order while it is still a draft.
+func (s *procurementService) UpdatePurchaseOrder(ctx context.Context, userID string, id string, req *UpdatePurchaseOrderRequest) (*PurchaseOrder, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	purchaseOrder, err := s.findPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseOrder.Status != StatusDraft {
+		return nil, fmt.Errorf("only draft purchase orders can be edited")
+	}
+
+	lastUpdatedAt := purchaseOrder.UpdatedAt
+
+	if req.WarehouseID != nil {
+		warehouseID, err := s.resolveWarehouse(ctx, *req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		purchaseOrder.WarehouseID = warehouseID
+	}
+
+	if req.Lines != nil {
+		lines, err := s.buildLines(ctx, req.Lines)
+		if err != nil {
+			return nil, err
+		}
+		purchaseOrder.Lines = lines
+		purchaseOrder.TotalCost = totalCost(lines)
+	}
+
+	if req.ExpectedAt != nil {
+		expectedAt, err := parseExpectedAt(*req.ExpectedAt)
+		if err != nil {
+			return nil, err
+		}
+		purchaseOrder.ExpectedAt = expectedAt
+	}
+
+	if req.Note != nil {
+		purchaseOrder.Note = *req.Note
+	}
+
+	purchaseOrder.UpdatedAt = time.Now()
+
+	if err := s.repository.UpdatePurchaseOrder(ctx, purchaseOrder, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier. The expected
+// delivery date defaults to the supplier lead time.
+func (s *procurementService) SendPurchaseOrder(ctx context.Context, userID string, id string) (*PurchaseOrder, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	purchaseOrder, err := s.findPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseOrder.Status != StatusDraft {
+		return nil, fmt.Errorf("only draft purchase orders can be sent")
+	}
+
+	supplier, err := s.repository.FindSupplierByID(ctx, purchaseOrder.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if supplier == nil || !supplier.Active {
+		return nil, fmt.Errorf("supplier is not active")
+	}
+
+	lastUpdatedAt := purchaseOrder.UpdatedAt
+	now := time.Now()
+
+	if purchaseOrder.ExpectedAt == nil && supplier.LeadTimeDays > 0 {
+		expectedAt := now.AddDate(0, 0, supplier.LeadTimeDays)
+		purchaseOrder.ExpectedAt = &expectedAt
+	}
+
+	purchaseOrder.Status = StatusSent
+	purchaseOrder.SentAt = &now
+	purchaseOrder.UpdatedAt = now
+
+	if err := s.repository.UpdatePurchaseOrder(ctx, purchaseOrder, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+// ReceivePurchaseOrder books a delivery against a sent purchase order into
+// its warehouse.
+func (s *procurementService) ReceivePurchaseOrder(ctx context.Context, userID string, id string, req *ReceiveRequest) (*PurchaseOrder, error) {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrder, err := s.findPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseOrder.Status != StatusSent && purchaseOrder.Status != StatusPartiallyReceived {
+		return nil, fmt.Errorf("purchase order %s cannot be received", purchaseOrder.Status)
+	}
+
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("lines are required")
+	}
+
+	lineIndex := make(map[primitive.ObjectID]int)
+	for i, line := range purchaseOrder.Lines {
+		lineIndex[line.VariantID] = i
+	}
+
+	received := make(map[int]int)
+
+	for _, reqLine := range req.Lines {
+		variantID, err := primitive.ObjectIDFromHex(reqLine.VariantID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variant id: %v", err)
+		}
+
+		i, ok := lineIndex[variantID]
+		if !ok {
+			return nil, fmt.Errorf("variant %s is not on the purchase order", reqLine.VariantID)
+		}
+
+		if reqLine.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than 0")
+		}
+
+		received[i] += reqLine.Quantity
+	}
+
+	for i, quantity := range received {
+		line := purchaseOrder.Lines[i]
+		if remaining := line.Quantity - line.ReceivedQuantity; quantity > remaining {
+			return nil, fmt.Errorf("only %d of %s are still to be received", remaining, line.SKU)
+		}
+	}
+
+	receipt := Receipt{
+		ID:         primitive.NewObjectID(),
+		Note:       req.Note,
+		ReceivedBy: staff.ID,
+		ReceivedAt: time.Now(),
+	}
+
+	change := model.StockChange{
+		Type:        model.MovementPurchase,
+		Reason:      "purchase order " + purchaseOrder.Code,
+		ActorID:     &staff.ID,
+		ReferenceID: &purchaseOrder.ID,
+	}
+
+	// Lines booked before a failure are saved so the rest can be received again
+	var failed error
+
+	for i := range purchaseOrder.Lines {
+		quantity, ok := received[i]
+		if !ok {
+			continue
+		}
+
+		line := &purchaseOrder.Lines[i]
+
+		if err := s.inventoryService.ReceiveStock(ctx, purchaseOrder.WarehouseID, line.ProductID, line.VariantID, quantity, change); err != nil {
+			failed = fmt.Errorf("failed to receive %s: %v", line.SKU, err)
+			break
+		}
+
+		line.ReceivedQuantity += quantity
+		receipt.Lines = append(receipt.Lines, ReceiptLine{VariantID: line.VariantID, SKU: line.SKU, Quantity: quantity})
+	}
+
+	if len(receipt.Lines) == 0 {
+		return nil, failed
+	}
+
+	lastUpdatedAt := purchaseOrder.UpdatedAt
+
+	purchaseOrder.Receipts = append(purchaseOrder.Receipts, receipt)
+	purchaseOrder.Status = StatusReceived
+	for _, line := range purchaseOrder.Lines {
+		if line.ReceivedQuantity < line.Quantity {
+			purchaseOrder.Status = StatusPartiallyReceived
+			break
+		}
+	}
+
+	if purchaseOrder.Status == StatusReceived {
+		purchaseOrder.ReceivedAt = &receipt.ReceivedAt
+	}
+	purchaseOrder.UpdatedAt = receipt.ReceivedAt
+
+	if err := s.repository.UpdatePurchaseOrder(ctx, purchaseOrder, lastUpdatedAt); err != nil {
+		log.Printf("stock of receipt %s was booked but purchase order %s was not saved: %v", receipt.ID.Hex(), purchaseOrder.Code, err)
+		return nil, err
+	}
+
+	if failed != nil {
+		return nil, failed
+	}
+
+	return purchaseOrder, nil
+}
+
+// CancelPurchaseOrder cancels a purchase order before any goods arrived.
+func (s *procurementService) CancelPurchaseOrder(ctx context.Context, userID string, id string) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	purchaseOrder, err := s.findPurchaseOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if purchaseOrder.Status != StatusDraft && purchaseOrder.Status != StatusSent {
+		return fmt.Errorf("purchase order %s cannot be cancelled", purchaseOrder.Status)
+	}
+
+	lastUpdatedAt := purchaseOrder.UpdatedAt
+
+	purchaseOrder.Status = StatusCancelled
+	purchaseOrder.UpdatedAt = time.Now()
+
+	return s.repository.UpdatePurchaseOrder(ctx, purchaseOrder, lastUpdatedAt)
+}
+
+func (s *procurementService) buildLines(ctx context.Context, reqLines []PurchaseOrderLineRequest) ([]PurchaseOrderLine, error) {
+
+	if len(reqLines) == 0 {
+		return nil, fmt.Errorf("lines are required")
+	}
+
+	var lines []PurchaseOrderLine
+	seen := make(map[primitive.ObjectID]bool)
+
+	for _, reqLine := range reqLines {
+		productID, err := primitive.ObjectIDFromHex(reqLine.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product id: %v", err)
+		}
+
+		p, err := s.productRepository.FindByID(ctx, productID)
+		if err != nil || p == nil || p.DeletedAt != nil {
+			return nil, fmt.Errorf("product %s not found", reqLine.ProductID)
+		}
+
+		if p.IsBundle() {
+			return nil, fmt.Errorf("bundle %s is restocked through its components", p.ProductName)
+		}
+
+		var variant *product.Variant
+		if reqLine.VariantID != "" {
+			variantID, err := primitive.ObjectIDFromHex(reqLine.VariantID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid variant id: %v", err)
+			}
+			variant = p.FindVariant(variantID)
+		} else {
+			variant = p.FindVariantBySize(reqLine.Size)
+		}
+
+		if variant == nil {
+			return nil, fmt.Errorf("product %s has no such variant", p.ProductName)
+		}
+
+		if seen[variant.ID] {
+			return nil, fmt.Errorf("variant %s is listed twice", variant.SKU)
+		}
+		seen[variant.ID] = true
+
+		if reqLine.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than 0")
+		}
+
+		if reqLine.UnitCost < 0 {
+			return nil, fmt.Errorf("unit cost must not be negative")
+		}
+
+		lines = append(lines, PurchaseOrderLine{
+			ProductID:   p.ID,
+			VariantID:   variant.ID,
+			SKU:         variant.SKU,
+			ProductName: p.ProductName,
+			Size:        variant.Attributes["size"],
+			Quantity:    reqLine.Quantity,
+			UnitCost:    reqLine.UnitCost,
+		})
+	}
+
+	return lines, nil
+}
+
+func (s *procurementService) resolveWarehouse(ctx context.Context, id string) (primitive.ObjectID, error) {
+
+	var warehouse *inventory.Warehouse
+	var err error
+
+	if id == "" {
+		warehouse, err = s.inventoryRepository.FindDefaultWarehouse(ctx)
+	} else {
+		objectID, parseErr := primitive.ObjectIDFromHex(id)
+		if parseErr != nil {
+			return primitive.NilObjectID, fmt.Errorf("invalid warehouse id: %v", parseErr)
+		}
+		warehouse, err = s.inventoryRepository.FindWarehouseByID(ctx, objectID)
+	}
+
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	if warehouse == nil {
+		return primitive.NilObjectID, fmt.Errorf("warehouse not found")
+	}
+
+	return warehouse.ID, nil
+}
+
+func (s *procurementService) findSupplier(ctx context.Context, id string) (*Supplier, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supplier id: %v", err)
+	}
+
+	supplier, err := s.repository.FindSupplierByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if supplier == nil {
+		return nil, fmt.Errorf("supplier not found")
+	}
+
+	return supplier, nil
+}
+
+func (s *procurementService) findPurchaseOrder(ctx context.Context, id string) (*PurchaseOrder, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid purchase order id: %v", err)
+	}
+
+	purchaseOrder, err := s.repository.FindPurchaseOrderByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseOrder == nil {
+		return nil, fmt.Errorf("purchase order not found")
+	}
+
+	return purchaseOrder, nil
+}
+
+func parseExpectedAt(value string) (*time.Time, error) {
+
+	if value == "" {
+		return nil, nil
+	}
+
+	expectedAt, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected date: %v", err)
+	}
+
+	return &expectedAt, nil
+}
+
+func totalCost(lines []PurchaseOrderLine) float64 {
+
+	total := 0.0
+	for _, line := range lines {
+		total += float64(line.Quantity) * line.UnitCost
+	}
+
+	return total
+}
+
+func generatePurchaseOrderCode() string {
+
+	timestamp := time.Now().Format("20060102-150405")
+
+	b := make([]byte, 4)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf("PO-%s-%s", timestamp, hex.EncodeToString(b))
+}
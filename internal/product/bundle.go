@@ -0,0 +1,94 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BundleComponent is one item of a bundle variant. Quantity is the number of
+// units in one bundle.
+type BundleComponent struct {
+	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID   primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU         string             `json:"sku" bson:"sku"`
+	ProductName string             `json:"product_name" bson:"product_name"`
+	Attributes  map[string]string  `json:"attributes" bson:"attributes"`
+	Quantity    int                `json:"quantity" bson:"quantity"`
+}
+
+// IsBundle reports whether the product is sold as a set of other products.
+func (p *Product) IsBundle() bool {
+	return isBundle(p.Variants)
+}
+
+func isBundle(variants []Variant) bool {
+	for _, v := range variants {
+		if len(v.Components) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *productService) buildComponents(ctx context.Context, bundleID primitive.ObjectID, reqComponents []BundleComponentRequest, index int) ([]BundleComponent, error) {
+
+	var components []BundleComponent
+	seen := make(map[primitive.ObjectID]bool)
+
+	for _, req := range reqComponents {
+
+		productID, err := primitive.ObjectIDFromHex(req.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component product id for variant at index %d: %v", index, err)
+		}
+
+		if productID == bundleID {
+			return nil, fmt.Errorf("a bundle cannot contain itself")
+		}
+
+		variantID, err := primitive.ObjectIDFromHex(req.VariantID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component variant id for variant at index %d: %v", index, err)
+		}
+
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid component quantity for variant at index %d", index)
+		}
+
+		if seen[variantID] {
+			return nil, fmt.Errorf("duplicate component for variant at index %d", index)
+		}
+		seen[variantID] = true
+
+		component, err := s.repository.FindByID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+
+		if component == nil || component.DeletedAt != nil {
+			return nil, fmt.Errorf("component product %s not found", req.ProductID)
+		}
+
+		if component.IsBundle() {
+			return nil, fmt.Errorf("bundle %s cannot be a component of another bundle", component.ProductName)
+		}
+
+		variant := component.FindVariant(variantID)
+		if variant == nil {
+			return nil, fmt.Errorf("component variant %s not found", req.VariantID)
+		}
+
+		components = append(components, BundleComponent{
+			ProductID:   component.ID,
+			VariantID:   variant.ID,
+			SKU:         variant.SKU,
+			ProductName: component.ProductName,
+			Attributes:  variant.Attributes,
+			Quantity:    req.Quantity,
+		})
+	}
+
+	return components, nil
+}
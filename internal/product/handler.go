@@ -1,11 +1,14 @@
 package product
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"modular_monolith/helper"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +25,11 @@ func NewProductHandler(productService ProductService) *ProductHandler {
 
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	err := c.Request.ParseMultipartForm(32 << 20)
 	if err != nil {
 		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
@@ -44,16 +52,34 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		}
 	}
 	req.Currency = c.PostForm("currency")
+	req.Slug = c.PostForm("slug")
+	req.MetaTitle = c.PostForm("meta_title")
+	req.MetaDescription = c.PostForm("meta_description")
+	req.Status = c.PostForm("status")
+
+	publishAt, unpublishAt, err := parseSchedule(c)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+	req.PublishAt = publishAt
+	req.UnpublishAt = unpublishAt
 
 	if req.ProductName == "" || req.ProductDescription == "" || req.CategoryID == "" || req.Color == "" {
 		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("invalid request: product_name, product_description, category_id or color is missing"), helper.ErrInvalidRequest)
 		return
 	}
 
+	variants, err := parseVariants(c)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+	req.Variants = variants
+
 	sizeCountStr := c.PostForm("size_count")
-	fmt.Printf("size_count: %s\n", sizeCountStr)
 	sizeCount, err := strconv.Atoi(sizeCountStr)
-	if err != nil || sizeCount == 0 {
+	if len(variants) == 0 && (err != nil || sizeCount == 0) {
 		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("invalid size_count"), helper.ErrInvalidRequest)
 		return
 	}
@@ -87,8 +113,9 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		subImages = subImagesArray
 	}
 	productFiles.SubImages = subImages
-    
-	err = h.ProductService.CreateProduct(c.Request.Context(), &req, productFiles)
+	productFiles.VariantImages = variantImages(c)
+
+	err = h.ProductService.CreateProduct(c.Request.Context(), userID, &req, productFiles)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -115,11 +142,45 @@ func (h *ProductHandler) GetAllProducts(c *gin.Context) {
 
 }
 
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	products, err := h.ProductService.SearchProducts(c, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", products)
+}
+
+func (h *ProductHandler) SuggestProducts(c *gin.Context) {
+
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	suggestions, err := h.ProductService.SuggestProducts(c, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", suggestions)
+}
+
 func (h *ProductHandler) GetProductByID(c *gin.Context) {
 
 	id := c.Param("id")
 
-	product, err := h.ProductService.GetProductByID(c, id)
+	product, err := h.ProductService.GetProductByID(c, viewerID(c), id)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -129,10 +190,33 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 
 }
 
+func (h *ProductHandler) GetProductBySlug(c *gin.Context) {
+
+	slug := c.Param("slug")
+
+	product, err := h.ProductService.GetProductBySlug(c, viewerID(c), slug)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	if product.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/product/slug/"+product.Slug)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", product)
+}
+
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 
 	id := c.Param("id")
 
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
 		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 		return
@@ -154,6 +238,18 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		}
 	}
 	req.Currency = c.PostForm("currency")
+	req.Slug = c.PostForm("slug")
+	req.MetaTitle = c.PostForm("meta_title")
+	req.MetaDescription = c.PostForm("meta_description")
+	req.Status = c.PostForm("status")
+
+	publishAt, unpublishAt, err := parseSchedule(c)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+	req.PublishAt = publishAt
+	req.UnpublishAt = unpublishAt
 
 	if req.ProductName == "" || req.ProductDescription == "" || req.CategoryID == "" || req.Color == "" {
 		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("invalid request: product_name, product_description, category_id or color is missing"), helper.ErrInvalidRequest)
@@ -161,15 +257,22 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	}
 
 	// Get existing product to know current size count
-	product, err := h.ProductService.GetProductByID(c, id)
+	product, err := h.ProductService.GetProductByID(c, userID, id)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
 	}
 
+	variants, err := parseVariants(c)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+	req.Variants = variants
+
 	// Parse size options
 	var sizes []CreateSizeOptionsRequest
-	for i := 0; i < len(product.Sizes); i++ {
+	for i := 0; i < len(product.Sizes) && len(variants) == 0; i++ {
 		var size CreateSizeOptionsRequest
 		size.Size = c.PostForm(fmt.Sprintf("sizes[%d][size]", i))
 		if stockStr := c.PostForm(fmt.Sprintf("sizes[%d][stock]", i)); stockStr != "" {
@@ -197,8 +300,9 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		subImages = subImagesArray
 	}
 	productFiles.SubImages = subImages
+	productFiles.VariantImages = variantImages(c)
 
-	err = h.ProductService.UpdateProduct(c.Request.Context(), id, &req, productFiles)
+	err = h.ProductService.UpdateProduct(c.Request.Context(), userID, id, &req, productFiles)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -207,11 +311,184 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
 }
 
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("file is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+	job, err := h.ProductService.ImportProducts(c.Request.Context(), userID, file, dryRun)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	if dryRun {
+		helper.SendSuccess(c, http.StatusOK, "success", job.Report)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusAccepted, "success", job)
+}
+
+func (h *ProductHandler) GetImportJob(c *gin.Context) {
+
+	id := c.Param("job_id")
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.ProductService.GetImportJob(c, userID, id)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", job)
+}
+
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+
+	contentType := "text/csv"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	// Build the file first so errors can still be sent as JSON
+	var buf bytes.Buffer
+	if err := h.ProductService.ExportProducts(c, userID, format, &buf); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	fileName := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (h *ProductHandler) GetAdminProducts(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter ProductFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	products, err := h.ProductService.GetAdminProducts(c, userID, &filter)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", products)
+}
+
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ProductService.RestoreProduct(c, userID, c.Param("id")); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ProductHandler) SetSale(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.ProductService.SetSale(c, userID, c.Param("id"), &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ProductHandler) CancelSale(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ProductService.CancelSale(c, userID, c.Param("id")); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req PriceHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	history, err := h.ProductService.GetPriceHistory(c, userID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", history)
+}
+
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 
 	id := c.Param("id")
 
-	err := h.ProductService.DeleteProduct(c, id)
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.ProductService.DeleteProduct(c, userID, id)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -219,3 +496,65 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
 }
+
+// parseVariants reads the optional "variants" form field, a JSON array of
+// variants. Images for variant i are sent as files under "variant_images[i]".
+func parseVariants(c *gin.Context) ([]CreateVariantRequest, error) {
+
+	raw := c.PostForm("variants")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var variants []CreateVariantRequest
+	if err := json.Unmarshal([]byte(raw), &variants); err != nil {
+		return nil, fmt.Errorf("invalid variants: %v", err)
+	}
+
+	return variants, nil
+}
+
+// parseSchedule reads the optional RFC 3339 "publish_at" and "unpublish_at"
+// form fields.
+func parseSchedule(c *gin.Context) (*time.Time, *time.Time, error) {
+
+	var times [2]*time.Time
+
+	for i, field := range []string{"publish_at", "unpublish_at"} {
+		raw := c.PostForm(field)
+		if raw == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %v", field, err)
+		}
+		times[i] = &t
+	}
+
+	return times[0], times[1], nil
+}
+
+// viewerID returns the signed in user, or an empty id for guests.
+func viewerID(c *gin.Context) string {
+
+	userID, _ := c.Get("user_id")
+	userIDStr, _ := userID.(string)
+
+	return userIDStr
+}
+
+func variantImages(c *gin.Context) map[int][]*multipart.FileHeader {
+
+	images := make(map[int][]*multipart.FileHeader)
+
+	for key, files := range c.Request.MultipartForm.File {
+		var i int
+		if _, err := fmt.Sscanf(key, "variant_images[%d]", &i); err == nil && len(files) > 0 {
+			images[i] = files
+		}
+	}
+
+	return images
+}
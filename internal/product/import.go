@@ -0,0 +1,818 @@
+package product
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"modular_monolith/helper"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// importColumns is the column layout shared by import and export, one row per
+// variant. Stock is only read for new variants; existing ones keep the stock
+// the inventory holds.
+var importColumns = []string{
+	"sku",
+	"product_name",
+	"product_description",
+	"category",
+	"color",
+	"size",
+	"surface",
+	"price",
+	"variant_price",
+	"discount",
+	"currency",
+	"barcode",
+	"weight",
+	"stock",
+	"main_image_url",
+	"sub_image_urls",
+	"variant_image_urls",
+	"meta_title",
+	"meta_description",
+}
+
+const (
+	maxImportFileSize = 10 << 20
+	maxImportRows     = 5000
+	imageURLSeparator = "|"
+)
+
+type importRow struct {
+	Row             int
+	SKU             string
+	ProductName     string
+	Description     string
+	Category        string
+	CategoryID      primitive.ObjectID
+	Color           string
+	Size            string
+	Surface         string
+	Price           float64
+	VariantPrice    *float64
+	Discount        float64
+	Currency        string
+	Barcode         string
+	Weight          float64
+	Stock           int
+	MainImage       string
+	SubImages       []string
+	VariantImages   []string
+	MetaTitle       string
+	MetaDescription string
+}
+
+func (r *importRow) attributes() map[string]string {
+
+	attributes := map[string]string{"color": r.Color}
+	if r.Size != "" {
+		attributes["size"] = r.Size
+	}
+	if r.Surface != "" {
+		attributes["surface"] = r.Surface
+	}
+
+	return attributes
+}
+
+// importGroup holds the rows of one product. Product is nil for a new one.
+type importGroup struct {
+	Product *Product
+	Rows    []*importRow
+}
+
+// ImportProducts validates the file and, unless dryRun is set, writes the
+// valid rows in a background job.
+func (s *productService) ImportProducts(ctx context.Context, userID string, file *multipart.FileHeader, dryRun bool) (*ImportJob, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		return nil, fmt.Errorf("file is required")
+	}
+
+	if file.Size > maxImportFileSize {
+		return nil, fmt.Errorf("file is larger than %d MB", maxImportFileSize>>20)
+	}
+
+	records, err := readImportFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, report, err := s.prepareImport(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ImportJob{
+		ID:        primitive.NewObjectID(),
+		FileName:  file.Filename,
+		Status:    ImportStatusPending,
+		Report:    *report,
+		Errors:    []ImportRowError{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if dryRun {
+		return job, nil
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("file has no valid rows")
+	}
+
+	if err := s.importJobRepository.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	go s.runImport(job, groups)
+
+	return job, nil
+}
+
+func (s *productService) GetImportJob(ctx context.Context, userID string, id string) (*ImportJob, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job id: %v", err)
+	}
+
+	job, err := s.importJobRepository.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("import job not found")
+	}
+
+	return job, nil
+}
+
+func (s *productService) runImport(job *ImportJob, groups []*importGroup) {
+
+	ctx := context.Background()
+
+	defer func() {
+		if r := recover(); r != nil {
+			now := time.Now()
+			job.FinishedAt = &now
+			job.Status = ImportStatusFailed
+			job.Errors = append(job.Errors, ImportRowError{Message: fmt.Sprintf("import stopped unexpectedly: %v", r)})
+
+			if err := s.importJobRepository.Update(ctx, job); err != nil {
+				fmt.Printf("Warning: failed to update import job %s: %v\n", job.ID.Hex(), err)
+			}
+		}
+	}()
+
+	job.Status = ImportStatusRunning
+	if err := s.importJobRepository.Update(ctx, job); err != nil {
+		fmt.Printf("Warning: failed to update import job %s: %v\n", job.ID.Hex(), err)
+	}
+
+	for i, group := range groups {
+
+		created, err := s.applyImportGroup(ctx, job, group)
+		if err != nil {
+			for _, row := range group.Rows {
+				job.Errors = append(job.Errors, ImportRowError{Row: row.Row, SKU: row.SKU, Message: err.Error()})
+			}
+		} else if created {
+			job.Created++
+		} else {
+			job.Updated++
+		}
+
+		job.Processed += len(group.Rows)
+
+		if i%20 == 19 {
+			if err := s.importJobRepository.Update(ctx, job); err != nil {
+				fmt.Printf("Warning: failed to update import job %s: %v\n", job.ID.Hex(), err)
+			}
+		}
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = ImportStatusCompleted
+	if job.Created == 0 && job.Updated == 0 {
+		job.Status = ImportStatusFailed
+	}
+
+	if err := s.importJobRepository.Update(ctx, job); err != nil {
+		fmt.Printf("Warning: failed to update import job %s: %v\n", job.ID.Hex(), err)
+	}
+}
+
+// prepareImport validates the rows and groups the valid ones by product.
+func (s *productService) prepareImport(ctx context.Context, records []map[string]string) ([]*importGroup, *ImportReport, error) {
+
+	report := &ImportReport{
+		Errors: []ImportRowError{},
+	}
+
+	categories, err := s.categoryService.GetCategories(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+
+	categoryIDs := make(map[string]primitive.ObjectID)
+	for _, c := range categories {
+		categoryIDs[helper.FoldVietnamese(strings.TrimSpace(c.CategoryName))] = c.ID
+	}
+
+	groups := make(map[string]*importGroup)
+	var order []string
+
+	// Rows for new SKUs join the product of an earlier row with the same name
+	nameKeys := make(map[string]string)
+	seenSKU := make(map[string]int)
+	seenCombo := make(map[string]int)
+
+	for i, record := range records {
+
+		if len(record) == 0 {
+			continue
+		}
+		report.TotalRows++
+
+		row, err := parseImportRow(record, i+2)
+		if err == nil {
+			row.CategoryID, err = lookupCategory(categoryIDs, row.Category)
+		}
+		if err == nil {
+			if first, ok := seenSKU[row.SKU]; ok {
+				err = fmt.Errorf("sku already used on row %d", first)
+			}
+		}
+
+		var key string
+		var existing *Product
+
+		if err == nil {
+			existing, err = s.repository.FindBySKU(ctx, row.SKU)
+		}
+
+		if err == nil {
+			nameKey := helper.FoldVietnamese(row.ProductName)
+
+			if existing != nil {
+				key = existing.ID.Hex()
+			} else if k, ok := nameKeys[nameKey]; ok {
+				key = k
+			} else {
+				key = "new:" + nameKey
+			}
+
+			if _, ok := nameKeys[nameKey]; !ok {
+				nameKeys[nameKey] = key
+			}
+
+			combo := key + "|" + attributeKey(row.attributes())
+			if first, ok := seenCombo[combo]; ok {
+				err = fmt.Errorf("same size, color and surface as row %d", first)
+			} else {
+				seenCombo[combo] = row.Row
+			}
+		}
+
+		if err != nil {
+			report.InvalidRows++
+			report.Errors = append(report.Errors, ImportRowError{Row: i + 2, SKU: record["sku"], Message: err.Error()})
+			continue
+		}
+
+		seenSKU[row.SKU] = row.Row
+		report.ValidRows++
+
+		group, ok := groups[key]
+		if !ok {
+			group = &importGroup{Product: existing}
+			groups[key] = group
+			order = append(order, key)
+
+			if existing == nil {
+				report.ProductsToCreate++
+			} else {
+				report.ProductsToUpdate++
+			}
+		}
+
+		group.Rows = append(group.Rows, row)
+	}
+
+	var result []*importGroup
+	for _, key := range order {
+		result = append(result, groups[key])
+	}
+
+	return result, report, nil
+}
+
+// applyImportGroup creates or updates one product. Product fields come from
+// the first row and variants are matched by SKU.
+func (s *productService) applyImportGroup(ctx context.Context, job *ImportJob, group *importGroup) (bool, error) {
+
+	first := group.Rows[0]
+
+	product := group.Product
+	created := product == nil
+
+	var before *priceSnapshot
+	if !created {
+		before = snapshotPrices(product)
+	}
+
+	if created {
+		product = &Product{
+			ID:        primitive.NewObjectID(),
+			Status:    ProductStatusActive,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	product.ProductName = first.ProductName
+	product.ProductDescription = first.Description
+	product.CategoryID = first.CategoryID
+	product.Color = first.Color
+	product.Price = first.Price
+	product.Discount = first.Discount
+	product.Currency = first.Currency
+	product.UpdatedAt = time.Now()
+
+	bySKU := make(map[string]*importRow)
+	for _, row := range group.Rows {
+		bySKU[row.SKU] = row
+	}
+
+	var reqVariants []CreateVariantRequest
+
+	for _, v := range product.Variants {
+		req := CreateVariantRequest{
+			ID:         v.ID.Hex(),
+			SKU:        v.SKU,
+			Attributes: v.Attributes,
+			Price:      v.Price,
+			Barcode:    v.Barcode,
+			Weight:     v.Weight,
+			Stock:      v.Stock,
+		}
+
+		if row, ok := bySKU[v.SKU]; ok {
+			req = row.variantRequest()
+			req.ID = v.ID.Hex()
+			delete(bySKU, v.SKU)
+		}
+
+		reqVariants = append(reqVariants, req)
+	}
+
+	for _, row := range group.Rows {
+		if _, ok := bySKU[row.SKU]; ok {
+			reqVariants = append(reqVariants, row.variantRequest())
+		}
+	}
+
+	variants, err := s.buildVariants(ctx, product.ID, product.Color, reqVariants, nil, product.Variants, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range variants {
+		for _, row := range group.Rows {
+			if row.SKU != variants[i].SKU || len(row.VariantImages) == 0 {
+				continue
+			}
+
+			images, err := s.importImages(ctx, variants[i].Images, row.VariantImages)
+			if err != nil {
+				return false, err
+			}
+			variants[i].Images = images
+		}
+	}
+
+	product.Variants = variants
+	product.Sizes, product.Options = summarizeVariants(variants)
+
+	if first.MainImage != "" && first.MainImage != product.MainImage {
+		url, publicID, err := s.cloudUploader.UploadImage(ctx, first.MainImage, "products")
+		if err != nil {
+			return false, fmt.Errorf("failed to upload main image: %w", err)
+		}
+
+		if product.MainImagePublicID != "" {
+			if err := s.cloudUploader.DeleteImage(ctx, product.MainImagePublicID); err != nil {
+				fmt.Printf("Warning: failed to delete old main image: %v\n", err)
+			}
+		}
+
+		product.MainImage = url
+		product.MainImagePublicID = publicID
+	}
+
+	if len(first.SubImages) > 0 {
+		subImages, err := s.importImages(ctx, product.SubImages, first.SubImages)
+		if err != nil {
+			return false, err
+		}
+		product.SubImages = subImages
+	}
+
+	if err := s.assignSlug(ctx, product, ""); err != nil {
+		return false, err
+	}
+
+	s.applySEO(product, first.MetaTitle, first.MetaDescription)
+
+	reason := PriceChangeImport
+	if created {
+		reason = PriceChangeCreated
+	}
+
+	changes := priceChanges(product, before, reason, nil)
+	product.LowestPrice30Days = s.lowestPrice30Days(ctx, product, changes)
+
+	if created {
+		err = s.repository.Create(ctx, product)
+	} else {
+		err = s.repository.UpdateByID(ctx, product.ID, product)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.savePriceChanges(ctx, changes)
+	s.syncStock(ctx, product, model.StockChange{Type: model.MovementImport, Reason: "import " + job.FileName, ReferenceID: &job.ID})
+	s.indexProduct(ctx, product)
+
+	return created, nil
+}
+
+// importImages uploads the image URLs unless they are already the current
+// images, which is the case when re-importing an export.
+func (s *productService) importImages(ctx context.Context, current []SubImage, urls []string) ([]SubImage, error) {
+
+	var currentURLs []string
+	for _, img := range current {
+		currentURLs = append(currentURLs, img.Url)
+	}
+
+	if strings.Join(currentURLs, imageURLSeparator) == strings.Join(urls, imageURLSeparator) {
+		return current, nil
+	}
+
+	var images []SubImage
+	for _, url := range urls {
+		uploaded, publicID, err := s.cloudUploader.UploadImage(ctx, url, "products")
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload image %s: %w", url, err)
+		}
+
+		images = append(images, SubImage{Url: uploaded, SubImagePublicID: publicID})
+	}
+
+	s.deleteVariantImages(ctx, current)
+
+	return images, nil
+}
+
+func (r *importRow) variantRequest() CreateVariantRequest {
+	return CreateVariantRequest{
+		SKU:        r.SKU,
+		Attributes: r.attributes(),
+		Price:      r.VariantPrice,
+		Barcode:    r.Barcode,
+		Weight:     r.Weight,
+		Stock:      r.Stock,
+	}
+}
+
+func lookupCategory(categoryIDs map[string]primitive.ObjectID, name string) (primitive.ObjectID, error) {
+
+	if id, ok := categoryIDs[helper.FoldVietnamese(name)]; ok {
+		return id, nil
+	}
+
+	return primitive.NilObjectID, fmt.Errorf("category %s not found", name)
+}
+
+func parseImportRow(record map[string]string, rowNumber int) (*importRow, error) {
+
+	row := &importRow{
+		Row:             rowNumber,
+		SKU:             strings.ToUpper(record["sku"]),
+		ProductName:     record["product_name"],
+		Description:     record["product_description"],
+		Category:        record["category"],
+		Color:           record["color"],
+		Size:            record["size"],
+		Surface:         record["surface"],
+		Currency:        record["currency"],
+		Barcode:         record["barcode"],
+		MainImage:       record["main_image_url"],
+		SubImages:       splitURLs(record["sub_image_urls"]),
+		VariantImages:   splitURLs(record["variant_image_urls"]),
+		MetaTitle:       record["meta_title"],
+		MetaDescription: record["meta_description"],
+	}
+
+	if row.SKU == "" {
+		return nil, fmt.Errorf("sku is required")
+	}
+
+	if row.ProductName == "" {
+		return nil, fmt.Errorf("product_name is required")
+	}
+
+	if row.Description == "" {
+		return nil, fmt.Errorf("product_description is required")
+	}
+
+	if row.Category == "" {
+		return nil, fmt.Errorf("category is required")
+	}
+
+	if row.Color == "" {
+		return nil, fmt.Errorf("color is required")
+	}
+
+	if row.Currency == "" {
+		row.Currency = "VND"
+	}
+
+	var err error
+
+	if row.Price, err = parseFloat(record["price"]); err != nil || row.Price <= 0 {
+		return nil, fmt.Errorf("price must be a number greater than 0")
+	}
+
+	if record["variant_price"] != "" {
+		price, err := parseFloat(record["variant_price"])
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("variant_price must be a number greater than 0")
+		}
+		row.VariantPrice = &price
+	}
+
+	if row.Discount, err = parseFloat(record["discount"]); err != nil || row.Discount < 0 {
+		return nil, fmt.Errorf("discount must be a number greater than or equal to 0")
+	}
+
+	if row.Weight, err = parseFloat(record["weight"]); err != nil || row.Weight < 0 {
+		return nil, fmt.Errorf("weight must be a number greater than or equal to 0")
+	}
+
+	if row.Stock, err = strconv.Atoi(record["stock"]); err != nil || row.Stock < 0 {
+		return nil, fmt.Errorf("stock must be a whole number greater than or equal to 0")
+	}
+
+	return row, nil
+}
+
+// parseFloat treats an empty cell as 0.
+func parseFloat(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func splitURLs(value string) []string {
+
+	var urls []string
+	for _, url := range strings.Split(value, imageURLSeparator) {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
+
+// readImportFile reads a csv or xlsx file into one map per row, keyed by the
+// lowercased header.
+func readImportFile(file *multipart.FileHeader) ([]map[string]string, error) {
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var rows [][]string
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(src)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		rows, err = reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx: %v", err)
+		}
+		defer workbook.Close()
+
+		rows, err = workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type, use .csv or .xlsx")
+	}
+
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("file has no data rows")
+	}
+
+	if len(rows)-1 > maxImportRows {
+		return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+	}
+
+	header := rows[0]
+	// Spreadsheet tools often prefix csv files with a byte order mark
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+
+	known := make(map[string]bool)
+	for _, col := range importColumns {
+		known[col] = true
+	}
+
+	for _, required := range []string{"sku", "product_name", "category", "price", "stock"} {
+		found := false
+		for _, col := range header {
+			if col == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("missing column %s", required)
+		}
+	}
+
+	var records []map[string]string
+	for _, row := range rows[1:] {
+		record := make(map[string]string)
+		empty := true
+
+		for i, col := range header {
+			if !known[col] || i >= len(row) {
+				continue
+			}
+
+			record[col] = strings.TrimSpace(row[i])
+			if record[col] != "" {
+				empty = false
+			}
+		}
+
+		// Blank rows are kept empty so row numbers still match the file
+		if empty {
+			record = map[string]string{}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ExportProducts writes the catalog in the import layout as csv or xlsx.
+// Bundles are left out as the layout has no column for their components.
+func (s *productService) ExportProducts(ctx context.Context, userID string, format string, w io.Writer) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	if format != "csv" && format != "xlsx" {
+		return fmt.Errorf("unsupported format %s, use csv or xlsx", format)
+	}
+
+	products, _, err := s.repository.FindAll(ctx, &ProductFilter{Sort: "name-asc", Admin: true})
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.categoryService.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+
+	categoryNames := make(map[primitive.ObjectID]string)
+	for _, c := range categories {
+		categoryNames[c.ID] = c.CategoryName
+	}
+
+	rows := [][]string{importColumns}
+
+	for _, p := range products {
+
+		if p.IsBundle() {
+			continue
+		}
+
+		var subImages []string
+		for _, img := range p.SubImages {
+			subImages = append(subImages, img.Url)
+		}
+
+		for _, v := range p.Variants {
+
+			var variantImages []string
+			for _, img := range v.Images {
+				variantImages = append(variantImages, img.Url)
+			}
+
+			variantPrice := ""
+			if v.Price != nil {
+				variantPrice = formatFloat(*v.Price)
+			}
+
+			color := v.Attributes["color"]
+			if color == "" {
+				color = p.Color
+			}
+
+			rows = append(rows, []string{
+				v.SKU,
+				p.ProductName,
+				p.ProductDescription,
+				categoryNames[p.CategoryID],
+				color,
+				v.Attributes["size"],
+				v.Attributes["surface"],
+				formatFloat(p.Price),
+				variantPrice,
+				formatFloat(p.Discount),
+				p.Currency,
+				v.Barcode,
+				formatFloat(v.Weight),
+				strconv.Itoa(v.Stock),
+				p.MainImage,
+				strings.Join(subImages, imageURLSeparator),
+				strings.Join(variantImages, imageURLSeparator),
+				p.SEO.MetaTitle,
+				p.SEO.MetaDescription,
+			})
+		}
+	}
+
+	if format == "csv" {
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	sheet := workbook.GetSheetName(0)
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+
+		if err := workbook.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return workbook.Write(w)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,100 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+type ImportJob struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	FileName   string             `json:"file_name" bson:"file_name"`
+	Status     string             `json:"status" bson:"status"`
+	Report     ImportReport       `json:"report" bson:"report"`
+	Processed  int                `json:"processed" bson:"processed"`
+	Created    int                `json:"created" bson:"created"`
+	Updated    int                `json:"updated" bson:"updated"`
+	Errors     []ImportRowError   `json:"errors" bson:"errors"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+	FinishedAt *time.Time         `json:"finished_at" bson:"finished_at"`
+}
+
+// ImportReport is the result of validating an import file without writing it.
+type ImportReport struct {
+	TotalRows        int              `json:"total_rows" bson:"total_rows"`
+	ValidRows        int              `json:"valid_rows" bson:"valid_rows"`
+	InvalidRows      int              `json:"invalid_rows" bson:"invalid_rows"`
+	ProductsToCreate int              `json:"products_to_create" bson:"products_to_create"`
+	ProductsToUpdate int              `json:"products_to_update" bson:"products_to_update"`
+	Errors           []ImportRowError `json:"errors" bson:"errors"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row" bson:"row"`
+	SKU     string `json:"sku" bson:"sku"`
+	Message string `json:"message" bson:"message"`
+}
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *ImportJob) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*ImportJob, error)
+	Update(ctx context.Context, job *ImportJob) error
+}
+
+type importJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewImportJobRepository(collection *mongo.Collection) ImportJobRepository {
+	return &importJobRepository{
+		collection: collection,
+	}
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job *ImportJob) error {
+
+	_, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *importJobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*ImportJob, error) {
+
+	var job *ImportJob
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r *importJobRepository) Update(ctx context.Context, job *ImportJob) error {
+
+	job.UpdatedAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": job.ID}, bson.M{"$set": job})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
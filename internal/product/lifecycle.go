@@ -0,0 +1,168 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"modular_monolith/internal/user"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resolveStatus validates the requested status and schedule. An empty status
+// keeps the current one; new products start as drafts when their publish
+// time is still ahead and as active otherwise.
+func resolveStatus(status, current string, publishAt, unpublishAt *time.Time) (string, error) {
+
+	switch status {
+	case "", ProductStatusDraft, ProductStatusActive, ProductStatusArchived:
+	default:
+		return "", fmt.Errorf("invalid status %q", status)
+	}
+
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return "", fmt.Errorf("unpublish_at must be after publish_at")
+	}
+
+	// The scheduler would publish such a draft right away
+	if status == ProductStatusDraft && publishAt != nil && !publishAt.After(time.Now()) {
+		return "", fmt.Errorf("publish_at must be in the future for a draft product")
+	}
+
+	if status == "" {
+		status = current
+	}
+
+	if status == "" {
+		status = ProductStatusActive
+		if publishAt != nil && publishAt.After(time.Now()) {
+			status = ProductStatusDraft
+		}
+	}
+
+	if status == ProductStatusActive && unpublishAt != nil && !unpublishAt.After(time.Now()) {
+		return "", fmt.Errorf("unpublish_at must be in the future for an active product")
+	}
+
+	return status, nil
+}
+
+// GetAdminProducts lists products in every status. Soft-deleted products are
+// only listed when filter.Deleted is set.
+func (s *productService) GetAdminProducts(ctx context.Context, userID string, filter *ProductFilter) (*ProductsResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	switch filter.Status {
+	case "", ProductStatusDraft, ProductStatusActive, ProductStatusArchived:
+	default:
+		return nil, fmt.Errorf("invalid status %q", filter.Status)
+	}
+
+	filter.Admin = true
+	filter.IncludeDeleted = false
+
+	return s.GetAllProducts(ctx, filter)
+}
+
+func (s *productService) RestoreProduct(ctx context.Context, userID string, id string) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	product, err := s.repository.FindByID(ctx, objectID)
+	if err != nil || product == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if product.DeletedAt == nil {
+		return fmt.Errorf("product is not deleted")
+	}
+
+	if err := s.repository.RestoreByID(ctx, objectID); err != nil {
+		return err
+	}
+
+	product.DeletedAt = nil
+	s.indexProduct(ctx, product)
+
+	return nil
+}
+
+// CronPublishSchedule publishes drafts whose publish time has come and
+// archives active products whose unpublish time has passed.
+func (s *productService) CronPublishSchedule(ctx context.Context) error {
+
+	now := time.Now()
+
+	due, err := s.repository.FindDueToPublish(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find products to publish: %w", err)
+	}
+
+	for _, product := range due {
+		// Drafts whose unpublish time also passed go straight to archived
+		status := ProductStatusActive
+		if product.UnpublishAt != nil && !product.UnpublishAt.After(now) {
+			status = ProductStatusArchived
+		}
+
+		if err := s.setStatus(ctx, product, status); err != nil {
+			log.Printf("failed to publish product %s: %v", product.ID.Hex(), err)
+		}
+	}
+
+	expired, err := s.repository.FindDueToUnpublish(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find products to unpublish: %w", err)
+	}
+
+	for _, product := range expired {
+		if err := s.setStatus(ctx, product, ProductStatusArchived); err != nil {
+			log.Printf("failed to unpublish product %s: %v", product.ID.Hex(), err)
+		}
+	}
+
+	if len(due) > 0 || len(expired) > 0 {
+		log.Printf("product schedule: %d published, %d unpublished", len(due), len(expired))
+	}
+
+	return nil
+}
+
+func (s *productService) setStatus(ctx context.Context, product *Product, status string) error {
+
+	if err := s.repository.UpdateStatus(ctx, product.ID, status); err != nil {
+		return err
+	}
+
+	product.Status = status
+	s.indexProduct(ctx, product)
+
+	return nil
+}
+
+// visibleTo hides draft, archived and deleted products from everyone but
+// staff.
+func (s *productService) visibleTo(ctx context.Context, userID string, product *Product) bool {
+
+	if product.IsVisible() {
+		return true
+	}
+
+	if userID == "" {
+		return false
+	}
+
+	_, err := user.RequireStaff(ctx, s.userRepository, userID)
+	return err == nil
+}
@@ -1,6 +1,7 @@
 package product
 
 import (
+	"modular_monolith/internal/shared/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,9 +11,13 @@ type Product struct {
 	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	CategoryID         primitive.ObjectID `json:"category_id" bson:"category_id"`
 	ProductName        string             `json:"product_name" bson:"product_name"`
+	Slug               string             `json:"slug" bson:"slug"`
+	SlugHistory        []string           `json:"slug_history" bson:"slug_history"`
+	SEO                model.SEO          `json:"seo" bson:"seo"`
 	ProductDescription string             `json:"product_description" bson:"product_description"`
 	RatingAverage      float64            `json:"rating_average" bson:"rating_average"`
 	ReviewsCount       int                `json:"reviews_count" bson:"reviews_count"`
+	RatingHistogram    map[string]int     `json:"rating_histogram" bson:"rating_histogram"`
 	Color              string             `json:"color" bson:"color"`
 	MainImagePublicID  string             `json:"main_image_public_id" bson:"main_image_public_id"`
 	MainImage          string             `json:"main_image" bson:"main_image"`
@@ -20,11 +25,48 @@ type Product struct {
 	Price              float64            `json:"price" bson:"price"`
 	Discount           float64            `json:"discount" bson:"discount"`
 	Currency           string             `json:"currency" bson:"currency"`
+	Sale               *Sale              `json:"sale" bson:"sale"`
+	LowestPrice30Days  float64            `json:"lowest_price_30_days" bson:"lowest_price_30_days"`
 	Sizes              []SizeOptions      `json:"sizes" bson:"sizes"`
+	Options            []ProductOption    `json:"options" bson:"options"`
+	Variants           []Variant          `json:"variants" bson:"variants"`
+	Status             string             `json:"status" bson:"status"`
+	PublishAt          *time.Time         `json:"publish_at" bson:"publish_at"`
+	UnpublishAt        *time.Time         `json:"unpublish_at" bson:"unpublish_at"`
+	DeletedAt          *time.Time         `json:"deleted_at" bson:"deleted_at"`
 	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
+const (
+	ProductStatusDraft    = "draft"
+	ProductStatusActive   = "active"
+	ProductStatusArchived = "archived"
+)
+
+// IsVisible reports whether shoppers can see and buy the product. Products
+// saved before statuses existed have no status and count as active.
+func (p *Product) IsVisible() bool {
+	return p.DeletedAt == nil && (p.Status == "" || p.Status == ProductStatusActive)
+}
+
+// Sale is a scheduled sale price. It is only charged once the scheduler has
+// marked it active and is removed when it ends.
+type Sale struct {
+	Price   float64   `json:"price" bson:"price"`
+	StartAt time.Time `json:"start_at" bson:"start_at"`
+	EndAt   time.Time `json:"end_at" bson:"end_at"`
+	Active  bool      `json:"active" bson:"active"`
+}
+
+// CurrentPrice returns the sale price while a sale runs, else the list price.
+func (p *Product) CurrentPrice() float64 {
+	if p.Sale != nil && p.Sale.Active {
+		return p.Sale.Price
+	}
+	return p.Price
+}
+
 type SizeOptions struct {
 	Size  string `json:"size" bson:"size"`
 	Stock int    `json:"stock" bson:"stock"`
@@ -34,3 +76,50 @@ type SubImage struct {
 	Url              string `json:"url" bson:"url"`
 	SubImagePublicID string `json:"sub_image_public_id" bson:"sub_image_public_id"`
 }
+
+// ProductOption is one axis products vary on, e.g. size, color or surface.
+type ProductOption struct {
+	Name   string   `json:"name" bson:"name"`
+	Values []string `json:"values" bson:"values"`
+}
+
+// Variant is a purchasable combination of option values. Product.Sizes is a
+// per-size summary derived from the variants.
+type Variant struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	SKU        string             `json:"sku" bson:"sku"`
+	Attributes map[string]string  `json:"attributes" bson:"attributes"`
+	Price      *float64           `json:"price" bson:"price"`
+	Barcode    string             `json:"barcode" bson:"barcode"`
+	Weight     float64            `json:"weight" bson:"weight"`
+	Stock      int                `json:"stock" bson:"stock"`
+	Images     []SubImage         `json:"images" bson:"images"`
+	Components []BundleComponent  `json:"components,omitempty" bson:"components,omitempty"`
+}
+
+func (p *Product) FindVariant(id primitive.ObjectID) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+func (p *Product) FindVariantBySize(size string) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].Attributes["size"] == size {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// VariantPrice returns the variant price override or the current product
+// price. Sales do not apply to variants with their own price.
+func (p *Product) VariantPrice(v *Variant) float64 {
+	if v != nil && v.Price != nil {
+		return *v.Price
+	}
+	return p.CurrentPrice()
+}
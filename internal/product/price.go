@@ -0,0 +1,344 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"modular_monolith/internal/user"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lowestPriceWindow is how far back the lowest price shown on a sale looks.
+const lowestPriceWindow = 30 * 24 * time.Hour
+
+// priceSnapshot holds the prices of a product before an edit.
+type priceSnapshot struct {
+	Price    float64
+	Discount float64
+	Variants map[primitive.ObjectID]*float64
+}
+
+func snapshotPrices(product *Product) *priceSnapshot {
+
+	snapshot := &priceSnapshot{
+		Price:    product.CurrentPrice(),
+		Discount: product.Discount,
+		Variants: make(map[primitive.ObjectID]*float64),
+	}
+
+	for _, v := range product.Variants {
+		snapshot.Variants[v.ID] = v.Price
+	}
+
+	return snapshot
+}
+
+// priceChanges lists what changed between the snapshot and the product. A nil
+// snapshot means the product is new and records its starting price.
+func priceChanges(product *Product, before *priceSnapshot, reason string, changedBy *primitive.ObjectID) []*PriceChange {
+
+	now := time.Now()
+
+	change := func(variantID *primitive.ObjectID, sku string, oldPrice, newPrice, oldDiscount float64) *PriceChange {
+		return &PriceChange{
+			ID:          primitive.NewObjectID(),
+			ProductID:   product.ID,
+			VariantID:   variantID,
+			SKU:         sku,
+			OldPrice:    oldPrice,
+			NewPrice:    newPrice,
+			OldDiscount: oldDiscount,
+			NewDiscount: product.Discount,
+			Reason:      reason,
+			ChangedBy:   changedBy,
+			ChangedAt:   now,
+		}
+	}
+
+	if before == nil {
+		price := product.CurrentPrice()
+		return []*PriceChange{change(nil, "", price, price, product.Discount)}
+	}
+
+	var changes []*PriceChange
+
+	if before.Price != product.CurrentPrice() || before.Discount != product.Discount {
+		changes = append(changes, change(nil, "", before.Price, product.CurrentPrice(), before.Discount))
+	}
+
+	for _, v := range product.Variants {
+		old, existed := before.Variants[v.ID]
+		if !existed || (old == nil && v.Price == nil) {
+			continue
+		}
+
+		oldPrice := before.Price
+		if old != nil {
+			oldPrice = *old
+		}
+
+		if newPrice := product.VariantPrice(&v); newPrice != oldPrice {
+			variantID := v.ID
+			changes = append(changes, change(&variantID, v.SKU, oldPrice, newPrice, before.Discount))
+		}
+	}
+
+	return changes
+}
+
+// lowestPrice30Days returns the lowest price charged in the 30 days before the
+// current reduction, leaving out the new prices about to be saved. A running
+// sale keeps the reference computed when it started.
+func (s *productService) lowestPrice30Days(ctx context.Context, product *Product, pending []*PriceChange) float64 {
+
+	var reductions []*PriceChange
+	for _, change := range pending {
+		if change.VariantID == nil {
+			reductions = append(reductions, change)
+		}
+	}
+
+	if len(reductions) == 0 && product.Sale != nil && product.Sale.Active && product.LowestPrice30Days > 0 {
+		return product.LowestPrice30Days
+	}
+
+	history, err := s.priceHistoryRepository.FindByProductID(ctx, product.ID, time.Now().Add(-lowestPriceWindow))
+	if err != nil {
+		log.Printf("failed to load price history of product %s: %v", product.ID.Hex(), err)
+	}
+
+	var prices []float64
+	if len(reductions) == 0 {
+		prices = append(prices, product.CurrentPrice())
+	}
+
+	for _, change := range history {
+		if change.VariantID == nil {
+			prices = append(prices, change.OldPrice, change.NewPrice)
+		}
+	}
+
+	for _, change := range reductions {
+		prices = append(prices, change.OldPrice)
+	}
+
+	lowest := 0.0
+	for _, price := range prices {
+		if price > 0 && (lowest == 0 || price < lowest) {
+			lowest = price
+		}
+	}
+
+	if lowest == 0 {
+		return product.CurrentPrice()
+	}
+
+	return lowest
+}
+
+func (s *productService) savePriceChanges(ctx context.Context, changes []*PriceChange) {
+	for _, change := range changes {
+		if err := s.priceHistoryRepository.Create(ctx, change); err != nil {
+			log.Printf("failed to record price change: %v", err)
+		}
+	}
+}
+
+func (s *productService) SetSale(ctx context.Context, userID string, id string, req *SaleRequest) error {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	product, err := s.repository.FindByID(ctx, objectID)
+	if err != nil || product == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if req.Price <= 0 {
+		return fmt.Errorf("sale price must be greater than 0")
+	}
+
+	if req.Price >= product.Price {
+		return fmt.Errorf("sale price must be lower than the price %.2f", product.Price)
+	}
+
+	if req.StartAt.IsZero() || req.EndAt.IsZero() {
+		return fmt.Errorf("start_at and end_at are required")
+	}
+
+	if !req.EndAt.After(req.StartAt) {
+		return fmt.Errorf("end_at must be after start_at")
+	}
+
+	now := time.Now()
+
+	if !req.EndAt.After(now) {
+		return fmt.Errorf("end_at must be in the future")
+	}
+
+	sale := &Sale{
+		Price:   req.Price,
+		StartAt: req.StartAt,
+		EndAt:   req.EndAt,
+		Active:  !req.StartAt.After(now),
+	}
+
+	return s.applySale(ctx, product, sale, &staff.ID)
+}
+
+func (s *productService) CancelSale(ctx context.Context, userID string, id string) error {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	product, err := s.repository.FindByID(ctx, objectID)
+	if err != nil || product == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if product.Sale == nil {
+		return fmt.Errorf("product has no sale")
+	}
+
+	return s.applySale(ctx, product, nil, &staff.ID)
+}
+
+// applySale replaces the sale of the product and records the price change.
+func (s *productService) applySale(ctx context.Context, product *Product, sale *Sale, changedBy *primitive.ObjectID) error {
+
+	before := snapshotPrices(product)
+	product.Sale = sale
+
+	reason := PriceChangeSaleEnd
+	if sale != nil && sale.Active {
+		reason = PriceChangeSaleStart
+	}
+
+	changes := priceChanges(product, before, reason, changedBy)
+	product.LowestPrice30Days = s.lowestPrice30Days(ctx, product, changes)
+
+	if err := s.repository.UpdateSale(ctx, product.ID, sale, product.LowestPrice30Days); err != nil {
+		return err
+	}
+
+	s.savePriceChanges(ctx, changes)
+	s.indexProduct(ctx, product)
+
+	return nil
+}
+
+func (s *productService) GetPriceHistory(ctx context.Context, userID string, id string, req *PriceHistoryRequest) (*PriceHistoryResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	product, err := s.repository.FindByID(ctx, objectID)
+	if err != nil || product == nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if req.Days <= 0 {
+		req.Days = 90
+	} else if req.Days > 365 {
+		req.Days = 365
+	}
+
+	changes, err := s.priceHistoryRepository.FindByProductID(ctx, objectID, time.Now().AddDate(0, 0, -req.Days))
+	if err != nil {
+		return nil, err
+	}
+
+	if changes == nil {
+		changes = []*PriceChange{}
+	}
+
+	result := &PriceHistoryResponse{
+		ProductID:         product.ID,
+		Currency:          product.Currency,
+		CurrentPrice:      product.CurrentPrice(),
+		LowestPrice30Days: s.lowestPrice30Days(ctx, product, nil),
+		Sale:              product.Sale,
+		Changes:           changes,
+	}
+
+	return result, nil
+}
+
+// CronPriceSchedule starts scheduled sales whose start time has come and ends
+// sales whose end time has passed.
+func (s *productService) CronPriceSchedule(ctx context.Context) error {
+
+	now := time.Now()
+
+	starting, err := s.repository.FindSalesToStart(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find sales to start: %w", err)
+	}
+
+	for _, product := range starting {
+		sale := *product.Sale
+		sale.Active = true
+
+		if err := s.applySale(ctx, product, &sale, nil); err != nil {
+			log.Printf("failed to start sale of product %s: %v", product.ID.Hex(), err)
+		}
+	}
+
+	ending, err := s.repository.FindSalesToEnd(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find sales to end: %w", err)
+	}
+
+	for _, product := range ending {
+		if err := s.applySale(ctx, product, nil, nil); err != nil {
+			log.Printf("failed to end sale of product %s: %v", product.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// RefreshLowestPrices recomputes the 30 day lowest price of every product as
+// old prices fall out of the window.
+func (s *productService) RefreshLowestPrices(ctx context.Context) error {
+
+	products, _, err := s.repository.FindAll(ctx, &ProductFilter{Admin: true})
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		lowest := s.lowestPrice30Days(ctx, product, nil)
+		if lowest == product.LowestPrice30Days {
+			continue
+		}
+
+		if err := s.repository.UpdateLowestPrice(ctx, product.ID, lowest); err != nil {
+			return fmt.Errorf("failed to update lowest price of product %s: %w", product.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
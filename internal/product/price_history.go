@@ -0,0 +1,83 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	PriceChangeCreated   = "created"
+	PriceChangeManual    = "manual"
+	PriceChangeImport    = "import"
+	PriceChangeSaleStart = "sale_start"
+	PriceChangeSaleEnd   = "sale_end"
+)
+
+// PriceChange records one change of the price shoppers pay. Changes without
+// a VariantID are for the product price, the others for a variant override.
+type PriceChange struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	ProductID   primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID   *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id"`
+	SKU         string              `json:"sku,omitempty" bson:"sku,omitempty"`
+	OldPrice    float64             `json:"old_price" bson:"old_price"`
+	NewPrice    float64             `json:"new_price" bson:"new_price"`
+	OldDiscount float64             `json:"old_discount" bson:"old_discount"`
+	NewDiscount float64             `json:"new_discount" bson:"new_discount"`
+	Reason      string              `json:"reason" bson:"reason"`
+	ChangedBy   *primitive.ObjectID `json:"changed_by" bson:"changed_by"`
+	ChangedAt   time.Time           `json:"changed_at" bson:"changed_at"`
+}
+
+type PriceHistoryRepository interface {
+	Create(ctx context.Context, change *PriceChange) error
+	FindByProductID(ctx context.Context, productID primitive.ObjectID, since time.Time) ([]*PriceChange, error)
+}
+
+type priceHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPriceHistoryRepository(collection *mongo.Collection) PriceHistoryRepository {
+	return &priceHistoryRepository{
+		collection: collection,
+	}
+}
+
+func (r *priceHistoryRepository) Create(ctx context.Context, change *PriceChange) error {
+
+	_, err := r.collection.InsertOne(ctx, change)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByProductID returns the changes made since the given time, oldest first.
+func (r *priceHistoryRepository) FindByProductID(ctx context.Context, productID primitive.ObjectID, since time.Time) ([]*PriceChange, error) {
+
+	filter := bson.M{
+		"product_id": productID,
+		"changed_at": bson.M{"$gte": since},
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "changed_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*PriceChange
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -3,6 +3,10 @@ package product
 import (
 	"context"
 	"fmt"
+	"modular_monolith/helper"
+	"regexp"
+	"strconv"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,11 +16,33 @@ import (
 
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
-	FindAll(ctx context.Context, filter *ProductFilter) ([]*Product, error)
+	FindAll(ctx context.Context, filter *ProductFilter) ([]*Product, int64, error)
+	FindFacets(ctx context.Context, filter *ProductFilter) (*ProductFacets, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*Product, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*Product, error)
+	FindBySlug(ctx context.Context, slug string) (*Product, error)
+	ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
+	EnsureSlugIndex(ctx context.Context) error
 	UpdateByID(ctx context.Context, id primitive.ObjectID, product *Product) error
-	UpdateQuantityByID(ctx context.Context, id primitive.ObjectID, size string, quantity int) error
+	AdjustVariantStock(ctx context.Context, id primitive.ObjectID, variantID primitive.ObjectID, size string, quantity int) error
+	SetVariantStock(ctx context.Context, id primitive.ObjectID, variants []Variant, sizes []SizeOptions) error
+	FindBundlesByComponent(ctx context.Context, variantID primitive.ObjectID) ([]*Product, error)
+	ExistsSKU(ctx context.Context, sku string, excludeID primitive.ObjectID) (bool, error)
+	FindBySKU(ctx context.Context, sku string) (*Product, error)
+	FindWithoutVariants(ctx context.Context) ([]*Product, error)
+	UpdateRatingStats(ctx context.Context, id primitive.ObjectID, average float64, count int, histogram map[string]int) error
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
+	SoftDeleteByID(ctx context.Context, id primitive.ObjectID, deletedAt time.Time) error
+	RestoreByID(ctx context.Context, id primitive.ObjectID) error
+	FindDueToPublish(ctx context.Context, now time.Time) ([]*Product, error)
+	FindDueToUnpublish(ctx context.Context, now time.Time) ([]*Product, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	UpdateSale(ctx context.Context, id primitive.ObjectID, sale *Sale, lowestPrice float64) error
+	UpdateLowestPrice(ctx context.Context, id primitive.ObjectID, lowestPrice float64) error
+	FindSalesToStart(ctx context.Context, now time.Time) ([]*Product, error)
+	FindSalesToEnd(ctx context.Context, now time.Time) ([]*Product, error)
+	CountByCategoryIDs(ctx context.Context, categoryIDs []primitive.ObjectID) (int64, error)
+	MoveCategory(ctx context.Context, fromIDs []primitive.ObjectID, to primitive.ObjectID) error
 }
 
 type productRepository struct {
@@ -39,15 +65,47 @@ func (r *productRepository) Create(ctx context.Context, product *Product) error
 	return nil
 }
 
-func (r *productRepository) FindAll(ctx context.Context, filter *ProductFilter) ([]*Product, error) {
+// priceBuckets are the lower bounds of the price facet, in VND.
+var priceBuckets = []float64{0, 200000, 500000, 1000000, 2000000, 5000000}
+
+// visibleQuery matches products shoppers can see: active, or saved before
+// statuses existed, and not soft-deleted.
+func visibleQuery() bson.M {
+	return bson.M{
+		"status":     bson.M{"$in": bson.A{ProductStatusActive, nil}},
+		"deleted_at": nil,
+	}
+}
+
+func buildProductQuery(filter *ProductFilter) bson.M {
 
 	query := bson.M{}
 
+	if !filter.Admin {
+		query = visibleQuery()
+	} else {
+		switch filter.Status {
+		case "":
+		case ProductStatusActive:
+			query["status"] = bson.M{"$in": bson.A{ProductStatusActive, nil}}
+		default:
+			query["status"] = filter.Status
+		}
+
+		if filter.Deleted {
+			query["deleted_at"] = bson.M{"$ne": nil}
+		} else if !filter.IncludeDeleted {
+			query["deleted_at"] = nil
+		}
+	}
+
 	if filter.Name != "" {
-		query["product_name"] = bson.M{"$regex": filter.Name, "$options": "i"}
+		query["product_name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
 	}
 
-	if filter.CategoryID != "" {
+	if len(filter.CategoryIDs) > 0 {
+		query["category_id"] = bson.M{"$in": filter.CategoryIDs}
+	} else if filter.CategoryID != "" {
 		objID, err := primitive.ObjectIDFromHex(filter.CategoryID)
 		if err == nil {
 			query["category_id"] = objID
@@ -65,40 +123,172 @@ func (r *productRepository) FindAll(ctx context.Context, filter *ProductFilter)
 		query["price"] = priceQuery
 	}
 
+	if filter.Color != "" {
+		color := exactMatch(filter.Color)
+		query["$or"] = bson.A{
+			bson.M{"color": color},
+			bson.M{"variants.attributes.color": color},
+		}
+	}
+
+	// Size, surface and stock must hold for the same variant
+	variant := bson.M{}
 	if filter.Size != "" {
-		query["sizes.size"] = filter.Size
+		variant["attributes.size"] = filter.Size
 	}
 	if filter.Surface != "" {
-		query["surface"] = filter.Surface
+		variant["attributes.surface"] = exactMatch(filter.Surface)
+	}
+	if filter.InStock {
+		variant["stock"] = bson.M{"$gt": 0}
+	}
+	if len(variant) > 0 {
+		query["variants"] = bson.M{"$elemMatch": variant}
+	}
+
+	if filter.Rating > 0 {
+		query["rating_average"] = bson.M{"$gte": filter.Rating}
 	}
 
-	sortQuery := bson.M{}
+	return query
+}
+
+func exactMatch(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
+
+// FindAll returns one page of products matching the filter and the total
+// number of matches. A zero Limit returns every match.
+func (r *productRepository) FindAll(ctx context.Context, filter *ProductFilter) ([]*Product, int64, error) {
+
+	query := buildProductQuery(filter)
+
+	sortQuery := bson.D{}
 
 	switch filter.Sort {
 	case "price-asc":
-		sortQuery["price"] = 1
+		sortQuery = bson.D{{Key: "price", Value: 1}}
 	case "price-desc":
-		sortQuery["price"] = -1
+		sortQuery = bson.D{{Key: "price", Value: -1}}
 	case "name-asc":
-		sortQuery["product_name"] = 1
+		sortQuery = bson.D{{Key: "product_name", Value: 1}}
 	case "name-desc":
-		sortQuery["product_name"] = -1
+		sortQuery = bson.D{{Key: "product_name", Value: -1}}
+	case "rating-desc":
+		sortQuery = bson.D{{Key: "rating_average", Value: -1}, {Key: "reviews_count", Value: -1}}
+	case "rating-asc":
+		sortQuery = bson.D{{Key: "rating_average", Value: 1}, {Key: "reviews_count", Value: -1}}
+	case "reviews-desc":
+		sortQuery = bson.D{{Key: "reviews_count", Value: -1}}
+	}
+
+	// Tie-break on _id so pages are stable
+	sortQuery = append(sortQuery, bson.E{Key: "_id", Value: -1})
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	opts := options.Find().SetSort(sortQuery)
+	if filter.Limit > 0 {
+		opts.SetSkip(int64((filter.Page - 1) * filter.Limit))
+		opts.SetLimit(int64(filter.Limit))
+	}
 
 	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var products []*Product
 	if err := cursor.All(ctx, &products); err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+
+}
+
+// FindFacets counts the products matching the filter per category, size,
+// price bucket and rating.
+func (r *productRepository) FindFacets(ctx context.Context, filter *ProductFilter) (*ProductFacets, error) {
+
+	countStage := bson.D{{Key: "$project", Value: bson.M{"_id": 0, "value": bson.M{"$toString": "$_id"}, "count": 1}}}
+	sortStage := bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "value", Value: 1}}}}
+
+	boundaries := bson.A{}
+	for _, b := range priceBuckets {
+		boundaries = append(boundaries, b)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildProductQuery(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"categories": bson.A{
+				bson.M{"$group": bson.M{"_id": "$category_id", "count": bson.M{"$sum": 1}}},
+				countStage,
+				sortStage,
+			},
+			"sizes": bson.A{
+				bson.M{"$unwind": "$sizes"},
+				bson.M{"$group": bson.M{"_id": "$sizes.size", "products": bson.M{"$addToSet": "$_id"}}},
+				bson.M{"$project": bson.M{"count": bson.M{"$size": "$products"}}},
+				countStage,
+				sortStage,
+			},
+			"prices": bson.A{
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price",
+					"boundaries": boundaries,
+					"default":    priceBuckets[len(priceBuckets)-1],
+					"output":     bson.M{"count": bson.M{"$sum": 1}},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+				countStage,
+			},
+			"ratings": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$floor": bson.M{"$ifNull": bson.A{"$rating_average", 0}}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": -1}},
+				countStage,
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
 		return nil, err
 	}
 
-	return products, nil
+	var results []*ProductFacets
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &ProductFacets{}, nil
+	}
+
+	facets := results[0]
+
+	for i, bucket := range facets.Prices {
+		for j, lower := range priceBuckets {
+			if bucket.Value != strconv.FormatFloat(lower, 'f', -1, 64) {
+				continue
+			}
+			if j+1 < len(priceBuckets) {
+				facets.Prices[i].Value = fmt.Sprintf("%s-%s", bucket.Value, strconv.FormatFloat(priceBuckets[j+1], 'f', -1, 64))
+			} else {
+				facets.Prices[i].Value = bucket.Value + "+"
+			}
+			break
+		}
+	}
 
+	return facets, nil
 }
 
 func (r *productRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*Product, error) {
@@ -115,6 +305,71 @@ func (r *productRepository) FindByID(ctx context.Context, id primitive.ObjectID)
 	return product, nil
 }
 
+func (r *productRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*Product, error) {
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// FindBySlug matches the current slug or one the product used before.
+func (r *productRepository) FindBySlug(ctx context.Context, slug string) (*Product, error) {
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"slug_history": slug},
+	}}
+
+	var product *Product
+
+	err := r.collection.FindOne(ctx, filter).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// ExistsSlug reports whether another product uses the slug, now or in its
+// history, so old links never point at a different product.
+func (r *productRepository) ExistsSlug(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+
+	filter := bson.M{
+		"_id": bson.M{"$ne": excludeID},
+		"$or": bson.A{
+			bson.M{"slug": slug},
+			bson.M{"slug_history": slug},
+		},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *productRepository) EnsureSlugIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, helper.SlugIndex)
+	return err
+}
+
 func (r *productRepository) UpdateByID(ctx context.Context, id primitive.ObjectID, product *Product) error {
 
 	filter := bson.M{"_id": id}
@@ -129,41 +384,283 @@ func (r *productRepository) UpdateByID(ctx context.Context, id primitive.ObjectI
 
 }
 
-func (r *productRepository) UpdateQuantityByID(ctx context.Context, id primitive.ObjectID, size string, quantity int) error {
+// AdjustVariantStock mirrors a stock change already checked by the inventory,
+// so it never refuses the change.
+func (r *productRepository) AdjustVariantStock(ctx context.Context, id primitive.ObjectID, variantID primitive.ObjectID, size string, quantity int) error {
+
+	inc := bson.M{"variants.$[v].stock": quantity}
+	arrayFilters := []interface{}{bson.M{"v.id": variantID}}
+
+	if size != "" {
+		inc["sizes.$[s].stock"] = quantity
+		arrayFilters = append(arrayFilters, bson.M{"s.size": size})
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "variants.id": variantID}, bson.M{"$inc": inc}, opts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetVariantStock saves the stock of the variants and the per-size summary.
+func (r *productRepository) SetVariantStock(ctx context.Context, id primitive.ObjectID, variants []Variant, sizes []SizeOptions) error {
+
+	set := bson.M{"sizes": sizes}
+	var arrayFilters []interface{}
+
+	for i, v := range variants {
+		set[fmt.Sprintf("variants.$[v%d].stock", i)] = v.Stock
+		arrayFilters = append(arrayFilters, bson.M{fmt.Sprintf("v%d.id", i): v.ID})
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts)
+	return err
+}
+
+// FindBundlesByComponent returns the bundles that contain the variant.
+func (r *productRepository) FindBundlesByComponent(ctx context.Context, variantID primitive.ObjectID) ([]*Product, error) {
+
+	cursor, err := r.collection.Find(ctx, bson.M{"variants.components.variant_id": variantID})
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) ExistsSKU(ctx context.Context, sku string, excludeID primitive.ObjectID) (bool, error) {
 
 	filter := bson.M{
-		"_id":        id,
-		"sizes.size": size,
-		"sizes.stock": bson.M{
-			"$gte": -quantity,
-		},
+		"variants.sku": sku,
+		"_id":          bson.M{"$ne": excludeID},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *productRepository) FindBySKU(ctx context.Context, sku string) (*Product, error) {
+
+	var product *Product
+
+	err := r.collection.FindOne(ctx, bson.M{"variants.sku": sku}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (r *productRepository) FindWithoutVariants(ctx context.Context) ([]*Product, error) {
+
+	filter := bson.M{"variants.0": bson.M{"$exists": false}}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	update := bson.M{
-		"$inc": bson.M{"sizes.$.stock": quantity},
+	var products []*Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
 	}
 
-	res, err := r.collection.UpdateOne(ctx, filter, update)
+	return products, nil
+}
+
+func (r *productRepository) UpdateRatingStats(ctx context.Context, id primitive.ObjectID, average float64, count int, histogram map[string]int) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"rating_average":   average,
+		"reviews_count":    count,
+		"rating_histogram": histogram,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+func (r *productRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+
+	filter := bson.M{"_id": id}
+
+	_, err := r.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
 
-	if res.ModifiedCount == 0 {
-		return fmt.Errorf("not enough stock for size %s", size)
+	return nil
+
+}
+
+func (r *productRepository) SoftDeleteByID(ctx context.Context, id primitive.ObjectID, deletedAt time.Time) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"deleted_at": deletedAt,
+		"updated_at": deletedAt,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (r *productRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+func (r *productRepository) RestoreByID(ctx context.Context, id primitive.ObjectID) error {
 
 	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"deleted_at": nil,
+		"updated_at": time.Now(),
+	}}
 
-	_, err := r.collection.DeleteOne(ctx, filter)
+	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
 	return nil
+}
+
+// FindDueToPublish returns drafts whose publish time has passed.
+func (r *productRepository) FindDueToPublish(ctx context.Context, now time.Time) ([]*Product, error) {
+
+	filter := bson.M{
+		"status":     ProductStatusDraft,
+		"publish_at": bson.M{"$lte": now},
+		"deleted_at": nil,
+	}
+
+	return r.findMany(ctx, filter)
+}
+
+// FindDueToUnpublish returns active products whose unpublish time has passed.
+func (r *productRepository) FindDueToUnpublish(ctx context.Context, now time.Time) ([]*Product, error) {
+
+	filter := bson.M{
+		"status":       bson.M{"$in": bson.A{ProductStatusActive, nil}},
+		"unpublish_at": bson.M{"$lte": now},
+		"deleted_at":   nil,
+	}
+
+	return r.findMany(ctx, filter)
+}
+
+func (r *productRepository) findMany(ctx context.Context, filter bson.M) ([]*Product, error) {
 
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"updated_at": time.Now(),
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSale only touches the sale fields so it cannot race with stock updates.
+func (r *productRepository) UpdateSale(ctx context.Context, id primitive.ObjectID, sale *Sale, lowestPrice float64) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"sale":                 sale,
+		"lowest_price_30_days": lowestPrice,
+		"updated_at":           time.Now(),
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *productRepository) UpdateLowestPrice(ctx context.Context, id primitive.ObjectID, lowestPrice float64) error {
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"lowest_price_30_days": lowestPrice}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindSalesToStart returns products whose scheduled sale has begun but is not
+// active yet.
+func (r *productRepository) FindSalesToStart(ctx context.Context, now time.Time) ([]*Product, error) {
+
+	filter := bson.M{
+		"sale.active":   false,
+		"sale.start_at": bson.M{"$lte": now},
+		"sale.end_at":   bson.M{"$gt": now},
+	}
+
+	return r.findMany(ctx, filter)
+}
+
+func (r *productRepository) FindSalesToEnd(ctx context.Context, now time.Time) ([]*Product, error) {
+	return r.findMany(ctx, bson.M{"sale.end_at": bson.M{"$lte": now}})
+}
+
+func (r *productRepository) CountByCategoryIDs(ctx context.Context, categoryIDs []primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"category_id": bson.M{"$in": categoryIDs}})
+}
+
+func (r *productRepository) MoveCategory(ctx context.Context, fromIDs []primitive.ObjectID, to primitive.ObjectID) error {
+
+	filter := bson.M{"category_id": bson.M{"$in": fromIDs}}
+	update := bson.M{"$set": bson.M{"category_id": to}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,6 +1,11 @@
 package product
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type CreateProductRequest struct {
 	ProductName        string                     `json:"product_name"`
@@ -11,11 +16,37 @@ type CreateProductRequest struct {
 	Discount           float64                    `json:"discount"`
 	Currency           string                     `json:"currency"`
 	Sizes              []CreateSizeOptionsRequest `json:"sizes"`
+	Variants           []CreateVariantRequest     `json:"variants"`
+	Slug               string                     `json:"slug"`
+	MetaTitle          string                     `json:"meta_title"`
+	MetaDescription    string                     `json:"meta_description"`
+	Status             string                     `json:"status"`
+	PublishAt          *time.Time                 `json:"publish_at"`
+	UnpublishAt        *time.Time                 `json:"unpublish_at"`
 }
 
 type ProductFiles struct {
-	MainImage *multipart.FileHeader   `json:"main_image"`
-	SubImages []*multipart.FileHeader `json:"sub_images"`
+	MainImage     *multipart.FileHeader   `json:"main_image"`
+	SubImages     []*multipart.FileHeader `json:"sub_images"`
+	VariantImages map[int][]*multipart.FileHeader
+}
+
+type CreateVariantRequest struct {
+	ID         string            `json:"id"`
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	Price      *float64          `json:"price"`
+	Barcode    string            `json:"barcode"`
+	Weight     float64           `json:"weight"`
+	Stock      int               `json:"stock"`
+	// Components make the variant a bundle; its stock is then ignored
+	Components []BundleComponentRequest `json:"components"`
+}
+
+type BundleComponentRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
 }
 
 type CreateSizeOptionsRequest struct {
@@ -32,6 +63,29 @@ type UpdateProductRequest struct {
 	Discount           float64                    `json:"discount"`
 	Currency           string                     `json:"currency"`
 	Sizes              []CreateSizeOptionsRequest `json:"sizes" bson:"sizes"`
+	Variants           []CreateVariantRequest     `json:"variants" bson:"variants"`
+	Slug               string                     `json:"slug" bson:"slug"`
+	MetaTitle          string                     `json:"meta_title" bson:"meta_title"`
+	MetaDescription    string                     `json:"meta_description" bson:"meta_description"`
+	Status             string                     `json:"status" bson:"status"`
+	PublishAt          *time.Time                 `json:"publish_at" bson:"publish_at"`
+	UnpublishAt        *time.Time                 `json:"unpublish_at" bson:"unpublish_at"`
+}
+
+type SaleRequest struct {
+	Price   float64   `json:"price"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type PriceHistoryRequest struct {
+	Days int `form:"days"`
+}
+
+type SearchRequest struct {
+	Query string `form:"q"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
 }
 
 type ProductFilter struct {
@@ -41,6 +95,19 @@ type ProductFilter struct {
     CategoryID string  `form:"category_id"`
     Size       string  `form:"size"`
     Surface    string  `form:"surface"`
+    Color      string  `form:"color"`
+    InStock    bool    `form:"in_stock"`
     Rating     float64 `form:"rating"`
     Sort       string  `form:"sort"`
+    Page       int     `form:"page"`
+    Limit      int     `form:"limit"`
+    // Status and Deleted are only honoured on the admin listing
+    Status     string  `form:"status"`
+    Deleted    bool    `form:"deleted"`
+    // CategoryIDs holds CategoryID and its descendants, resolved by the service
+    CategoryIDs []primitive.ObjectID `form:"-"`
+    // Admin lists products in every status instead of only visible ones
+    Admin bool `form:"-"`
+    // IncludeDeleted adds soft-deleted products to an admin listing
+    IncludeDeleted bool `form:"-"`
 }
\ No newline at end of file
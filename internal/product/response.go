@@ -1,6 +1,7 @@
 package product
 
 import (
+	"modular_monolith/internal/shared/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,9 +10,12 @@ type ProductResponse struct {
 	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Category           CategoryResponse   `json:"category" bson:"category"`
 	ProductName        string             `json:"product_name" bson:"product_name"`
+	Slug               string             `json:"slug" bson:"slug"`
+	SEO                model.SEO          `json:"seo" bson:"seo"`
 	ProductDescription string             `json:"product_description" bson:"product_description"`
 	RatingAverage      float64            `json:"rating_average" bson:"rating_average"`
 	ReviewsCount       int                `json:"reviews_count" bson:"reviews_count"`
+	RatingHistogram    map[string]int     `json:"rating_histogram" bson:"rating_histogram"`
 	Color              string             `json:"color" bson:"color"`
 	MainImagePublicID  string             `json:"main_image_public_id" bson:"main_image_public_id"`
 	MainImage          string             `json:"main_image" bson:"main_image"`
@@ -20,6 +24,15 @@ type ProductResponse struct {
 	Discount           float64            `json:"discount" bson:"discount"`
 	Currency           string             `json:"currency" bson:"currency"`
 	Sizes              []SizeOptions      `json:"sizes" bson:"sizes"`
+	Options            []ProductOption    `json:"options" bson:"options"`
+	Variants           []Variant          `json:"variants" bson:"variants"`
+	Sale               *Sale              `json:"sale" bson:"sale"`
+	CurrentPrice       float64            `json:"current_price" bson:"current_price"`
+	LowestPrice30Days  float64            `json:"lowest_price_30_days" bson:"lowest_price_30_days"`
+	Status             string             `json:"status" bson:"status"`
+	PublishAt          *time.Time         `json:"publish_at" bson:"publish_at"`
+	UnpublishAt        *time.Time         `json:"unpublish_at" bson:"unpublish_at"`
+	DeletedAt          *time.Time         `json:"deleted_at" bson:"deleted_at"`
 	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
 }
@@ -31,3 +44,45 @@ type CategoryResponse struct {
 	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time           `json:"updated_at" bson:"updated_at"`
 }
+
+type ProductsResponse struct {
+	Products   []*ProductResponse `json:"products" bson:"products"`
+	Pagination Pagination         `json:"pagination" bson:"pagination"`
+	Facets     *ProductFacets     `json:"facets" bson:"facets"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page" bson:"page"`
+	Limit      int   `json:"limit" bson:"limit"`
+	Total      int64 `json:"total" bson:"total"`
+	TotalPages int64 `json:"total_pages" bson:"total_pages"`
+}
+
+type ProductFacets struct {
+	Categories []FacetCount `json:"categories" bson:"categories"`
+	Sizes      []FacetCount `json:"sizes" bson:"sizes"`
+	Prices     []FacetCount `json:"prices" bson:"prices"`
+	Ratings    []FacetCount `json:"ratings" bson:"ratings"`
+}
+
+type FacetCount struct {
+	Value string `json:"value" bson:"value"`
+	Label string `json:"label,omitempty" bson:"label,omitempty"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+type SuggestionResponse struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	ProductName string             `json:"product_name" bson:"product_name"`
+	MainImage   string             `json:"main_image" bson:"main_image"`
+	Price       float64            `json:"price" bson:"price"`
+}
+
+type PriceHistoryResponse struct {
+	ProductID         primitive.ObjectID `json:"product_id"`
+	Currency          string             `json:"currency"`
+	CurrentPrice      float64            `json:"current_price"`
+	LowestPrice30Days float64            `json:"lowest_price_30_days"`
+	Sale              *Sale              `json:"sale"`
+	Changes           []*PriceChange     `json:"changes"`
+}
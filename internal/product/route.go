@@ -1,16 +1,29 @@
 package product
 
 import (
+	"modular_monolith/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRoutes(r *gin.Engine, handler *ProductHandler) {
 	productGroup := r.Group("/api/v1/product")
 	{
-		productGroup.POST("", handler.CreateProduct)
+		productGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateProduct)
 		productGroup.GET("", handler.GetAllProducts)
-		productGroup.GET("/:id", handler.GetProductByID)
-		productGroup.PUT("/:id", handler.UpdateProduct)
-		productGroup.DELETE("/:id", handler.DeleteProduct)
+		productGroup.GET("/search", handler.SearchProducts)
+		productGroup.GET("/suggest", handler.SuggestProducts)
+		productGroup.GET("/export", middleware.JWTAuthMiddleware(), handler.ExportProducts)
+		productGroup.POST("/import", middleware.JWTAuthMiddleware(), handler.ImportProducts)
+		productGroup.GET("/import/:job_id", middleware.JWTAuthMiddleware(), handler.GetImportJob)
+		productGroup.GET("/admin", middleware.JWTAuthMiddleware(), handler.GetAdminProducts)
+		productGroup.GET("/slug/:slug", middleware.OptionalJWTAuthMiddleware(), handler.GetProductBySlug)
+		productGroup.GET("/:id", middleware.OptionalJWTAuthMiddleware(), handler.GetProductByID)
+		productGroup.PUT("/:id", middleware.JWTAuthMiddleware(), handler.UpdateProduct)
+		productGroup.DELETE("/:id", middleware.JWTAuthMiddleware(), handler.DeleteProduct)
+		productGroup.POST("/:id/restore", middleware.JWTAuthMiddleware(), handler.RestoreProduct)
+		productGroup.PUT("/:id/sale", middleware.JWTAuthMiddleware(), handler.SetSale)
+		productGroup.DELETE("/:id/sale", middleware.JWTAuthMiddleware(), handler.CancelSale)
+		productGroup.GET("/:id/price-history", middleware.JWTAuthMiddleware(), handler.GetPriceHistory)
 	}
 }
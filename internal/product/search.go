@@ -0,0 +1,294 @@
+package product
+
+import (
+	"math"
+	"modular_monolith/helper"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Field weights used when ranking search hits.
+const (
+	weightName        = 3.0
+	weightSKU         = 3.0
+	weightCategory    = 2.0
+	weightAttribute   = 1.5
+	weightDescription = 1.0
+)
+
+// Match factors applied to a query token depending on how it matched a term.
+const (
+	factorExact  = 1.0
+	factorPrefix = 0.7
+	factorFuzzy  = 0.5
+)
+
+// searchIndex is an in-memory inverted index over products. Text is folded
+// so Vietnamese queries match with or without diacritics.
+type searchIndex struct {
+	mu       sync.RWMutex
+	docs     map[primitive.ObjectID]*searchDoc
+	postings map[string]map[primitive.ObjectID]float64
+}
+
+type searchDoc struct {
+	ID        primitive.ObjectID
+	Name      string
+	MainImage string
+	Price     float64
+	Rating    float64
+	folded    string
+	terms     map[string]float64
+}
+
+type searchHit struct {
+	Doc   *searchDoc
+	Score float64
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[primitive.ObjectID]*searchDoc),
+		postings: make(map[string]map[primitive.ObjectID]float64),
+	}
+}
+
+// Put adds or replaces the product in the index.
+func (idx *searchIndex) Put(product *Product, categoryName string) {
+
+	doc := &searchDoc{
+		ID:        product.ID,
+		Name:      product.ProductName,
+		MainImage: product.MainImage,
+		Price:     product.CurrentPrice(),
+		Rating:    product.RatingAverage,
+		folded:    strings.Join(tokenize(product.ProductName), " "),
+		terms:     make(map[string]float64),
+	}
+
+	addTerms := func(text string, weight float64) {
+		for _, term := range tokenize(text) {
+			doc.terms[term] += weight
+		}
+	}
+
+	addTerms(product.ProductName, weightName)
+	addTerms(categoryName, weightCategory)
+	addTerms(product.Color, weightAttribute)
+	addTerms(product.ProductDescription, weightDescription)
+
+	for _, v := range product.Variants {
+		addTerms(v.SKU, weightSKU)
+		for _, value := range v.Attributes {
+			addTerms(value, weightAttribute)
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(product.ID)
+
+	idx.docs[doc.ID] = doc
+	for term, weight := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[primitive.ObjectID]float64)
+		}
+		idx.postings[term][doc.ID] = weight
+	}
+}
+
+func (idx *searchIndex) Remove(id primitive.ObjectID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *searchIndex) remove(id primitive.ObjectID) {
+
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	delete(idx.docs, id)
+}
+
+// Search ranks products against the query. Products must match every token,
+// unless none does, then any token.
+func (idx *searchIndex) Search(query string) []searchHit {
+
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.docs))
+
+	scores := make(map[primitive.ObjectID]float64)
+	matched := make(map[primitive.ObjectID]int)
+
+	for _, token := range tokens {
+
+		tokenScores := make(map[primitive.ObjectID]float64)
+
+		for term, factor := range idx.expand(token) {
+			postings := idx.postings[term]
+			idf := math.Log(1 + total/float64(len(postings)))
+
+			for id, weight := range postings {
+				if score := weight * factor * idf; score > tokenScores[id] {
+					tokenScores[id] = score
+				}
+			}
+		}
+
+		for id, score := range tokenScores {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	phrase := strings.Join(tokens, " ")
+
+	var all, partial []searchHit
+	for id, score := range scores {
+		doc := idx.docs[id]
+
+		// Boost names containing the whole query as typed
+		if strings.Contains(doc.folded, phrase) {
+			score += weightName
+		}
+
+		hit := searchHit{Doc: doc, Score: score}
+		if matched[id] == len(tokens) {
+			all = append(all, hit)
+		} else {
+			partial = append(partial, hit)
+		}
+	}
+
+	hits := all
+	if len(hits) == 0 {
+		hits = partial
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Doc.Rating != hits[j].Doc.Rating {
+			return hits[i].Doc.Rating > hits[j].Doc.Rating
+		}
+		return hits[i].Doc.Name < hits[j].Doc.Name
+	})
+
+	return hits
+}
+
+// expand returns the indexed terms a query token matches, exactly, as prefix
+// or within the edit distance, with the factor to apply.
+func (idx *searchIndex) expand(token string) map[string]float64 {
+
+	terms := make(map[string]float64)
+
+	if _, ok := idx.postings[token]; ok {
+		terms[token] = factorExact
+	}
+
+	maxEdits := allowedEdits(token)
+
+	for term := range idx.postings {
+		if term == token {
+			continue
+		}
+
+		if len(token) >= 2 && strings.HasPrefix(term, token) {
+			terms[term] = factorPrefix
+			continue
+		}
+
+		if maxEdits > 0 && abs(len(term)-len(token)) <= maxEdits && editDistance(token, term, maxEdits) <= maxEdits {
+			terms[term] = factorFuzzy
+		}
+	}
+
+	return terms
+}
+
+func allowedEdits(token string) int {
+	switch {
+	case len(token) >= 8:
+		return 2
+	case len(token) >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// editDistance computes the Levenshtein distance between a and b, giving up
+// once it exceeds max.
+func editDistance(a, b string, max int) int {
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// tokenize folds the text and splits it into terms.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(helper.FoldVietnamese(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,60 @@
+package product
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Giày Đá Bóng", []string{"giay", "da", "bong"}},
+		{"  áo-thun,size XL!! ", []string{"ao", "thun", "size", "xl"}},
+		{"Nike Mercurial 2024", []string{"nike", "mercurial", "2024"}},
+		{"", nil},
+		{"--- ...", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := tokenize(tt.text)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"giay", "giay", 2, 0},
+		{"giay", "giya", 2, 2},
+		{"bong", "bongg", 2, 1},
+		{"adidas", "adiddas", 1, 1},
+		{"kitten", "sitting", 3, 3},
+		{"", "abc", 3, 3},
+		{"abc", "", 3, 3},
+		// Gives up once the distance exceeds max
+		{"kitten", "sitting", 1, 2},
+		{"nike", "puma", 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+			}
+		})
+	}
+}
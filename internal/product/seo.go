@@ -0,0 +1,75 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/helper"
+)
+
+// assignSlug sets the product slug from the requested slug or the name.
+func (s *productService) assignSlug(ctx context.Context, product *Product, requested string) error {
+	return helper.AssignSlug(&product.Slug, &product.SlugHistory, requested, product.ProductName, product.ID.Hex(), func(slug string) (bool, error) {
+		return s.repository.ExistsSlug(ctx, slug, product.ID)
+	})
+}
+
+// applySEO fills the SEO metadata, defaulting to the product name and
+// description when no custom values are given.
+func (s *productService) applySEO(product *Product, metaTitle, metaDescription string) {
+
+	if metaTitle == "" {
+		metaTitle = product.ProductName
+	}
+
+	if metaDescription == "" {
+		metaDescription = helper.MetaDescription(product.ProductDescription)
+	}
+
+	product.SEO.MetaTitle = metaTitle
+	product.SEO.MetaDescription = metaDescription
+	product.SEO.CanonicalURL = s.seoConfig.SiteURL + "/product/" + product.Slug
+}
+
+// MigrateSlugs backfills the slug and SEO metadata of older products.
+func (s *productService) MigrateSlugs(ctx context.Context) error {
+
+	products, _, err := s.repository.FindAll(ctx, &ProductFilter{Admin: true, IncludeDeleted: true})
+	if err != nil {
+		return err
+	}
+
+	err = helper.MigrateSlugs(products, func(product *Product) string { return product.Slug }, func(product *Product) error {
+
+		if err := s.assignSlug(ctx, product, ""); err != nil {
+			return err
+		}
+
+		s.applySEO(product, "", "")
+
+		if err := s.repository.UpdateByID(ctx, product.ID, product); err != nil {
+			return fmt.Errorf("failed to migrate product %s: %w", product.ID.Hex(), err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repository.EnsureSlugIndex(ctx)
+}
+
+// GetProductBySlug looks a product up by its current or a previous slug.
+func (s *productService) GetProductBySlug(ctx context.Context, userID string, slug string) (*ProductResponse, error) {
+
+	product, err := s.repository.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if product == nil || !s.visibleTo(ctx, userID, product) {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	return s.toProductResponse(ctx, product)
+}
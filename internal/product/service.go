@@ -3,9 +3,14 @@ package product
 import (
 	"context"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"modular_monolith/config"
 	"modular_monolith/helper"
 	"modular_monolith/internal/category"
-	"modular_monolith/internal/reviews"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/shared/ports"
+	"modular_monolith/internal/user"
 	"os"
 	"time"
 
@@ -13,33 +18,70 @@ import (
 )
 
 type ProductService interface {
-	CreateProduct(ctx context.Context, req *CreateProductRequest, productFiles ProductFiles) error
-	GetAllProducts(ctx context.Context, filter *ProductFilter) ([]*ProductResponse, error)
-	GetProductByID(ctx context.Context, id string) (*ProductResponse, error)
-	UpdateProduct(ctx context.Context, id string, req *UpdateProductRequest, productFiles ProductFiles) error
-	DeleteProduct(ctx context.Context, id string) error
+	CreateProduct(ctx context.Context, userID string, req *CreateProductRequest, productFiles ProductFiles) error
+	GetAllProducts(ctx context.Context, filter *ProductFilter) (*ProductsResponse, error)
+	GetProductByID(ctx context.Context, userID string, id string) (*ProductResponse, error)
+	GetProductsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*ProductResponse, error)
+	UpdateProduct(ctx context.Context, userID string, id string, req *UpdateProductRequest, productFiles ProductFiles) error
+	DeleteProduct(ctx context.Context, userID string, id string) error
+	MigrateVariants(ctx context.Context) error
+	SearchProducts(ctx context.Context, req *SearchRequest) (*ProductsResponse, error)
+	SuggestProducts(ctx context.Context, req *SearchRequest) ([]*SuggestionResponse, error)
+	BuildSearchIndex(ctx context.Context) error
+	GetProductBySlug(ctx context.Context, userID string, slug string) (*ProductResponse, error)
+	MigrateSlugs(ctx context.Context) error
+	ImportProducts(ctx context.Context, userID string, file *multipart.FileHeader, dryRun bool) (*ImportJob, error)
+	GetImportJob(ctx context.Context, userID string, id string) (*ImportJob, error)
+	ExportProducts(ctx context.Context, userID string, format string, w io.Writer) error
+	GetAdminProducts(ctx context.Context, userID string, filter *ProductFilter) (*ProductsResponse, error)
+	RestoreProduct(ctx context.Context, userID string, id string) error
+	CronPublishSchedule(ctx context.Context) error
+	SetSale(ctx context.Context, userID string, id string, req *SaleRequest) error
+	CancelSale(ctx context.Context, userID string, id string) error
+	GetPriceHistory(ctx context.Context, userID string, id string, req *PriceHistoryRequest) (*PriceHistoryResponse, error)
+	CronPriceSchedule(ctx context.Context) error
+	RefreshLowestPrices(ctx context.Context) error
 }
 
 type productService struct {
-	repository      ProductRepository
-	reviewService   reviews.ReviewService
-	categoryService category.CategoryService
-	cloudUploader   *helper.CloudinaryUploader
+	repository             ProductRepository
+	importJobRepository    ImportJobRepository
+	priceHistoryRepository PriceHistoryRepository
+	userRepository         user.UserRepository
+	inventorySync          ports.InventorySync
+	categoryService        category.CategoryService
+	cloudUploader          *helper.CloudinaryUploader
+	searchIndex            *searchIndex
+	seoConfig              config.SEOConfig
 }
 
 func NewProductService(repository ProductRepository,
+	importJobRepository ImportJobRepository,
+	priceHistoryRepository PriceHistoryRepository,
+	userRepository user.UserRepository,
+	inventorySync ports.InventorySync,
 	uploader *helper.CloudinaryUploader,
-	reviewService reviews.ReviewService,
-	categoryService category.CategoryService) ProductService {
+	categoryService category.CategoryService,
+	seoConfig config.SEOConfig) ProductService {
 	return &productService{
-		repository:      repository,
-		cloudUploader:   uploader,
-		reviewService:   reviewService,
-		categoryService: categoryService,
+		repository:             repository,
+		importJobRepository:    importJobRepository,
+		priceHistoryRepository: priceHistoryRepository,
+		userRepository:         userRepository,
+		inventorySync:          inventorySync,
+		cloudUploader:          uploader,
+		categoryService:        categoryService,
+		searchIndex:            newSearchIndex(),
+		seoConfig:              seoConfig,
 	}
 }
 
-func (s *productService) CreateProduct(ctx context.Context, req *CreateProductRequest, productFiles ProductFiles) error {
+func (s *productService) CreateProduct(ctx context.Context, userID string, req *CreateProductRequest, productFiles ProductFiles) error {
+
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
 
 	if req.ProductName == "" {
 		return fmt.Errorf("product name is required")
@@ -70,8 +112,8 @@ func (s *productService) CreateProduct(ctx context.Context, req *CreateProductRe
 		return fmt.Errorf("invalid category id: %v", err)
 	}
 
-	if len(req.Sizes) == 0 {
-		return fmt.Errorf("sizes are required")
+	if len(req.Sizes) == 0 && len(req.Variants) == 0 {
+		return fmt.Errorf("sizes or variants are required")
 	}
 
 	var sizes []SizeOptions
@@ -89,8 +131,23 @@ func (s *productService) CreateProduct(ctx context.Context, req *CreateProductRe
 		sizes = append(sizes, SizeOptions(s))
 	}
 
+	status, err := resolveStatus(req.Status, "", req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		return err
+	}
+
+	productID := primitive.NewObjectID()
+
+	variants, err := s.buildVariants(ctx, productID, req.Color, req.Variants, sizes, nil, productFiles.VariantImages)
+	if err != nil {
+		return err
+	}
+
+	sizes, options := summarizeVariants(variants)
+
 	// Create product object
 	product := &Product{
+		ID:                 productID,
 		ProductName:        req.ProductName,
 		ProductDescription: req.ProductDescription,
 		CategoryID:         categoryID,
@@ -99,10 +156,22 @@ func (s *productService) CreateProduct(ctx context.Context, req *CreateProductRe
 		Discount:           req.Discount,
 		Currency:           req.Currency,
 		Sizes:              sizes,
+		Options:            options,
+		Variants:           variants,
+		Status:             status,
+		PublishAt:          req.PublishAt,
+		UnpublishAt:        req.UnpublishAt,
+		LowestPrice30Days:  req.Price,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
+	if err := s.assignSlug(ctx, product, req.Slug); err != nil {
+		return err
+	}
+
+	s.applySEO(product, req.MetaTitle, req.MetaDescription)
+
 	// Handle main image upload
 	if productFiles.MainImage != nil {
 		tempPath := "/tmp/" + productFiles.MainImage.Filename
@@ -141,77 +210,80 @@ func (s *productService) CreateProduct(ctx context.Context, req *CreateProductRe
 	}
 	product.SubImages = subImages
 
-	return s.repository.Create(ctx, product)
+	if err := s.repository.Create(ctx, product); err != nil {
+		return err
+	}
+
+	s.savePriceChanges(ctx, priceChanges(product, nil, PriceChangeCreated, &staff.ID))
+	s.syncStock(ctx, product, model.StockChange{Type: model.MovementAdjustment, Reason: "product created", ActorID: &staff.ID})
+	s.indexProduct(ctx, product)
+
+	return nil
 }
 
-func (s *productService) GetAllProducts(ctx context.Context, filter *ProductFilter) ([]*ProductResponse, error) {
-	products, err := s.repository.FindAll(ctx, filter)
-	if err != nil {
-		return nil, err
+func (s *productService) GetAllProducts(ctx context.Context, filter *ProductFilter) (*ProductsResponse, error) {
+
+	if filter.Page <= 0 {
+		filter.Page = 1
 	}
 
-	var responses []*ProductResponse
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	} else if filter.Limit > 100 {
+		filter.Limit = 100
+	}
 
-	for _, product := range products {
-		reviewResp, err := s.reviewService.GetAllReviews(ctx, product.ID.Hex())
+	if filter.CategoryID != "" {
+		categoryIDs, err := s.categoryService.GetDescendantIDs(ctx, filter.CategoryID)
 		if err != nil {
 			return nil, err
 		}
+		filter.CategoryIDs = categoryIDs
+	}
 
-		reviews := reviewResp.ReviewsResponse
-		totalRating := 0
-		for _, review := range reviews {
-			totalRating += review.Rating
-		}
+	products, total, err := s.repository.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
 
-		var ratingAverage float64
-		if len(reviews) > 0 {
-			ratingAverage = float64(totalRating) / float64(len(reviews))
-		}
-		reviewsCount := len(reviews)
+	facets, err := s.repository.FindFacets(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count facets: %w", err)
+	}
 
-		category, err := s.categoryService.GetCategory(ctx, product.CategoryID.Hex())
-		if err != nil {
-			return nil, fmt.Errorf("failed to get category: %w", err)
+	for i, facet := range facets.Categories {
+		if category, err := s.categoryService.GetCategory(ctx, facet.Value); err == nil {
+			facets.Categories[i].Label = category.CategoryName
 		}
+	}
 
-		categoryData := CategoryResponse{
-			ID:           category.ID,
-			CategoryName: category.CategoryName,
-			ParentID:     category.ParentID,
-			CreatedAt:    category.CreatedAt,
-			UpdatedAt:    category.UpdatedAt,
-		}
+	responses := []*ProductResponse{}
 
-		resp := &ProductResponse{
-			ID:                 product.ID,
-			ProductName:        product.ProductName,
-			ProductDescription: product.ProductDescription,
-			Color:              product.Color,
-			Price:              product.Price,
-			Discount:           product.Discount,
-			Currency:           product.Currency,
-			Sizes:              product.Sizes,
-			Category:           categoryData,
-			MainImage:          product.MainImage,
-			SubImages:          product.SubImages,
-			RatingAverage:      ratingAverage,
-			ReviewsCount:       reviewsCount,
-			CreatedAt:          product.CreatedAt,
-			UpdatedAt:          product.UpdatedAt,
-		}
+	for _, product := range products {
 
-		if filter.Rating > 0 && resp.RatingAverage < filter.Rating {
-			continue
+		resp, err := s.toProductResponse(ctx, product)
+		if err != nil {
+			return nil, err
 		}
 
 		responses = append(responses, resp)
 	}
 
-	return responses, nil
+	result := &ProductsResponse{
+		Products: responses,
+		Pagination: Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		},
+		Facets: facets,
+	}
+
+	return result, nil
 }
 
-func (s *productService) GetProductByID(ctx context.Context, id string) (*ProductResponse, error) {
+func (s *productService) GetProductByID(ctx context.Context, userID string, id string) (*ProductResponse, error) {
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -219,29 +291,45 @@ func (s *productService) GetProductByID(ctx context.Context, id string) (*Produc
 	}
 
 	product, err := s.repository.FindByID(ctx, objectID)
-	if err != nil || product == nil {
+	if err != nil || product == nil || !s.visibleTo(ctx, userID, product) {
 		return nil, fmt.Errorf("product not found")
 	}
 
-	reviewResp, err := s.reviewService.GetAllReviews(ctx, product.ID.Hex())
+	return s.toProductResponse(ctx, product)
+}
+
+// GetProductsByIDs returns the visible products among ids in the order given.
+func (s *productService) GetProductsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*ProductResponse, error) {
+
+	products, err := s.repository.FindByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
 
-	reviews := reviewResp.ReviewsResponse
-	totalRating := 0
-
-	for _, review := range reviews {
-		totalRating += review.Rating
+	byID := make(map[primitive.ObjectID]*Product)
+	for _, product := range products {
+		byID[product.ID] = product
 	}
 
-	if len(reviews) > 0 {
-		product.RatingAverage = float64(totalRating) / float64(len(reviews))
-	} else {
-		product.RatingAverage = 0
+	responses := []*ProductResponse{}
+	for _, id := range ids {
+		product, ok := byID[id]
+		if !ok || !product.IsVisible() {
+			continue
+		}
+
+		resp, err := s.toProductResponse(ctx, product)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, resp)
 	}
 
-	product.ReviewsCount = len(reviews)
+	return responses, nil
+}
+
+func (s *productService) toProductResponse(ctx context.Context, product *Product) (*ProductResponse, error) {
 
 	category, err := s.categoryService.GetCategory(ctx, product.CategoryID.Hex())
 	if err != nil {
@@ -259,32 +347,48 @@ func (s *productService) GetProductByID(ctx context.Context, id string) (*Produc
 	result := &ProductResponse{
 		ID:                 product.ID,
 		ProductName:        product.ProductName,
+		Slug:               product.Slug,
+		SEO:                product.SEO,
 		ProductDescription: product.ProductDescription,
 		Color:              product.Color,
 		Price:              product.Price,
 		Discount:           product.Discount,
 		Currency:           product.Currency,
 		Sizes:              product.Sizes,
+		Options:            product.Options,
+		Variants:           product.Variants,
+		Sale:               product.Sale,
+		CurrentPrice:       product.CurrentPrice(),
+		LowestPrice30Days:  product.LowestPrice30Days,
+		Status:             product.Status,
+		PublishAt:          product.PublishAt,
+		UnpublishAt:        product.UnpublishAt,
+		DeletedAt:          product.DeletedAt,
 		Category:           categoryData,
 		MainImage:          product.MainImage,
 		SubImages:          product.SubImages,
 		RatingAverage:      product.RatingAverage,
 		ReviewsCount:       product.ReviewsCount,
+		RatingHistogram:    product.RatingHistogram,
 		CreatedAt:          product.CreatedAt,
 		UpdatedAt:          product.UpdatedAt,
 	}
 
 	return result, nil
-
 }
 
-func (s *productService) UpdateProduct(ctx context.Context, id string, req *UpdateProductRequest, productFiles ProductFiles) error {
+func (s *productService) UpdateProduct(ctx context.Context, userID string, id string, req *UpdateProductRequest, productFiles ProductFiles) error {
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid product id: %v", err)
 	}
 
+	staff, err := user.RequireStaff(ctx, s.userRepository, userID)
+	if err != nil {
+		return err
+	}
+
 	if req.ProductName == "" {
 		return fmt.Errorf("product name is required")
 	}
@@ -314,8 +418,8 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req *Upda
 		return fmt.Errorf("invalid category id: %v", err)
 	}
 
-	if len(req.Sizes) == 0 {
-		return fmt.Errorf("sizes are required")
+	if len(req.Sizes) == 0 && len(req.Variants) == 0 {
+		return fmt.Errorf("sizes or variants are required")
 	}
 
 	// Get existing product
@@ -324,6 +428,15 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req *Upda
 		return fmt.Errorf("failed to get existing product: %w", err)
 	}
 
+	if existingProduct == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	status, err := resolveStatus(req.Status, existingProduct.Status, req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		return err
+	}
+
 	// Validate and convert sizes
 	var sizes []SizeOptions
 	for i, s := range req.Sizes {
@@ -339,6 +452,15 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req *Upda
 		sizes = append(sizes, SizeOptions(s))
 	}
 
+	variants, err := s.buildVariants(ctx, objectID, req.Color, req.Variants, sizes, existingProduct.Variants, productFiles.VariantImages)
+	if err != nil {
+		return err
+	}
+
+	sizes, options := summarizeVariants(variants)
+
+	before := snapshotPrices(existingProduct)
+
 	// Update product object
 	existingProduct.ProductName = req.ProductName
 	existingProduct.ProductDescription = req.ProductDescription
@@ -348,8 +470,27 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req *Upda
 	existingProduct.Discount = req.Discount
 	existingProduct.Currency = req.Currency
 	existingProduct.Sizes = sizes
+	existingProduct.Options = options
+	existingProduct.Variants = variants
+	existingProduct.Status = status
+	existingProduct.PublishAt = req.PublishAt
+	existingProduct.UnpublishAt = req.UnpublishAt
 	existingProduct.UpdatedAt = time.Now()
 
+	// A sale priced at or above the new price no longer makes sense
+	if existingProduct.Sale != nil && existingProduct.Sale.Price >= req.Price {
+		existingProduct.Sale = nil
+	}
+
+	changes := priceChanges(existingProduct, before, PriceChangeManual, &staff.ID)
+	existingProduct.LowestPrice30Days = s.lowestPrice30Days(ctx, existingProduct, changes)
+
+	if err := s.assignSlug(ctx, existingProduct, req.Slug); err != nil {
+		return err
+	}
+
+	s.applySEO(existingProduct, req.MetaTitle, req.MetaDescription)
+
 	// Handle main image upload (if new image provided)
 	if productFiles.MainImage != nil {
 		// Delete old main image if exists
@@ -406,10 +547,22 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req *Upda
 		existingProduct.SubImages = subImages
 	}
 
-	return s.repository.UpdateByID(ctx, objectID, existingProduct)
+	if err := s.repository.UpdateByID(ctx, objectID, existingProduct); err != nil {
+		return err
+	}
+
+	s.savePriceChanges(ctx, changes)
+	s.syncStock(ctx, existingProduct, model.StockChange{Type: model.MovementAdjustment, Reason: "product edited", ActorID: &staff.ID})
+	s.indexProduct(ctx, existingProduct)
+
+	return nil
 }
 
-func (s *productService) DeleteProduct(ctx context.Context, id string) error {
+func (s *productService) DeleteProduct(ctx context.Context, userID string, id string) error {
+
+	if _, err := user.RequireStaff(ctx, s.userRepository, userID); err != nil {
+		return err
+	}
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -421,17 +574,130 @@ func (s *productService) DeleteProduct(ctx context.Context, id string) error {
 		return fmt.Errorf("product not found")
 	}
 
-	err = s.cloudUploader.DeleteImage(ctx, product.MainImagePublicID)
+	if product.DeletedAt != nil {
+		return fmt.Errorf("product is already deleted")
+	}
+
+	// Carts, orders and reviews still reference the product
+	if err := s.repository.SoftDeleteByID(ctx, objectID, time.Now()); err != nil {
+		return err
+	}
+
+	s.searchIndex.Remove(objectID)
+
+	return nil
+}
+
+// BuildSearchIndex loads every visible product into the in-memory search index.
+func (s *productService) BuildSearchIndex(ctx context.Context) error {
+
+	products, _, err := s.repository.FindAll(ctx, &ProductFilter{})
 	if err != nil {
-		return fmt.Errorf("failed to delete main image: %w", err)
+		return err
 	}
 
-	for _, sub := range product.SubImages {
-		err := s.cloudUploader.DeleteImage(ctx, sub.SubImagePublicID)
-		if err != nil {
-			return fmt.Errorf("failed to delete sub image: %w", err)
+	for _, product := range products {
+		s.indexProduct(ctx, product)
+	}
+
+	return nil
+}
+
+func (s *productService) indexProduct(ctx context.Context, product *Product) {
+
+	if !product.IsVisible() {
+		s.searchIndex.Remove(product.ID)
+		return
+	}
+
+	var categoryName string
+	if category, err := s.categoryService.GetCategory(ctx, product.CategoryID.Hex()); err == nil && category != nil {
+		categoryName = category.CategoryName
+	}
+
+	s.searchIndex.Put(product, categoryName)
+}
+
+// syncStock books the opening stock of new variants in the inventory, which
+// owns the stock per warehouse.
+func (s *productService) syncStock(ctx context.Context, product *Product, change model.StockChange) {
+	if err := s.inventorySync.SyncProductStock(ctx, product.ID, change); err != nil {
+		fmt.Printf("Warning: failed to sync stock of product %s: %v\n", product.ID.Hex(), err)
+	}
+}
+
+func (s *productService) SearchProducts(ctx context.Context, req *SearchRequest) (*ProductsResponse, error) {
+
+	if req.Query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	} else if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	hits := s.searchIndex.Search(req.Query)
+	total := int64(len(hits))
+
+	start := (req.Page - 1) * req.Limit
+	if start > len(hits) {
+		start = len(hits)
+	}
+	end := start + req.Limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+
+	var ids []primitive.ObjectID
+	for _, hit := range hits[start:end] {
+		ids = append(ids, hit.Doc.ID)
+	}
+
+	// Keep the ranking order of the index
+	responses, err := s.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProductsResponse{
+		Products: responses,
+		Pagination: Pagination{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: (total + int64(req.Limit) - 1) / int64(req.Limit),
+		},
+	}
+
+	return result, nil
+}
+
+func (s *productService) SuggestProducts(ctx context.Context, req *SearchRequest) ([]*SuggestionResponse, error) {
+
+	if req.Limit <= 0 || req.Limit > 10 {
+		req.Limit = 10
+	}
+
+	suggestions := []*SuggestionResponse{}
+
+	for _, hit := range s.searchIndex.Search(req.Query) {
+		if len(suggestions) == req.Limit {
+			break
 		}
+
+		suggestions = append(suggestions, &SuggestionResponse{
+			ID:          hit.Doc.ID,
+			ProductName: hit.Doc.Name,
+			MainImage:   hit.Doc.MainImage,
+			Price:       hit.Doc.Price,
+		})
 	}
 
-	return s.repository.DeleteByID(ctx, objectID)
+	return suggestions, nil
 }
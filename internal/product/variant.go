@@ -0,0 +1,376 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"modular_monolith/helper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buildVariants validates the requested variants and merges them with the
+// stored ones. Without variants they are derived from the legacy size list.
+func (s *productService) buildVariants(ctx context.Context, productID primitive.ObjectID, color string, reqVariants []CreateVariantRequest, sizes []SizeOptions, existing []Variant, images map[int][]*multipart.FileHeader) ([]Variant, error) {
+
+	if len(reqVariants) == 0 {
+		if isBundle(existing) {
+			return nil, fmt.Errorf("bundles must be updated with the variants field")
+		}
+		return deriveVariants(productID, color, sizes, existing)
+	}
+
+	existingByID := make(map[primitive.ObjectID]Variant)
+	for _, v := range existing {
+		existingByID[v.ID] = v
+	}
+
+	seenSKU := make(map[string]bool)
+	seenCombo := make(map[string]bool)
+
+	var variants []Variant
+
+	for i, req := range reqVariants {
+
+		attributes := make(map[string]string)
+		for k, v := range req.Attributes {
+			k = strings.ToLower(strings.TrimSpace(k))
+			v = strings.TrimSpace(v)
+			if k == "" || v == "" {
+				return nil, fmt.Errorf("invalid attribute for variant at index %d", i)
+			}
+			attributes[k] = v
+		}
+
+		if len(attributes) == 0 {
+			return nil, fmt.Errorf("attributes are required for variant at index %d", i)
+		}
+
+		if req.Stock < 0 {
+			return nil, fmt.Errorf("invalid stock for variant at index %d", i)
+		}
+
+		if req.Price != nil && *req.Price <= 0 {
+			return nil, fmt.Errorf("invalid price for variant at index %d", i)
+		}
+
+		if req.Weight < 0 {
+			return nil, fmt.Errorf("invalid weight for variant at index %d", i)
+		}
+
+		combo := attributeKey(attributes)
+		if seenCombo[combo] {
+			return nil, fmt.Errorf("duplicate variant %s", combo)
+		}
+		seenCombo[combo] = true
+
+		variant := Variant{
+			ID:         primitive.NewObjectID(),
+			Attributes: attributes,
+			Price:      req.Price,
+			Barcode:    strings.TrimSpace(req.Barcode),
+			Weight:     req.Weight,
+			Stock:      req.Stock,
+		}
+
+		if len(req.Components) > 0 {
+			components, err := s.buildComponents(ctx, productID, req.Components, i)
+			if err != nil {
+				return nil, err
+			}
+
+			variant.Components = components
+			variant.Stock = 0
+		}
+
+		if req.ID != "" {
+			variantID, err := primitive.ObjectIDFromHex(req.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid variant id at index %d: %v", i, err)
+			}
+
+			old, ok := existingByID[variantID]
+			if !ok {
+				return nil, fmt.Errorf("variant %s not found", req.ID)
+			}
+
+			// Stock of existing variants only changes through the inventory
+			variant.ID = old.ID
+			variant.SKU = old.SKU
+			variant.Stock = old.Stock
+			variant.Images = old.Images
+			delete(existingByID, variantID)
+		}
+
+		if sku := strings.ToUpper(strings.TrimSpace(req.SKU)); sku != "" {
+			variant.SKU = sku
+		}
+
+		if variant.SKU == "" {
+			variant.SKU = generateSKU(productID, attributes)
+		}
+
+		if seenSKU[variant.SKU] {
+			return nil, fmt.Errorf("duplicate sku %s", variant.SKU)
+		}
+		seenSKU[variant.SKU] = true
+
+		exists, err := s.repository.ExistsSKU(ctx, variant.SKU, productID)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			return nil, fmt.Errorf("sku %s is already used by another product", variant.SKU)
+		}
+
+		variants = append(variants, variant)
+	}
+
+	bundle := isBundle(variants)
+	for i, v := range variants {
+		if bundle && len(v.Components) == 0 {
+			return nil, fmt.Errorf("components are required for variant at index %d of a bundle", i)
+		}
+	}
+
+	if len(existing) > 0 && bundle != isBundle(existing) {
+		return nil, fmt.Errorf("a product cannot be turned into a bundle or back")
+	}
+
+	// Old images are only deleted once every upload succeeded
+	uploaded := make(map[int][]SubImage)
+
+	for i := range variants {
+		files := images[i]
+		if len(files) == 0 {
+			continue
+		}
+
+		variantImages, err := s.uploadVariantImages(ctx, files)
+		if err != nil {
+			for _, done := range uploaded {
+				s.deleteVariantImages(ctx, done)
+			}
+			return nil, fmt.Errorf("failed to upload images for variant at index %d: %w", i, err)
+		}
+		uploaded[i] = variantImages
+	}
+
+	for i, variantImages := range uploaded {
+		s.deleteVariantImages(ctx, variants[i].Images)
+		variants[i].Images = variantImages
+	}
+
+	for _, removed := range existingByID {
+		s.deleteVariantImages(ctx, removed.Images)
+	}
+
+	return variants, nil
+}
+
+// deriveVariants maps the legacy size list to variants. Stored variants of a
+// listed size are kept so carts and orders keep their ids; the size stock only
+// applies to new variants.
+func deriveVariants(productID primitive.ObjectID, color string, sizes []SizeOptions, existing []Variant) ([]Variant, error) {
+
+	bySize := make(map[string][]Variant)
+	for _, old := range existing {
+		size, ok := old.Attributes["size"]
+		if !ok {
+			return nil, fmt.Errorf("product has variants without a size, update them with the variants field")
+		}
+		bySize[size] = append(bySize[size], old)
+	}
+
+	seen := make(map[string]bool)
+	var variants []Variant
+
+	for _, size := range sizes {
+
+		if seen[size.Size] {
+			return nil, fmt.Errorf("duplicate size %s", size.Size)
+		}
+		seen[size.Size] = true
+
+		// A size sold in a single variant follows the color of the product
+		if olds := bySize[size.Size]; len(olds) > 0 {
+			if len(olds) == 1 && color != "" {
+				attributes := make(map[string]string)
+				for k, v := range olds[0].Attributes {
+					attributes[k] = v
+				}
+				attributes["color"] = color
+				olds[0].Attributes = attributes
+			}
+
+			variants = append(variants, olds...)
+			continue
+		}
+
+		attributes := map[string]string{"size": size.Size}
+		if color != "" {
+			attributes["color"] = color
+		}
+
+		variants = append(variants, Variant{
+			ID:         primitive.NewObjectID(),
+			SKU:        generateSKU(productID, attributes),
+			Attributes: attributes,
+			Stock:      size.Stock,
+		})
+	}
+
+	return variants, nil
+}
+
+// summarizeVariants rebuilds the per-size stock summary and the option axes
+// from the variants.
+func summarizeVariants(variants []Variant) ([]SizeOptions, []ProductOption) {
+
+	var sizes []SizeOptions
+	sizeIndex := make(map[string]int)
+
+	var options []ProductOption
+	optionIndex := make(map[string]int)
+	seenValue := make(map[string]bool)
+
+	for _, v := range variants {
+
+		if size, ok := v.Attributes["size"]; ok {
+			if i, found := sizeIndex[size]; found {
+				sizes[i].Stock += v.Stock
+			} else {
+				sizeIndex[size] = len(sizes)
+				sizes = append(sizes, SizeOptions{Size: size, Stock: v.Stock})
+			}
+		}
+
+		for _, name := range sortedKeys(v.Attributes) {
+			value := v.Attributes[name]
+
+			i, found := optionIndex[name]
+			if !found {
+				i = len(options)
+				optionIndex[name] = i
+				options = append(options, ProductOption{Name: name})
+			}
+
+			if !seenValue[name+"="+value] {
+				seenValue[name+"="+value] = true
+				options[i].Values = append(options[i].Values, value)
+			}
+		}
+	}
+
+	return sizes, options
+}
+
+// ApplyStock sets the stock of the variants and rebuilds the per-size summary.
+func (p *Product) ApplyStock(stock map[primitive.ObjectID]int) {
+	for i := range p.Variants {
+		p.Variants[i].Stock = stock[p.Variants[i].ID]
+	}
+	p.Sizes, _ = summarizeVariants(p.Variants)
+}
+
+func (s *productService) uploadVariantImages(ctx context.Context, files []*multipart.FileHeader) ([]SubImage, error) {
+
+	var images []SubImage
+
+	for _, file := range files {
+		tempPath := "/tmp/" + primitive.NewObjectID().Hex() + filepath.Ext(file.Filename)
+		if err := helper.SaveUploadedFile(file, tempPath); err != nil {
+			s.deleteVariantImages(ctx, images)
+			return nil, err
+		}
+
+		url, publicID, err := s.cloudUploader.UploadImage(ctx, tempPath, "products")
+		os.Remove(tempPath)
+		if err != nil {
+			s.deleteVariantImages(ctx, images)
+			return nil, err
+		}
+
+		images = append(images, SubImage{
+			SubImagePublicID: publicID,
+			Url:              url,
+		})
+	}
+
+	return images, nil
+}
+
+func (s *productService) deleteVariantImages(ctx context.Context, images []SubImage) {
+	for _, img := range images {
+		if err := s.cloudUploader.DeleteImage(ctx, img.SubImagePublicID); err != nil {
+			fmt.Printf("Warning: failed to delete variant image: %v\n", err)
+		}
+	}
+}
+
+// MigrateVariants backfills variants for products created before variants
+// existed, one variant per size.
+func (s *productService) MigrateVariants(ctx context.Context) error {
+
+	products, err := s.repository.FindWithoutVariants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+
+		variants, err := deriveVariants(product.ID, product.Color, product.Sizes, nil)
+		if err != nil {
+			return err
+		}
+
+		product.Variants = variants
+		product.Sizes, product.Options = summarizeVariants(variants)
+
+		if err := s.repository.UpdateByID(ctx, product.ID, product); err != nil {
+			return fmt.Errorf("failed to migrate product %s: %w", product.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func generateSKU(productID primitive.ObjectID, attributes map[string]string) string {
+
+	hex := strings.ToUpper(productID.Hex())
+	parts := []string{hex[len(hex)-6:]}
+
+	for _, k := range sortedKeys(attributes) {
+		value := strings.ToUpper(attributes[k])
+		value = strings.Join(strings.Fields(value), "")
+		parts = append(parts, value)
+	}
+
+	return strings.Join(parts, "-")
+}
+
+func attributeKey(attributes map[string]string) string {
+
+	var parts []string
+	for _, k := range sortedKeys(attributes) {
+		parts = append(parts, k+"="+strings.ToLower(attributes[k]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func sortedKeys(m map[string]string) []string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
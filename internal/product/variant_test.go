@@ -0,0 +1,107 @@
+package product
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeriveVariants(t *testing.T) {
+
+	productID := primitive.NewObjectID()
+
+	m := Variant{ID: primitive.NewObjectID(), SKU: "M", Attributes: map[string]string{"size": "M", "color": "red"}, Stock: 4}
+	lBlue := Variant{ID: primitive.NewObjectID(), SKU: "L-BLUE", Attributes: map[string]string{"size": "L", "color": "blue"}, Stock: 1}
+	lRed := Variant{ID: primitive.NewObjectID(), SKU: "L-RED", Attributes: map[string]string{"size": "L", "color": "red"}, Stock: 2}
+	noSize := Variant{ID: primitive.NewObjectID(), SKU: "X", Attributes: map[string]string{"color": "red"}}
+
+	type want struct {
+		id         primitive.ObjectID
+		attributes map[string]string
+		stock      int
+	}
+
+	tests := []struct {
+		name     string
+		color    string
+		sizes    []SizeOptions
+		existing []Variant
+		want     []want
+		wantErr  bool
+	}{
+		{
+			name:  "new sizes get new variants with the size stock",
+			color: "red",
+			sizes: []SizeOptions{{Size: "S", Stock: 5}, {Size: "M", Stock: 2}},
+			want: []want{
+				{attributes: map[string]string{"size": "S", "color": "red"}, stock: 5},
+				{attributes: map[string]string{"size": "M", "color": "red"}, stock: 2},
+			},
+		},
+		{
+			name:     "existing variant keeps its id and stock and follows the color",
+			color:    "green",
+			sizes:    []SizeOptions{{Size: "M", Stock: 99}},
+			existing: []Variant{m},
+			want: []want{
+				{id: m.ID, attributes: map[string]string{"size": "M", "color": "green"}, stock: 4},
+			},
+		},
+		{
+			name:     "size with several variants keeps them all unchanged",
+			color:    "green",
+			sizes:    []SizeOptions{{Size: "L", Stock: 0}},
+			existing: []Variant{m, lBlue, lRed},
+			want: []want{
+				{id: lBlue.ID, attributes: map[string]string{"size": "L", "color": "blue"}, stock: 1},
+				{id: lRed.ID, attributes: map[string]string{"size": "L", "color": "red"}, stock: 2},
+			},
+		},
+		{
+			name:     "duplicate size",
+			sizes:    []SizeOptions{{Size: "M"}, {Size: "M"}},
+			existing: []Variant{m},
+			wantErr:  true,
+		},
+		{
+			name:     "stored variant without a size",
+			sizes:    []SizeOptions{{Size: "M"}},
+			existing: []Variant{m, noSize},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deriveVariants(productID, tt.color, tt.sizes, tt.existing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deriveVariants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("deriveVariants() returned %d variants, want %d", len(got), len(tt.want))
+			}
+
+			for i, w := range tt.want {
+				if !w.id.IsZero() && got[i].ID != w.id {
+					t.Errorf("variant %d id = %s, want %s", i, got[i].ID.Hex(), w.id.Hex())
+				}
+				if w.id.IsZero() && (got[i].ID.IsZero() || got[i].SKU == "") {
+					t.Errorf("variant %d has no id or SKU", i)
+				}
+				if !reflect.DeepEqual(got[i].Attributes, w.attributes) {
+					t.Errorf("variant %d attributes = %v, want %v", i, got[i].Attributes, w.attributes)
+				}
+				if got[i].Stock != w.stock {
+					t.Errorf("variant %d stock = %d, want %d", i, got[i].Stock, w.stock)
+				}
+			}
+		})
+	}
+
+	// The stored variants must not be changed in place
+	if m.Attributes["color"] != "red" {
+		t.Errorf("existing variant color changed to %s", m.Attributes["color"])
+	}
+}
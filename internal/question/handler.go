@@ -0,0 +1,157 @@
+package question
+
+import (
+	"fmt"
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuestionHandler struct {
+	QuestionService QuestionService
+}
+
+func NewQuestionHandler(questionService QuestionService) *QuestionHandler {
+	return &QuestionHandler{QuestionService: questionService}
+}
+
+func (h *QuestionHandler) CreateQuestion(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.QuestionService.CreateQuestion(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", nil)
+
+}
+
+func (h *QuestionHandler) GetAllQuestions(c *gin.Context) {
+
+	var filter QuestionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if filter.ProductID == "" {
+		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("product_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	questions, err := h.QuestionService.GetAllQuestions(c, &filter)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", questions)
+
+}
+
+func (h *QuestionHandler) GetQuestionByID(c *gin.Context) {
+
+	question, err := h.QuestionService.GetQuestionByID(c, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", question)
+
+}
+
+func (h *QuestionHandler) DeleteQuestion(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.QuestionService.DeleteQuestion(c, userID, c.Param("id"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (h *QuestionHandler) AnswerQuestion(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AnswerQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.QuestionService.AnswerQuestion(c, userID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", nil)
+
+}
+
+func (h *QuestionHandler) DeleteAnswer(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.QuestionService.DeleteAnswer(c, userID, c.Param("id"), c.Param("answer_id"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (h *QuestionHandler) UpvoteAnswer(c *gin.Context) {
+	h.setUpvote(c, true)
+}
+
+func (h *QuestionHandler) RemoveUpvote(c *gin.Context) {
+	h.setUpvote(c, false)
+}
+
+func (h *QuestionHandler) setUpvote(c *gin.Context, upvote bool) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.QuestionService.UpvoteAnswer(c, userID, c.Param("id"), c.Param("answer_id"), upvote)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
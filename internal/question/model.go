@@ -0,0 +1,28 @@
+package question
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Question struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Question  string             `json:"question" bson:"question"`
+	Answers   []Answer           `json:"answers" bson:"answers"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type Answer struct {
+	ID            primitive.ObjectID   `json:"id" bson:"id"`
+	UserID        primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	Answer        string               `json:"answer" bson:"answer"`
+	IsStaff       bool                 `json:"is_staff" bson:"is_staff"`
+	VerifiedBuyer bool                 `json:"verified_buyer" bson:"verified_buyer"`
+	Upvotes       []primitive.ObjectID `json:"upvotes" bson:"upvotes"`
+	CreatedAt     time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,157 @@
+package question
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type QuestionRepository interface {
+	Create(ctx context.Context, question *Question) error
+	FindAll(ctx context.Context, productID primitive.ObjectID, filter *QuestionFilter) ([]*Question, int64, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*Question, error)
+	DeleteByID(ctx context.Context, id primitive.ObjectID) error
+	PushAnswer(ctx context.Context, id primitive.ObjectID, answer Answer) error
+	PullAnswer(ctx context.Context, id primitive.ObjectID, answerID primitive.ObjectID) error
+	SetUpvote(ctx context.Context, id primitive.ObjectID, answerID primitive.ObjectID, userID primitive.ObjectID, upvote bool) error
+}
+
+type questionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewQuestionRepository(collection *mongo.Collection) QuestionRepository {
+	return &questionRepository{collection: collection}
+}
+
+func (r *questionRepository) Create(ctx context.Context, question *Question) error {
+	_, err := r.collection.InsertOne(ctx, question)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *questionRepository) FindAll(ctx context.Context, productID primitive.ObjectID, filter *QuestionFilter) ([]*Question, int64, error) {
+
+	var questions []*Question
+
+	query := bson.M{"product_id": productID}
+
+	if filter.Search != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
+		query["$or"] = bson.A{
+			bson.M{"question": pattern},
+			bson.M{"answers.answer": pattern},
+		}
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "created_at", Value: -1}})
+	opts.SetSkip(int64((filter.Page - 1) * filter.Limit))
+	opts.SetLimit(int64(filter.Limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &questions); err != nil {
+		return nil, 0, err
+	}
+
+	return questions, total, nil
+
+}
+
+func (r *questionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*Question, error) {
+
+	var question Question
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&question)
+	if err != nil {
+		return nil, err
+	}
+
+	return &question, nil
+
+}
+
+func (r *questionRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *questionRepository) PushAnswer(ctx context.Context, id primitive.ObjectID, answer Answer) error {
+
+	update := bson.M{
+		"$push": bson.M{"answers": answer},
+		"$set":  bson.M{"updated_at": answer.CreatedAt},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("question not found")
+	}
+
+	return nil
+
+}
+
+func (r *questionRepository) PullAnswer(ctx context.Context, id primitive.ObjectID, answerID primitive.ObjectID) error {
+
+	update := bson.M{"$pull": bson.M{"answers": bson.M{"id": answerID}}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "answers.id": answerID}, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("answer not found")
+	}
+
+	return nil
+
+}
+
+func (r *questionRepository) SetUpvote(ctx context.Context, id primitive.ObjectID, answerID primitive.ObjectID, userID primitive.ObjectID, upvote bool) error {
+
+	filter := bson.M{"_id": id, "answers.id": answerID}
+
+	update := bson.M{"$pull": bson.M{"answers.$.upvotes": userID}}
+	if upvote {
+		update = bson.M{"$addToSet": bson.M{"answers.$.upvotes": userID}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("answer not found")
+	}
+
+	return nil
+
+}
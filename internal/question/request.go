@@ -0,0 +1,17 @@
+package question
+
+type CreateQuestionRequest struct {
+	ProductID string `json:"product_id" bson:"product_id"`
+	Question  string `json:"question" bson:"question"`
+}
+
+type AnswerQuestionRequest struct {
+	Answer string `json:"answer" bson:"answer"`
+}
+
+type QuestionFilter struct {
+	ProductID string `form:"product_id"`
+	Search    string `form:"q"`
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+}
@@ -0,0 +1,37 @@
+package question
+
+import (
+	"modular_monolith/internal/reviews"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type QuestionsResponse struct {
+	Questions  []*QuestionResponse `json:"questions" bson:"questions"`
+	Page       int                 `json:"page" bson:"page"`
+	Limit      int                 `json:"limit" bson:"limit"`
+	Total      int64               `json:"total" bson:"total"`
+	TotalPages int64               `json:"total_pages" bson:"total_pages"`
+}
+
+type QuestionResponse struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Question  string             `json:"question" bson:"question"`
+	UserInfo  reviews.UserInfo   `json:"user_info" bson:"user_info"`
+	Answers   []*AnswerResponse  `json:"answers" bson:"answers"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type AnswerResponse struct {
+	ID            primitive.ObjectID `json:"id" bson:"id"`
+	Answer        string             `json:"answer" bson:"answer"`
+	IsStaff       bool               `json:"is_staff" bson:"is_staff"`
+	VerifiedBuyer bool               `json:"verified_buyer" bson:"verified_buyer"`
+	UpvoteCount   int                `json:"upvote_count" bson:"upvote_count"`
+	UserInfo      reviews.UserInfo   `json:"user_info" bson:"user_info"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
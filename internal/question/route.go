@@ -0,0 +1,22 @@
+package question
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *QuestionHandler) {
+	questionGroup := r.Group("/api/v1/question")
+	{
+		questionGroup.GET("", handler.GetAllQuestions)
+		questionGroup.GET("/:id", handler.GetQuestionByID)
+		questionGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateQuestion)
+		questionGroup.DELETE("/:id", middleware.JWTAuthMiddleware(), handler.DeleteQuestion)
+
+		questionGroup.POST("/:id/answer", middleware.JWTAuthMiddleware(), handler.AnswerQuestion)
+		questionGroup.DELETE("/:id/answer/:answer_id", middleware.JWTAuthMiddleware(), handler.DeleteAnswer)
+		questionGroup.POST("/:id/answer/:answer_id/upvote", middleware.JWTAuthMiddleware(), handler.UpvoteAnswer)
+		questionGroup.DELETE("/:id/answer/:answer_id/upvote", middleware.JWTAuthMiddleware(), handler.RemoveUpvote)
+	}
+}
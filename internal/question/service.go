@@ -0,0 +1,355 @@
+package question
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/reviews"
+	"modular_monolith/internal/shared/ports"
+	"modular_monolith/internal/user"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type QuestionService interface {
+	CreateQuestion(ctx context.Context, userID string, req *CreateQuestionRequest) error
+	GetAllQuestions(ctx context.Context, filter *QuestionFilter) (*QuestionsResponse, error)
+	GetQuestionByID(ctx context.Context, id string) (*QuestionResponse, error)
+	DeleteQuestion(ctx context.Context, userID string, id string) error
+	AnswerQuestion(ctx context.Context, userID string, id string, req *AnswerQuestionRequest) error
+	DeleteAnswer(ctx context.Context, userID string, id string, answerID string) error
+	UpvoteAnswer(ctx context.Context, userID string, id string, answerID string, upvote bool) error
+}
+
+type questionService struct {
+	questionRepo QuestionRepository
+	userRepo     user.UserRepository
+	orderRepo    ports.OrderRepository
+	productRepo  product.ProductRepository
+}
+
+func NewQuestionService(questionRepo QuestionRepository, userRepo user.UserRepository, orderRepo ports.OrderRepository, productRepo product.ProductRepository) QuestionService {
+	return &questionService{
+		questionRepo: questionRepo,
+		userRepo:     userRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+	}
+}
+
+func (s *questionService) CreateQuestion(ctx context.Context, userID string, req *CreateQuestionRequest) error {
+
+	if req.ProductID == "" {
+		return fmt.Errorf("product_id is required")
+	}
+
+	if strings.TrimSpace(req.Question) == "" {
+		return fmt.Errorf("question is required")
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	objectProductID, err := primitive.ObjectIDFromHex(req.ProductID)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	p, err := s.productRepo.FindByID(ctx, objectProductID)
+	if err != nil {
+		return err
+	}
+
+	if p == nil || !p.IsVisible() {
+		return fmt.Errorf("product not found")
+	}
+
+	question := &Question{
+		ID:        primitive.NewObjectID(),
+		ProductID: objectProductID,
+		UserID:    objectUserID,
+		Question:  strings.TrimSpace(req.Question),
+		Answers:   []Answer{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	return s.questionRepo.Create(ctx, question)
+
+}
+
+func (s *questionService) GetAllQuestions(ctx context.Context, filter *QuestionFilter) (*QuestionsResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(filter.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	} else if filter.Limit > 50 {
+		filter.Limit = 50
+	}
+
+	questionList, total, err := s.questionRepo.FindAll(ctx, objectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[primitive.ObjectID]reviews.UserInfo{}
+	questions := []*QuestionResponse{}
+
+	for _, q := range questionList {
+
+		res, err := s.toQuestionResponse(ctx, q, users)
+		if err != nil {
+			return nil, err
+		}
+
+		questions = append(questions, res)
+	}
+
+	return &QuestionsResponse{
+		Questions:  questions,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+	}, nil
+}
+
+func (s *questionService) GetQuestionByID(ctx context.Context, id string) (*QuestionResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question id: %v", err)
+	}
+
+	question, err := s.questionRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toQuestionResponse(ctx, question, map[primitive.ObjectID]reviews.UserInfo{})
+}
+
+func (s *questionService) DeleteQuestion(ctx context.Context, userID string, id string) error {
+
+	author, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid question id: %v", err)
+	}
+
+	question, err := s.questionRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if question.UserID != author.ID && !author.IsStaff() {
+		return fmt.Errorf("you can only delete your own question")
+	}
+
+	return s.questionRepo.DeleteByID(ctx, objectID)
+}
+
+// AnswerQuestion accepts answers from staff and from customers who bought the
+// product.
+func (s *questionService) AnswerQuestion(ctx context.Context, userID string, id string, req *AnswerQuestionRequest) error {
+
+	if strings.TrimSpace(req.Answer) == "" {
+		return fmt.Errorf("answer is required")
+	}
+
+	author, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid question id: %v", err)
+	}
+
+	question, err := s.questionRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	answer := Answer{
+		ID:        primitive.NewObjectID(),
+		UserID:    author.ID,
+		Answer:    strings.TrimSpace(req.Answer),
+		IsStaff:   author.IsStaff(),
+		Upvotes:   []primitive.ObjectID{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if !answer.IsStaff {
+		order, err := s.orderRepo.FindPurchasedOrder(ctx, author.ID, question.ProductID)
+		if err != nil {
+			return err
+		}
+
+		if order == nil {
+			return fmt.Errorf("only staff or customers who purchased this product can answer")
+		}
+
+		answer.VerifiedBuyer = true
+	}
+
+	return s.questionRepo.PushAnswer(ctx, objectID, answer)
+}
+
+func (s *questionService) DeleteAnswer(ctx context.Context, userID string, id string, answerID string) error {
+
+	author, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid question id: %v", err)
+	}
+
+	answerObjID, err := primitive.ObjectIDFromHex(answerID)
+	if err != nil {
+		return fmt.Errorf("invalid answer id: %v", err)
+	}
+
+	question, err := s.questionRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range question.Answers {
+		if a.ID != answerObjID {
+			continue
+		}
+
+		if a.UserID != author.ID && !author.IsStaff() {
+			return fmt.Errorf("you can only delete your own answer")
+		}
+
+		return s.questionRepo.PullAnswer(ctx, objectID, answerObjID)
+	}
+
+	return fmt.Errorf("answer not found")
+}
+
+func (s *questionService) UpvoteAnswer(ctx context.Context, userID string, id string, answerID string, upvote bool) error {
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid question id: %v", err)
+	}
+
+	answerObjID, err := primitive.ObjectIDFromHex(answerID)
+	if err != nil {
+		return fmt.Errorf("invalid answer id: %v", err)
+	}
+
+	return s.questionRepo.SetUpvote(ctx, objectID, answerObjID, userObjID, upvote)
+}
+
+func (s *questionService) findUser(ctx context.Context, userID string) (*user.User, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	existingUser, err := s.userRepo.FindByUserID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return existingUser, nil
+}
+
+func (s *questionService) toQuestionResponse(ctx context.Context, question *Question, users map[primitive.ObjectID]reviews.UserInfo) (*QuestionResponse, error) {
+
+	author, err := s.userInfo(ctx, question.UserID, users)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := []*AnswerResponse{}
+
+	for _, a := range question.Answers {
+
+		answerAuthor, err := s.userInfo(ctx, a.UserID, users)
+		if err != nil {
+			return nil, err
+		}
+
+		answers = append(answers, &AnswerResponse{
+			ID:            a.ID,
+			Answer:        a.Answer,
+			IsStaff:       a.IsStaff,
+			VerifiedBuyer: a.VerifiedBuyer,
+			UpvoteCount:   len(a.Upvotes),
+			UserInfo:      answerAuthor,
+			CreatedAt:     a.CreatedAt,
+			UpdatedAt:     a.UpdatedAt,
+		})
+	}
+
+	// Most upvoted answers first, staff answers win ties
+	sort.SliceStable(answers, func(i, j int) bool {
+		if answers[i].UpvoteCount != answers[j].UpvoteCount {
+			return answers[i].UpvoteCount > answers[j].UpvoteCount
+		}
+		return answers[i].IsStaff && !answers[j].IsStaff
+	})
+
+	return &QuestionResponse{
+		ID:        question.ID,
+		ProductID: question.ProductID,
+		Question:  question.Question,
+		UserInfo:  author,
+		Answers:   answers,
+		CreatedAt: question.CreatedAt,
+		UpdatedAt: question.UpdatedAt,
+	}, nil
+}
+
+func (s *questionService) userInfo(ctx context.Context, userID primitive.ObjectID, users map[primitive.ObjectID]reviews.UserInfo) (reviews.UserInfo, error) {
+
+	if info, ok := users[userID]; ok {
+		return info, nil
+	}
+
+	info, err := reviews.AuthorInfo(ctx, s.userRepo, userID)
+	if err != nil {
+		return reviews.UserInfo{}, err
+	}
+
+	users[userID] = info
+
+	return info, nil
+}
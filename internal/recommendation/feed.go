@@ -0,0 +1,305 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"modular_monolith/internal/product"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// viewHalfLife is how long it takes a view to count half as much
+	viewHalfLife = 14 * 24 * time.Hour
+
+	// Category weights of a wished product and of a purchased order line,
+	// compared to one fresh view
+	wishlistWeight = 3.0
+	purchaseWeight = 2.0
+
+	// feedCategories is how many favourite categories feed candidates come from
+	feedCategories = 5
+	feedCandidates = 200
+
+	historyViews = 100
+)
+
+func parseViewer(userID string, guestToken string) (*primitive.ObjectID, error) {
+
+	if len(guestToken) > maxGuestTokenLength {
+		return nil, fmt.Errorf("guest token must be at most %d characters", maxGuestTokenLength)
+	}
+
+	if userID == "" {
+		return nil, nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	return &id, nil
+}
+
+func (s *recommendationService) GetRecentlyViewed(ctx context.Context, userID string, guestToken string, req *RecentlyViewedRequest) ([]*product.ProductResponse, error) {
+
+	viewerID, err := parseViewer(userID, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	} else if req.Limit > 50 {
+		req.Limit = 50
+	}
+
+	// A product can show up twice when viewed both as guest and signed in
+	views, err := s.repository.FindRecentViews(ctx, viewerID, guestToken, req.Limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	var ids []primitive.ObjectID
+	for _, view := range views {
+		if !seen[view.ProductID] {
+			seen[view.ProductID] = true
+			ids = append(ids, view.ProductID)
+		}
+	}
+
+	products, err := s.productService.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) > req.Limit {
+		products = products[:req.Limit]
+	}
+
+	return products, nil
+}
+
+// GetFeed ranks products by the viewer's interest in their category, then by
+// sales and rating. Viewers without history get bestsellers.
+func (s *recommendationService) GetFeed(ctx context.Context, userID string, guestToken string, req *FeedRequest) (*FeedResponse, error) {
+
+	viewerID, err := parseViewer(userID, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	} else if req.Limit > 50 {
+		req.Limit = 50
+	}
+
+	affinity, purchased, err := s.categoryAffinity(ctx, viewerID, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[primitive.ObjectID]*product.Product)
+
+	if len(affinity) > 0 {
+		products, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{
+			CategoryIDs: favouriteCategories(affinity),
+			Sort:        "rating-desc",
+			Limit:       feedCandidates,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range products {
+			candidates[p.ID] = p
+		}
+	}
+
+	bestsellers, err := s.repository.FindBestsellers(ctx, feedCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	sold := make(map[primitive.ObjectID]int)
+	maxSold := 0
+
+	var bestsellerIDs []primitive.ObjectID
+	for _, b := range bestsellers {
+		sold[b.ProductID] = b.PurchaseCount
+		maxSold = max(maxSold, b.PurchaseCount)
+		bestsellerIDs = append(bestsellerIDs, b.ProductID)
+	}
+
+	products, err := s.productRepository.FindByIDs(ctx, bestsellerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range products {
+		if p.IsVisible() {
+			candidates[p.ID] = p
+		}
+	}
+
+	// Nothing sold yet: fall back to the best rated products
+	if len(candidates) == 0 {
+		products, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{Sort: "rating-desc", Limit: feedCandidates})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range products {
+			candidates[p.ID] = p
+		}
+	}
+
+	maxAffinity := 0.0
+	for _, weight := range affinity {
+		maxAffinity = math.Max(maxAffinity, weight)
+	}
+
+	var ranked []ScoredProduct
+
+	for id, p := range candidates {
+		if purchased[id] {
+			continue
+		}
+
+		score := p.RatingAverage / 5 * 0.5
+		if maxAffinity > 0 {
+			score += 3 * affinity[p.CategoryID] / maxAffinity
+		}
+		if maxSold > 0 {
+			score += float64(sold[id]) / float64(maxSold)
+		}
+
+		ranked = append(ranked, ScoredProduct{ProductID: id, Score: score})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ProductID.Hex() < ranked[j].ProductID.Hex()
+	})
+
+	total := int64(len(ranked))
+
+	start := min((req.Page-1)*req.Limit, len(ranked))
+	end := min(start+req.Limit, len(ranked))
+
+	var ids []primitive.ObjectID
+	for _, scored := range ranked[start:end] {
+		ids = append(ids, scored.ProductID)
+	}
+
+	responses, err := s.productService.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FeedResponse{
+		Products: responses,
+		Pagination: product.Pagination{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: (total + int64(req.Limit) - 1) / int64(req.Limit),
+		},
+		Personalized: len(affinity) > 0,
+	}
+
+	return result, nil
+}
+
+// categoryAffinity weighs the categories of the products the viewer viewed,
+// wished and bought, and returns the products already bought.
+func (s *recommendationService) categoryAffinity(ctx context.Context, userID *primitive.ObjectID, guestToken string) (map[primitive.ObjectID]float64, map[primitive.ObjectID]bool, error) {
+
+	weights := make(map[primitive.ObjectID]float64)
+	purchased := make(map[primitive.ObjectID]bool)
+
+	views, err := s.repository.FindRecentViews(ctx, userID, guestToken, historyViews)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	for _, view := range views {
+		decay := math.Pow(0.5, float64(now.Sub(view.LastViewedAt))/float64(viewHalfLife))
+		weights[view.ProductID] += float64(view.ViewCount) * decay
+	}
+
+	if userID != nil {
+		wished, err := s.wishlistRepository.FindByUserID(ctx, *userID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, item := range wished {
+			weights[item.ProductID] += wishlistWeight
+		}
+
+		orders, err := s.orderRepository.FindPurchasedByUserID(ctx, *userID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, order := range orders {
+			for _, item := range order.OrderItems {
+				weights[item.ProductID] += purchaseWeight
+				purchased[item.ProductID] = true
+			}
+		}
+	}
+
+	var ids []primitive.ObjectID
+	for id := range weights {
+		ids = append(ids, id)
+	}
+
+	// Hidden products still tell which categories the viewer likes
+	products, err := s.productRepository.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	affinity := make(map[primitive.ObjectID]float64)
+	for _, p := range products {
+		affinity[p.CategoryID] += weights[p.ID]
+	}
+
+	return affinity, purchased, nil
+}
+
+func favouriteCategories(affinity map[primitive.ObjectID]float64) []primitive.ObjectID {
+
+	var ids []primitive.ObjectID
+	for id := range affinity {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if affinity[ids[i]] != affinity[ids[j]] {
+			return affinity[ids[i]] > affinity[ids[j]]
+		}
+		return ids[i].Hex() < ids[j].Hex()
+	})
+
+	if len(ids) > feedCategories {
+		ids = ids[:feedCategories]
+	}
+
+	return ids
+}
@@ -0,0 +1,97 @@
+package recommendation
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	RecommendationService RecommendationService
+}
+
+func NewRecommendationHandler(recommendationService RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		RecommendationService: recommendationService,
+	}
+}
+
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+
+	var req RecommendationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	recommendations, err := h.RecommendationService.GetRecommendations(c, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", recommendations)
+}
+
+// RecordView tracks a product page view.
+func (h *RecommendationHandler) RecordView(c *gin.Context) {
+
+	userID, guestToken := viewer(c)
+
+	err := h.RecommendationService.RecordView(c, c.Param("id"), userID, guestToken)
+	if err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *RecommendationHandler) GetRecentlyViewed(c *gin.Context) {
+
+	var req RecentlyViewedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, guestToken := viewer(c)
+
+	products, err := h.RecommendationService.GetRecentlyViewed(c, userID, guestToken, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", products)
+}
+
+func (h *RecommendationHandler) GetFeed(c *gin.Context) {
+
+	var req FeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, guestToken := viewer(c)
+
+	feed, err := h.RecommendationService.GetFeed(c, userID, guestToken, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", feed)
+}
+
+// viewer identifies who is browsing: signed in users by their token, guests
+// by the X-Guest-Token header kept by the client.
+func viewer(c *gin.Context) (string, string) {
+
+	userID, _ := c.Get("user_id")
+	userIDStr, _ := userID.(string)
+
+	return userIDStr, c.GetHeader("X-Guest-Token")
+}
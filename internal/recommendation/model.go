@@ -0,0 +1,33 @@
+package recommendation
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductView counts the views of one product by a signed in user or a guest.
+type ProductView struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	ProductID     primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	UserID        *primitive.ObjectID `json:"user_id" bson:"user_id"`
+	GuestToken    string              `json:"guest_token" bson:"guest_token"`
+	ViewCount     int                 `json:"view_count" bson:"view_count"`
+	FirstViewedAt time.Time           `json:"first_viewed_at" bson:"first_viewed_at"`
+	LastViewedAt  time.Time           `json:"last_viewed_at" bson:"last_viewed_at"`
+}
+
+// Recommendation holds the precomputed recommendations of one product.
+type Recommendation struct {
+	ProductID      primitive.ObjectID `json:"product_id" bson:"_id"`
+	BoughtTogether []ScoredProduct    `json:"bought_together" bson:"bought_together"`
+	AlsoViewed     []ScoredProduct    `json:"also_viewed" bson:"also_viewed"`
+	Similar        []ScoredProduct    `json:"similar" bson:"similar"`
+	PurchaseCount  int                `json:"purchase_count" bson:"purchase_count"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type ScoredProduct struct {
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Score     float64            `json:"score" bson:"score"`
+}
@@ -0,0 +1,195 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxViewerProducts caps how many products of one viewer are paired up when
+// computing "customers also viewed".
+const maxViewerProducts = 50
+
+type RecommendationRepository interface {
+	RecordView(ctx context.Context, productID primitive.ObjectID, userID *primitive.ObjectID, guestToken string, at time.Time) error
+	FindViewedTogether(ctx context.Context, since time.Time) ([][]primitive.ObjectID, error)
+	FindRecentViews(ctx context.Context, userID *primitive.ObjectID, guestToken string, limit int) ([]*ProductView, error)
+	FindBestsellers(ctx context.Context, limit int) ([]*Recommendation, error)
+	FindByProductID(ctx context.Context, productID primitive.ObjectID) (*Recommendation, error)
+	Save(ctx context.Context, recommendation *Recommendation) error
+	DeleteOlderThan(ctx context.Context, before time.Time) error
+}
+
+type recommendationRepository struct {
+	views           *mongo.Collection
+	recommendations *mongo.Collection
+}
+
+func NewRecommendationRepository(views *mongo.Collection, recommendations *mongo.Collection) RecommendationRepository {
+	return &recommendationRepository{
+		views:           views,
+		recommendations: recommendations,
+	}
+}
+
+// RecordView keeps one document per viewer and product so repeated views only
+// bump the counter.
+func (r *recommendationRepository) RecordView(ctx context.Context, productID primitive.ObjectID, userID *primitive.ObjectID, guestToken string, at time.Time) error {
+
+	filter := bson.M{"product_id": productID}
+	onInsert := bson.M{"first_viewed_at": at}
+
+	if userID != nil {
+		filter["user_id"] = *userID
+		onInsert["guest_token"] = guestToken
+	} else {
+		filter["user_id"] = nil
+		filter["guest_token"] = guestToken
+	}
+
+	update := bson.M{
+		"$inc":         bson.M{"view_count": 1},
+		"$set":         bson.M{"last_viewed_at": at},
+		"$setOnInsert": onInsert,
+	}
+
+	_, err := r.views.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindViewedTogether returns, per viewer active since the given time, the
+// products they viewed. Viewers with a single product are left out.
+func (r *recommendationRepository) FindViewedTogether(ctx context.Context, since time.Time) ([][]primitive.ObjectID, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"last_viewed_at": bson.M{"$gte": since}}}},
+		{{Key: "$sort", Value: bson.M{"last_viewed_at": -1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$ifNull": bson.A{"$user_id", "$guest_token"}},
+			"products": bson.M{"$push": "$product_id"},
+		}}},
+		{{Key: "$match", Value: bson.M{"products.1": bson.M{"$exists": true}}}},
+		{{Key: "$project", Value: bson.M{"products": bson.M{"$slice": bson.A{"$products", maxViewerProducts}}}}},
+	}
+
+	cursor, err := r.views.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Products []primitive.ObjectID `bson:"products"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	var sessions [][]primitive.ObjectID
+	for _, result := range results {
+		sessions = append(sessions, result.Products)
+	}
+
+	return sessions, nil
+}
+
+// FindRecentViews returns the latest views of the user, of the guest token, or
+// of both when a guest signed in on the same device.
+func (r *recommendationRepository) FindRecentViews(ctx context.Context, userID *primitive.ObjectID, guestToken string, limit int) ([]*ProductView, error) {
+
+	var viewers bson.A
+	if userID != nil {
+		viewers = append(viewers, bson.M{"user_id": *userID})
+	}
+	if guestToken != "" {
+		viewers = append(viewers, bson.M{"user_id": nil, "guest_token": guestToken})
+	}
+
+	if len(viewers) == 0 {
+		return nil, nil
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_viewed_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.views.Find(ctx, bson.M{"$or": viewers}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []*ProductView
+	if err := cursor.All(ctx, &views); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+// FindBestsellers returns the products sold most during the purchase window,
+// as counted by the last refresh.
+func (r *recommendationRepository) FindBestsellers(ctx context.Context, limit int) ([]*Recommendation, error) {
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "purchase_count", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"purchase_count": 1})
+
+	cursor, err := r.recommendations.Find(ctx, bson.M{"purchase_count": bson.M{"$gt": 0}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var bestsellers []*Recommendation
+	if err := cursor.All(ctx, &bestsellers); err != nil {
+		return nil, err
+	}
+
+	return bestsellers, nil
+}
+
+func (r *recommendationRepository) FindByProductID(ctx context.Context, productID primitive.ObjectID) (*Recommendation, error) {
+
+	var recommendation *Recommendation
+
+	err := r.recommendations.FindOne(ctx, bson.M{"_id": productID}).Decode(&recommendation)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return recommendation, nil
+}
+
+func (r *recommendationRepository) Save(ctx context.Context, recommendation *Recommendation) error {
+
+	filter := bson.M{"_id": recommendation.ProductID}
+
+	_, err := r.recommendations.ReplaceOne(ctx, filter, recommendation, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteOlderThan removes recommendations the last refresh did not rewrite,
+// i.e. those of products that are no longer visible.
+func (r *recommendationRepository) DeleteOlderThan(ctx context.Context, before time.Time) error {
+
+	_, err := r.recommendations.DeleteMany(ctx, bson.M{"updated_at": bson.M{"$lt": before}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
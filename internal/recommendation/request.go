@@ -0,0 +1,14 @@
+package recommendation
+
+type RecommendationRequest struct {
+	Limit int `form:"limit"`
+}
+
+type RecentlyViewedRequest struct {
+	Limit int `form:"limit"`
+}
+
+type FeedRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
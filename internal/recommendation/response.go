@@ -0,0 +1,17 @@
+package recommendation
+
+import "modular_monolith/internal/product"
+
+type RecommendationsResponse struct {
+	BoughtTogether []*product.ProductResponse `json:"frequently_bought_together"`
+	Similar        []*product.ProductResponse `json:"similar_products"`
+	AlsoViewed     []*product.ProductResponse `json:"customers_also_viewed"`
+}
+
+type FeedResponse struct {
+	Products   []*product.ProductResponse `json:"products"`
+	Pagination product.Pagination         `json:"pagination"`
+	// Personalized is false when the viewer has no history yet and the feed
+	// only holds bestsellers
+	Personalized bool `json:"personalized"`
+}
@@ -0,0 +1,17 @@
+package recommendation
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *RecommendationHandler) {
+	productGroup := r.Group("/api/v1/product")
+	{
+		productGroup.GET("/recently-viewed", middleware.OptionalJWTAuthMiddleware(), handler.GetRecentlyViewed)
+		productGroup.GET("/feed", middleware.OptionalJWTAuthMiddleware(), handler.GetFeed)
+		productGroup.GET("/:id/recommendations", handler.GetRecommendations)
+		productGroup.POST("/:id/view", middleware.OptionalJWTAuthMiddleware(), handler.RecordView)
+	}
+}
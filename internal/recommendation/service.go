@@ -0,0 +1,339 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/ports"
+	"modular_monolith/internal/wishlist"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// purchaseWindow and viewWindow bound how far back orders and views count
+	purchaseWindow = 180 * 24 * time.Hour
+	viewWindow     = 90 * 24 * time.Hour
+
+	// maxRecommendations is how many products are kept per list
+	maxRecommendations = 20
+
+	// priceBand is how far apart two prices can be and still count as similar
+	priceBand = 0.25
+
+	maxGuestTokenLength = 64
+)
+
+type RecommendationService interface {
+	RecordView(ctx context.Context, productID string, userID string, guestToken string) error
+	GetRecommendations(ctx context.Context, productID string, req *RecommendationRequest) (*RecommendationsResponse, error)
+	CronRefreshRecommendations(ctx context.Context) error
+	GetRecentlyViewed(ctx context.Context, userID string, guestToken string, req *RecentlyViewedRequest) ([]*product.ProductResponse, error)
+	GetFeed(ctx context.Context, userID string, guestToken string, req *FeedRequest) (*FeedResponse, error)
+}
+
+type recommendationService struct {
+	repository         RecommendationRepository
+	productRepository  product.ProductRepository
+	productService     product.ProductService
+	orderRepository    ports.OrderRepository
+	wishlistRepository wishlist.WishlistRepository
+}
+
+func NewRecommendationService(repository RecommendationRepository, productRepository product.ProductRepository, productService product.ProductService, orderRepository ports.OrderRepository, wishlistRepository wishlist.WishlistRepository) RecommendationService {
+	return &recommendationService{
+		repository:         repository,
+		productRepository:  productRepository,
+		productService:     productService,
+		orderRepository:    orderRepository,
+		wishlistRepository: wishlistRepository,
+	}
+}
+
+func (s *recommendationService) RecordView(ctx context.Context, productID string, userID string, guestToken string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	if userID == "" && guestToken == "" {
+		return fmt.Errorf("user or guest token is required")
+	}
+
+	viewerID, err := parseViewer(userID, guestToken)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.productRepository.FindByID(ctx, objectID)
+	if err != nil || p == nil || !p.IsVisible() {
+		return fmt.Errorf("product not found")
+	}
+
+	return s.repository.RecordView(ctx, objectID, viewerID, guestToken, time.Now())
+}
+
+func (s *recommendationService) GetRecommendations(ctx context.Context, productID string, req *RecommendationRequest) (*RecommendationsResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 8
+	} else if req.Limit > maxRecommendations {
+		req.Limit = maxRecommendations
+	}
+
+	recommendation, err := s.repository.FindByProductID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Products added since the last refresh only get similar products
+	if recommendation == nil {
+		recommendation, err = s.fallbackRecommendation(ctx, objectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &RecommendationsResponse{}
+
+	lists := []struct {
+		scored []ScoredProduct
+		target *[]*product.ProductResponse
+	}{
+		{recommendation.BoughtTogether, &result.BoughtTogether},
+		{recommendation.Similar, &result.Similar},
+		{recommendation.AlsoViewed, &result.AlsoViewed},
+	}
+
+	for _, list := range lists {
+		var ids []primitive.ObjectID
+		for _, scored := range list.scored {
+			ids = append(ids, scored.ProductID)
+		}
+
+		// Fetch the whole list as products hidden since the refresh are skipped
+		products, err := s.productService.GetProductsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(products) > req.Limit {
+			products = products[:req.Limit]
+		}
+
+		*list.target = products
+	}
+
+	return result, nil
+}
+
+func (s *recommendationService) fallbackRecommendation(ctx context.Context, productID primitive.ObjectID) (*Recommendation, error) {
+
+	target, err := s.productRepository.FindByID(ctx, productID)
+	if err != nil || target == nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	candidates, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{
+		CategoryIDs: []primitive.ObjectID{target.CategoryID},
+		Sort:        "rating-desc",
+		Limit:       100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recommendation{
+		ProductID: productID,
+		Similar:   similarProducts(target, candidates),
+	}, nil
+}
+
+// CronRefreshRecommendations recomputes the recommendations of every visible
+// product from recent orders, views and the catalog.
+func (s *recommendationService) CronRefreshRecommendations(ctx context.Context) error {
+
+	started := time.Now()
+
+	products, _, err := s.productRepository.FindAll(ctx, &product.ProductFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to load products: %w", err)
+	}
+
+	orders, err := s.orderRepository.FindPurchasedSince(ctx, started.Add(-purchaseWindow))
+	if err != nil {
+		return fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	var baskets [][]primitive.ObjectID
+	sold := make(map[primitive.ObjectID]int)
+
+	for _, order := range orders {
+		var basket []primitive.ObjectID
+		for _, item := range order.OrderItems {
+			basket = append(basket, item.ProductID)
+			sold[item.ProductID] += item.Quantity
+		}
+		baskets = append(baskets, basket)
+	}
+
+	viewed, err := s.repository.FindViewedTogether(ctx, started.Add(-viewWindow))
+	if err != nil {
+		return fmt.Errorf("failed to load product views: %w", err)
+	}
+
+	boughtTogether := coOccurrence(baskets)
+	alsoViewed := coOccurrence(viewed)
+
+	visible := make(map[primitive.ObjectID]bool)
+	for _, p := range products {
+		visible[p.ID] = true
+	}
+
+	for _, p := range products {
+
+		recommendation := &Recommendation{
+			ProductID:      p.ID,
+			BoughtTogether: topProducts(boughtTogether[p.ID], visible),
+			AlsoViewed:     topProducts(alsoViewed[p.ID], visible),
+			Similar:        similarProducts(p, products),
+			PurchaseCount:  sold[p.ID],
+			UpdatedAt:      started,
+		}
+
+		if err := s.repository.Save(ctx, recommendation); err != nil {
+			return fmt.Errorf("failed to save recommendations of product %s: %w", p.ID.Hex(), err)
+		}
+	}
+
+	return s.repository.DeleteOlderThan(ctx, started)
+}
+
+// coOccurrence scores every pair of products found in the same group by the
+// cosine similarity of the groups they appear in.
+func coOccurrence(groups [][]primitive.ObjectID) map[primitive.ObjectID]map[primitive.ObjectID]float64 {
+
+	counts := make(map[primitive.ObjectID]int)
+	pairs := make(map[primitive.ObjectID]map[primitive.ObjectID]int)
+
+	for _, group := range groups {
+
+		seen := make(map[primitive.ObjectID]bool)
+		var distinct []primitive.ObjectID
+		for _, id := range group {
+			if !seen[id] {
+				seen[id] = true
+				distinct = append(distinct, id)
+			}
+		}
+
+		for i, a := range distinct {
+			counts[a]++
+
+			for _, b := range distinct[i+1:] {
+				if pairs[a] == nil {
+					pairs[a] = make(map[primitive.ObjectID]int)
+				}
+				if pairs[b] == nil {
+					pairs[b] = make(map[primitive.ObjectID]int)
+				}
+				pairs[a][b]++
+				pairs[b][a]++
+			}
+		}
+	}
+
+	scores := make(map[primitive.ObjectID]map[primitive.ObjectID]float64)
+
+	for a, others := range pairs {
+		scores[a] = make(map[primitive.ObjectID]float64)
+		for b, together := range others {
+			scores[a][b] = float64(together) / math.Sqrt(float64(counts[a]*counts[b]))
+		}
+	}
+
+	return scores
+}
+
+// similarProducts scores candidates by shared category, color and price band.
+// A candidate needs the same category, or the same color and price band.
+func similarProducts(target *product.Product, candidates []*product.Product) []ScoredProduct {
+
+	scores := make(map[primitive.ObjectID]float64)
+
+	for _, candidate := range candidates {
+
+		if candidate.ID == target.ID {
+			continue
+		}
+
+		score := 0.0
+
+		if candidate.CategoryID == target.CategoryID {
+			score += 2
+		}
+
+		if target.Color != "" && strings.EqualFold(candidate.Color, target.Color) {
+			score++
+		}
+
+		if samePriceBand(candidate.CurrentPrice(), target.CurrentPrice()) {
+			score++
+		}
+
+		if score < 2 {
+			continue
+		}
+
+		// Better rated products win ties
+		scores[candidate.ID] = score + candidate.RatingAverage/10
+	}
+
+	return topProducts(scores, nil)
+}
+
+func samePriceBand(a, b float64) bool {
+
+	if a <= 0 || b <= 0 {
+		return false
+	}
+
+	return math.Min(a, b)/math.Max(a, b) >= 1-priceBand
+}
+
+// topProducts returns the best scored products, limited to the allowed ones
+// when allowed is not nil.
+func topProducts(scores map[primitive.ObjectID]float64, allowed map[primitive.ObjectID]bool) []ScoredProduct {
+
+	var top []ScoredProduct
+
+	for id, score := range scores {
+		if allowed != nil && !allowed[id] {
+			continue
+		}
+		top = append(top, ScoredProduct{ProductID: id, Score: score})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Score != top[j].Score {
+			return top[i].Score > top[j].Score
+		}
+		return top[i].ProductID.Hex() < top[j].ProductID.Hex()
+	})
+
+	if len(top) > maxRecommendations {
+		top = top[:maxRecommendations]
+	}
+
+	return top
+}
@@ -0,0 +1,55 @@
+package recommendation
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCoOccurrence(t *testing.T) {
+
+	boots := primitive.NewObjectID()
+	socks := primitive.NewObjectID()
+	shirt := primitive.NewObjectID()
+	ball := primitive.NewObjectID()
+
+	groups := [][]primitive.ObjectID{
+		{boots, socks},
+		{boots, socks, socks},
+		{boots, shirt},
+		{boots},
+		{ball},
+	}
+
+	scores := coOccurrence(groups)
+
+	tests := []struct {
+		name string
+		a, b primitive.ObjectID
+		want float64
+	}{
+		// boots in 4 groups, socks in 2, together in 2; duplicates count once
+		{"boots with socks", boots, socks, 2 / math.Sqrt(8)},
+		{"socks with boots", socks, boots, 2 / math.Sqrt(8)},
+		// shirt in 1 group, together with boots in 1
+		{"shirt with boots", shirt, boots, 1 / math.Sqrt(4)},
+		{"never together", socks, shirt, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scores[tt.a][tt.b]; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := scores[ball]; ok {
+		t.Errorf("product bought alone should have no pairs")
+	}
+
+	if _, ok := scores[boots][boots]; ok {
+		t.Errorf("product should not pair with itself")
+	}
+}
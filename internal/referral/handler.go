@@ -0,0 +1,32 @@
+package referral
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReferralHandler struct {
+	ReferralService ReferralService
+}
+
+func NewReferralHandler(referralService ReferralService) *ReferralHandler {
+	return &ReferralHandler{
+		ReferralService: referralService,
+	}
+}
+
+func (h *ReferralHandler) GetReferralStats(c *gin.Context) {
+
+	userID := c.Param("user_id")
+
+	stats, err := h.ReferralService.GetReferralStats(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", stats)
+
+}
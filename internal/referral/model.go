@@ -0,0 +1,32 @@
+package referral
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReferralStatus string
+
+const (
+	Registered ReferralStatus = "registered"
+	Rewarding  ReferralStatus = "rewarding"
+	Completed  ReferralStatus = "completed"
+	Rejected   ReferralStatus = "rejected"
+)
+
+type Referral struct {
+	ID                 primitive.ObjectID  `json:"id" bson:"_id"`
+	ReferrerID         primitive.ObjectID  `json:"referrer_id" bson:"referrer_id"`
+	RefereeID          primitive.ObjectID  `json:"referee_id" bson:"referee_id"`
+	ReferralCode       string              `json:"referral_code" bson:"referral_code"`
+	Status             ReferralStatus      `json:"status" bson:"status"`
+	RejectReason       *string             `json:"reject_reason" bson:"reject_reason"`
+	RefereeIP          string              `json:"-" bson:"referee_ip"`
+	OrderID            *primitive.ObjectID `json:"order_id" bson:"order_id"`
+	ReferrerCouponCode *string             `json:"referrer_coupon_code" bson:"referrer_coupon_code"`
+	RefereeCouponCode  *string             `json:"referee_coupon_code" bson:"referee_coupon_code"`
+	CompletedAt        *time.Time          `json:"completed_at" bson:"completed_at"`
+	CreatedAt          time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" bson:"updated_at"`
+}
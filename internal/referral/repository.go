@@ -0,0 +1,131 @@
+package referral
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReferralRepository interface {
+	Create(ctx context.Context, referral *Referral) error
+	FindByRefereeID(ctx context.Context, refereeID primitive.ObjectID) (*Referral, error)
+	FindByReferrerID(ctx context.Context, referrerID primitive.ObjectID) ([]*Referral, error)
+	CountByReferrerAndIP(ctx context.Context, referrerID primitive.ObjectID, ip string) (int64, error)
+	MarkRewarding(ctx context.Context, id primitive.ObjectID, orderID primitive.ObjectID) (bool, error)
+	MarkCompleted(ctx context.Context, id primitive.ObjectID) error
+	UpdateCoupon(ctx context.Context, id primitive.ObjectID, field string, code string) error
+}
+
+type referralRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReferralRepository(collection *mongo.Collection) ReferralRepository {
+	return &referralRepository{collection: collection}
+}
+
+func (r *referralRepository) Create(ctx context.Context, referral *Referral) error {
+	_, err := r.collection.InsertOne(ctx, referral)
+	return err
+}
+
+func (r *referralRepository) FindByRefereeID(ctx context.Context, refereeID primitive.ObjectID) (*Referral, error) {
+
+	var referral Referral
+
+	err := r.collection.FindOne(ctx, bson.M{"referee_id": refereeID}).Decode(&referral)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &referral, nil
+}
+
+func (r *referralRepository) FindByReferrerID(ctx context.Context, referrerID primitive.ObjectID) ([]*Referral, error) {
+
+	var referrals []*Referral
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"referrer_id": referrerID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &referrals); err != nil {
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+func (r *referralRepository) CountByReferrerAndIP(ctx context.Context, referrerID primitive.ObjectID, ip string) (int64, error) {
+
+	filter := bson.M{
+		"referrer_id": referrerID,
+		"referee_ip":  ip,
+	}
+
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// MarkRewarding only moves a referral out of the registered state once, so
+// concurrent order updates cannot grant the reward twice.
+func (r *referralRepository) MarkRewarding(ctx context.Context, id primitive.ObjectID, orderID primitive.ObjectID) (bool, error) {
+
+	filter := bson.M{
+		"_id":    id,
+		"status": Registered,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     Rewarding,
+			"order_id":   orderID,
+			"updated_at": time.Now(),
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *referralRepository) MarkCompleted(ctx context.Context, id primitive.ObjectID) error {
+
+	now := time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":       Completed,
+			"completed_at": now,
+			"updated_at":   now,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": Rewarding}, update)
+	return err
+}
+
+func (r *referralRepository) UpdateCoupon(ctx context.Context, id primitive.ObjectID, field string, code string) error {
+
+	update := bson.M{
+		"$set": bson.M{
+			field:        code,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
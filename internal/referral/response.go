@@ -0,0 +1,11 @@
+package referral
+
+type ReferralStatsResponse struct {
+	ReferralCode   string      `json:"referral_code"`
+	TotalReferrals int         `json:"total_referrals"`
+	Completed      int         `json:"completed"`
+	Pending        int         `json:"pending"`
+	Rejected       int         `json:"rejected"`
+	RewardCoupons  []string    `json:"reward_coupons"`
+	Referrals      []*Referral `json:"referrals"`
+}
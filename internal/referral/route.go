@@ -0,0 +1,12 @@
+package referral
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *ReferralHandler) {
+	referralGroup := r.Group("/api/v1/referral")
+	{
+		referralGroup.GET("/:user_id", handler.GetReferralStats)
+	}
+}
@@ -0,0 +1,304 @@
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"modular_monolith/config"
+	"modular_monolith/internal/coupon"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/user"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReferralService interface {
+	GenerateReferralCode(ctx context.Context) (string, error)
+	TrackRegistration(ctx context.Context, refereeID primitive.ObjectID, referralCode string, clientIP string) error
+	HandleOrderStatus(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, status string) error
+	GetReferralStats(ctx context.Context, userID string) (*ReferralStatsResponse, error)
+}
+
+type referralService struct {
+	referralRepository ReferralRepository
+	userRepository     user.UserRepository
+	couponService      coupon.CouponService
+	config             config.ReferralConfig
+}
+
+// Public mailbox domains are shared by unrelated people.
+var publicEmailDomains = map[string]bool{
+	"gmail.com":   true,
+	"yahoo.com":   true,
+	"outlook.com": true,
+	"hotmail.com": true,
+	"icloud.com":  true,
+}
+
+func NewReferralService(referralRepository ReferralRepository, userRepository user.UserRepository, couponService coupon.CouponService, config config.ReferralConfig) ReferralService {
+	return &referralService{
+		referralRepository: referralRepository,
+		userRepository:     userRepository,
+		couponService:      couponService,
+		config:             config,
+	}
+}
+
+func (s *referralService) GenerateReferralCode(ctx context.Context) (string, error) {
+
+	const maxAttempts = 5
+
+	for i := 0; i < maxAttempts; i++ {
+
+		code, err := s.randomCode(8)
+		if err != nil {
+			return "", err
+		}
+
+		existing, err := s.userRepository.FindByReferralCode(ctx, code)
+		if err != nil {
+			return "", err
+		}
+
+		if existing == nil {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not generate unique referral code after %d attempts", maxAttempts)
+}
+
+func (s *referralService) randomCode(length int) (string, error) {
+
+	charset := "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+
+	return string(b), nil
+}
+
+func (s *referralService) TrackRegistration(ctx context.Context, refereeID primitive.ObjectID, referralCode string, clientIP string) error {
+
+	referralCode = strings.ToUpper(strings.TrimSpace(referralCode))
+	if referralCode == "" {
+		return fmt.Errorf("referral code is required")
+	}
+
+	referrer, err := s.userRepository.FindByReferralCode(ctx, referralCode)
+	if err != nil {
+		return err
+	}
+
+	if referrer == nil {
+		return fmt.Errorf("invalid referral code")
+	}
+
+	referee, err := s.userRepository.FindByUserID(ctx, refereeID)
+	if err != nil {
+		return err
+	}
+
+	if referee == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	existing, err := s.referralRepository.FindByRefereeID(ctx, refereeID)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return fmt.Errorf("user has already been referred")
+	}
+
+	reason, err := s.detectFraud(ctx, referrer, referee, clientIP)
+	if err != nil {
+		return err
+	}
+
+	referral := &Referral{
+		ID:           primitive.NewObjectID(),
+		ReferrerID:   referrer.ID,
+		RefereeID:    referee.ID,
+		ReferralCode: referralCode,
+		Status:       Registered,
+		RefereeIP:    clientIP,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if reason != "" {
+		referral.Status = Rejected
+		referral.RejectReason = &reason
+	}
+
+	return s.referralRepository.Create(ctx, referral)
+}
+
+func (s *referralService) detectFraud(ctx context.Context, referrer *user.User, referee *user.User, clientIP string) (string, error) {
+
+	if referrer.ID == referee.ID {
+		return "self referral", nil
+	}
+
+	if clientIP != "" {
+		if referrer.RegisterIP == clientIP {
+			return "same ip address as referrer", nil
+		}
+
+		count, err := s.referralRepository.CountByReferrerAndIP(ctx, referrer.ID, clientIP)
+		if err != nil {
+			return "", err
+		}
+
+		if count > 0 {
+			return "ip address already used for another referral", nil
+		}
+	}
+
+	referrerDomain := emailDomain(referrer.Email)
+	if referrerDomain != "" && !publicEmailDomains[referrerDomain] && referrerDomain == emailDomain(referee.Email) {
+		return "same email domain as referrer", nil
+	}
+
+	return "", nil
+}
+
+func emailDomain(email string) string {
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+// HandleOrderStatus rewards both sides of a referral the first time the
+// referred user has an order completed. The referral stays rewarding until
+// both coupons are granted, so a failed attempt is finished by the next one.
+func (s *referralService) HandleOrderStatus(ctx context.Context, userID primitive.ObjectID, orderID primitive.ObjectID, status string) error {
+
+	if status != string(model.OrderPaid) && status != string(model.PaymentSuccess) {
+		return nil
+	}
+
+	referral, err := s.referralRepository.FindByRefereeID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if referral == nil || (referral.Status != Registered && referral.Status != Rewarding) {
+		return nil
+	}
+
+	if referral.Status == Registered {
+		claimed, err := s.referralRepository.MarkRewarding(ctx, referral.ID, orderID)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			return nil
+		}
+	}
+
+	expiredAt := time.Now().AddDate(0, 0, s.config.RewardValidDays)
+
+	if referral.ReferrerCouponCode == nil {
+		coupon, err := s.couponService.GrantPrivateCoupon(ctx, "Referral reward", s.config.RewardDiscount, []primitive.ObjectID{referral.ReferrerID}, expiredAt)
+		if err != nil {
+			return fmt.Errorf("failed to grant referrer coupon: %w", err)
+		}
+
+		if err := s.referralRepository.UpdateCoupon(ctx, referral.ID, "referrer_coupon_code", coupon.CodeCoupon); err != nil {
+			return err
+		}
+	}
+
+	if referral.RefereeCouponCode == nil {
+		coupon, err := s.couponService.GrantPrivateCoupon(ctx, "Welcome referral reward", s.config.RewardDiscount, []primitive.ObjectID{referral.RefereeID}, expiredAt)
+		if err != nil {
+			return fmt.Errorf("failed to grant referee coupon: %w", err)
+		}
+
+		if err := s.referralRepository.UpdateCoupon(ctx, referral.ID, "referee_coupon_code", coupon.CodeCoupon); err != nil {
+			return err
+		}
+	}
+
+	return s.referralRepository.MarkCompleted(ctx, referral.ID)
+}
+
+func (s *referralService) GetReferralStats(ctx context.Context, userID string) (*ReferralStatsResponse, error) {
+
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user_id: %v", err)
+	}
+
+	existingUser, err := s.userRepository.FindByUserID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	// Users registered before the referral program have no code yet
+	if existingUser.ReferralCode == "" {
+		code, err := s.GenerateReferralCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.userRepository.UpdateByID(ctx, objectID, bson.M{"referral_code": code}); err != nil {
+			return nil, err
+		}
+
+		existingUser.ReferralCode = code
+	}
+
+	referrals, err := s.referralRepository.FindByReferrerID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ReferralStatsResponse{
+		ReferralCode:   existingUser.ReferralCode,
+		TotalReferrals: len(referrals),
+		RewardCoupons:  []string{},
+		Referrals:      referrals,
+	}
+
+	for _, referral := range referrals {
+		switch referral.Status {
+		case Completed:
+			stats.Completed++
+			if referral.ReferrerCouponCode != nil {
+				stats.RewardCoupons = append(stats.RewardCoupons, *referral.ReferrerCouponCode)
+			}
+		case Registered, Rewarding:
+			stats.Pending++
+		case Rejected:
+			stats.Rejected++
+		}
+	}
+
+	return stats, nil
+}
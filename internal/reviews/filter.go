@@ -0,0 +1,92 @@
+package reviews
+
+import (
+	"modular_monolith/config"
+	"regexp"
+	"strings"
+)
+
+type FilterVerdict string
+
+const (
+	FilterClean   FilterVerdict = "clean"
+	FilterFlagged FilterVerdict = "flagged"
+	FilterBlocked FilterVerdict = "blocked"
+)
+
+var (
+	linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// contentFilter blocks profanity outright and flags spam-looking reviews for
+// a moderator to look at.
+type contentFilter struct {
+	bannedWords map[string]bool
+	maxLinks    int
+}
+
+func newContentFilter(cfg config.ReviewConfig) *contentFilter {
+
+	banned := make(map[string]bool, len(cfg.BannedWords))
+	for _, w := range cfg.BannedWords {
+		banned[strings.ToLower(w)] = true
+	}
+
+	return &contentFilter{
+		bannedWords: banned,
+		maxLinks:    cfg.MaxLinks,
+	}
+}
+
+func (f *contentFilter) Check(text string) (FilterVerdict, string) {
+
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if f.bannedWords[word] {
+			return FilterBlocked, "contains prohibited language"
+		}
+	}
+
+	if len(linkPattern.FindAllString(text, -1)) > f.maxLinks {
+		return FilterFlagged, "contains links"
+	}
+
+	if hasRepeatedRun(text, 8) {
+		return FilterFlagged, "contains repeated characters"
+	}
+
+	letters, upper := 0, 0
+	for _, r := range text {
+		if r >= 'a' && r <= 'z' {
+			letters++
+		} else if r >= 'A' && r <= 'Z' {
+			letters++
+			upper++
+		}
+	}
+
+	if letters >= 20 && upper*10 >= letters*8 {
+		return FilterFlagged, "mostly uppercase"
+	}
+
+	return FilterClean, ""
+}
+
+func hasRepeatedRun(text string, length int) bool {
+
+	var prev rune
+	run := 0
+
+	for _, r := range text {
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run >= length {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,8 @@ package reviews
 
 import (
 	"fmt"
+	"io"
+	"mime/multipart"
 	"modular_monolith/helper"
 	"net/http"
 
@@ -17,15 +19,25 @@ func NewReviewHandler(reviewService ReviewService) *ReviewHandler {
 }
 
 func (r *ReviewHandler) CreateReview(c *gin.Context) {
-	
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req CreateReviewRequest
-	
-	if err := c.ShouldBindJSON(&req); err != nil {
+
+	if err := c.ShouldBind(&req); err != nil {
 		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 		return
 	}
 
-	err := r.ReviewService.CreateReview(c, &req)
+	var files []*multipart.FileHeader
+	if c.Request.MultipartForm != nil {
+		files = c.Request.MultipartForm.File["media"]
+	}
+
+	err := r.ReviewService.CreateReview(c, userID, &req, files)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -37,39 +49,48 @@ func (r *ReviewHandler) CreateReview(c *gin.Context) {
 
 func (r *ReviewHandler) GetAllReviews(c *gin.Context) {
 
-	productID := c.Query("product_id")
+	var filter ReviewFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
 
-	if productID == "" {
+	if filter.ProductID == "" {
 		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("product_id is required"), helper.ErrInvalidRequest)
 		return
 	}
 
-	reviews, err := r.ReviewService.GetAllReviews(c, productID)
+	reviews, err := r.ReviewService.GetAllReviews(c, &filter)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", reviews)
-	
+
 }
 
 func (r *ReviewHandler) GetReviewByID(c *gin.Context) {
-	
+
 	id := c.Param("id")
 
 	review, err := r.ReviewService.GetReviewByID(c, id)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
-	}	
+	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", review)
-	
+
 }
 
 func (r *ReviewHandler) UpdateReview(c *gin.Context) {
 
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 	if id == "" {
 		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
@@ -83,7 +104,7 @@ func (r *ReviewHandler) UpdateReview(c *gin.Context) {
 		return
 	}
 
-	err := r.ReviewService.UpdateReview(c, id, &req)
+	err := r.ReviewService.UpdateReview(c, userID, id, &req)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -95,9 +116,14 @@ func (r *ReviewHandler) UpdateReview(c *gin.Context) {
 
 func (r *ReviewHandler) DeleteReview(c *gin.Context) {
 
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 
-	err := r.ReviewService.DeleteReview(c, id)
+	err := r.ReviewService.DeleteReview(c, userID, id)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -108,6 +134,11 @@ func (r *ReviewHandler) DeleteReview(c *gin.Context) {
 
 func (r *ReviewHandler) LikeReview(c *gin.Context) {
 
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 
 	if id == "" {
@@ -122,7 +153,99 @@ func (r *ReviewHandler) LikeReview(c *gin.Context) {
 		return
 	}
 
-	err := r.ReviewService.LikeReview(c, &req, id)
+	err := r.ReviewService.LikeReview(c, userID, id, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) GetModerationQueue(c *gin.Context) {
+
+	moderatorID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter ModerationFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	reviews, err := r.ReviewService.GetModerationQueue(c, moderatorID, &filter)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", reviews)
+
+}
+
+func (r *ReviewHandler) ApproveReview(c *gin.Context) {
+
+	moderatorID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.ApproveReview(c, moderatorID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) RejectReview(c *gin.Context) {
+
+	moderatorID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.RejectReview(c, moderatorID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) ReportReview(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReportReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.ReportReview(c, userID, c.Param("id"), &req)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -130,4 +253,124 @@ func (r *ReviewHandler) LikeReview(c *gin.Context) {
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
 
-}
\ No newline at end of file
+}
+
+func (r *ReviewHandler) AddReviewMedia(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.AddReviewMedia(c, userID, c.Param("id"), c.Request.MultipartForm.File["media"])
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) DeleteReviewMedia(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := r.ReviewService.DeleteReviewMedia(c, userID, c.Param("id"), c.Query("public_id"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) GetCustomerMedia(c *gin.Context) {
+
+	productID := c.Query("product_id")
+	if productID == "" {
+		helper.SendError(c, http.StatusBadRequest, fmt.Errorf("product_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	media, err := r.ReviewService.GetCustomerMedia(c, productID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", media)
+
+}
+
+func (r *ReviewHandler) ReplyReview(c *gin.Context) {
+
+	staffID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.ReplyReview(c, staffID, c.Param("id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", nil)
+
+}
+
+func (r *ReviewHandler) UpdateReply(c *gin.Context) {
+
+	staffID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := r.ReviewService.UpdateReply(c, staffID, c.Param("id"), c.Param("reply_id"), &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (r *ReviewHandler) DeleteReply(c *gin.Context) {
+
+	staffID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := r.ReviewService.DeleteReply(c, staffID, c.Param("id"), c.Param("reply_id"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
@@ -6,15 +6,35 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
 type Reviews struct {
-	ID         primitive.ObjectID `json:"id" bson:"_id"`
-	ProductID  primitive.ObjectID `json:"product_id" bson:"product_id"`
-	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Rating     int                `json:"rating" bson:"rating"`
-	Review     string             `json:"review" bson:"review"`
-	LikeReview []LikeReview       `json:"like_review" bson:"like_review"`
-	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+	ID               primitive.ObjectID  `json:"id" bson:"_id"`
+	ProductID        primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	UserID           primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	OrderID          primitive.ObjectID  `json:"order_id" bson:"order_id"`
+	Rating           int                 `json:"rating" bson:"rating"`
+	Review           string              `json:"review" bson:"review"`
+	Size             string              `json:"size" bson:"size"`
+	VerifiedPurchase bool                `json:"verified_purchase" bson:"verified_purchase"`
+	LikeReview       []LikeReview        `json:"like_review" bson:"like_review"`
+	DislikeReview    []LikeReview        `json:"dislike_review" bson:"dislike_review"`
+	Media            []ReviewMedia       `json:"media" bson:"media"`
+	Replies          []ReviewReply       `json:"replies" bson:"replies"`
+	Status           ReviewStatus        `json:"status" bson:"status"`
+	ModerationReason *string             `json:"moderation_reason" bson:"moderation_reason"`
+	ModeratedBy      *primitive.ObjectID `json:"moderated_by" bson:"moderated_by"`
+	ModeratedAt      *time.Time          `json:"moderated_at" bson:"moderated_at"`
+	Reports          []ReviewReport      `json:"reports" bson:"reports"`
+	Hidden           bool                `json:"hidden" bson:"hidden"`
+	CreatedAt        time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" bson:"updated_at"`
 }
 
 type LikeReview struct {
@@ -22,3 +42,24 @@ type LikeReview struct {
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 }
+
+type ReviewReport struct {
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Reason    string             `json:"reason" bson:"reason"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type ReviewMedia struct {
+	Url       string    `json:"url" bson:"url"`
+	PublicID  string    `json:"public_id" bson:"public_id"`
+	Type      string    `json:"type" bson:"type"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+type ReviewReply struct {
+	ID        primitive.ObjectID `json:"id" bson:"id"`
+	StaffID   primitive.ObjectID `json:"staff_id" bson:"staff_id"`
+	Message   string             `json:"message" bson:"message"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
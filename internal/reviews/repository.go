@@ -2,6 +2,9 @@ package reviews
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,9 +14,21 @@ import (
 
 type ReviewRepository interface {
 	Create(ctx context.Context, review *Reviews) error
-	FindAll(ctx context.Context, productID primitive.ObjectID) ([]*Reviews, error)
+	FindAll(ctx context.Context, productID primitive.ObjectID, filter *ReviewFilter) ([]*Reviews, int64, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*Reviews, error)
-	UpdateByID(ctx context.Context, id primitive.ObjectID, req *UpdateReviewRequest) error
+	FindByUserAndProduct(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (*Reviews, error)
+	EnsureUserProductIndex(ctx context.Context) error
+	UpdateFields(ctx context.Context, id primitive.ObjectID, fields bson.M) error
+	Vote(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID, add string, remove string) error
+	PushMedia(ctx context.Context, id primitive.ObjectID, media []ReviewMedia, maxMedia int) error
+	PullMedia(ctx context.Context, id primitive.ObjectID, publicID string) error
+	PushReply(ctx context.Context, id primitive.ObjectID, reply ReviewReply) error
+	UpdateReply(ctx context.Context, id primitive.ObjectID, replyID primitive.ObjectID, message string) error
+	PullReply(ctx context.Context, id primitive.ObjectID, replyID primitive.ObjectID) error
+	FindForModeration(ctx context.Context, filter *ModerationFilter) ([]*Reviews, error)
+	AddReport(ctx context.Context, id primitive.ObjectID, report ReviewReport) (*Reviews, error)
+	CountVisibleByRating(ctx context.Context, productID primitive.ObjectID) (map[int]int, error)
+	FindReviewedProductIDs(ctx context.Context) ([]primitive.ObjectID, error)
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
 }
 
@@ -33,26 +48,167 @@ func (r *reviewRepository) Create(ctx context.Context, review *Reviews) error {
 	return nil
 }
 
-func (r *reviewRepository) FindAll(ctx context.Context, productID primitive.ObjectID) ([]*Reviews, error) {
+// FindAll returns one page of visible reviews together with the number of
+// reviews matching the filter. A zero Limit returns every match.
+func (r *reviewRepository) FindAll(ctx context.Context, productID primitive.ObjectID, filter *ReviewFilter) ([]*Reviews, int64, error) {
 
 	var reviews []*Reviews
-	filter := bson.M{
+
+	query := visibleFilter(productID)
+
+	if filter.HasMedia {
+		query["media.0"] = bson.M{"$exists": true}
+	}
+
+	if filter.Rating > 0 {
+		query["rating"] = filter.Rating
+	}
+
+	if filter.Verified != nil {
+		if *filter.Verified {
+			query["verified_purchase"] = true
+		} else {
+			query["verified_purchase"] = bson.M{"$ne": true}
+		}
+	}
+
+	if filter.Size != "" {
+		query["size"] = filter.Size
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: query}},
+	}
+
+	var sortQuery bson.D
+
+	switch filter.Sort {
+	case "rating-desc":
+		sortQuery = bson.D{{Key: "rating", Value: -1}, {Key: "created_at", Value: -1}}
+	case "rating-asc":
+		sortQuery = bson.D{{Key: "rating", Value: 1}, {Key: "created_at", Value: -1}}
+	case "helpful":
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{"helpful_score": helpfulScore()}}})
+		sortQuery = bson.D{{Key: "helpful_score", Value: -1}, {Key: "created_at", Value: -1}}
+	default:
+		sortQuery = bson.D{{Key: "created_at", Value: -1}}
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sortQuery}})
+
+	if filter.Limit > 0 {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$skip", Value: int64((filter.Page - 1) * filter.Limit)}},
+			bson.D{{Key: "$limit", Value: int64(filter.Limit)}},
+		)
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &reviews); err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+
+}
+
+const (
+	helpfulAgeOffset = 2
+	helpfulDecay     = 1.5
+)
+
+// helpfulness nets helpful against not helpful votes and decays the result
+// with the age of the review in days.
+func helpfulness(likes int, dislikes int, ageDays float64) float64 {
+	return float64(likes-dislikes) / math.Pow(ageDays+helpfulAgeOffset, helpfulDecay)
+}
+
+// helpfulScore computes helpfulness in the aggregation pipeline.
+func helpfulScore() bson.M {
+
+	votes := bson.M{"$subtract": bson.A{
+		bson.M{"$size": bson.M{"$ifNull": bson.A{"$like_review", bson.A{}}}},
+		bson.M{"$size": bson.M{"$ifNull": bson.A{"$dislike_review", bson.A{}}}},
+	}}
+
+	ageDays := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$$NOW", "$created_at"}},
+		1000 * 60 * 60 * 24,
+	}}
+
+	return bson.M{"$divide": bson.A{
+		votes,
+		bson.M{"$pow": bson.A{bson.M{"$add": bson.A{ageDays, helpfulAgeOffset}}, helpfulDecay}},
+	}}
+}
+
+// visibleFilter matches the reviews shown to customers. Reviews written before
+// moderation existed have no status and stay visible.
+func visibleFilter(productID primitive.ObjectID) bson.M {
+	return bson.M{
 		"product_id": productID,
+		"status":     bson.M{"$in": []interface{}{ReviewApproved, nil}},
+		"hidden":     bson.M{"$ne": true},
 	}
+}
 
-	opts := options.Find()
-	opts.SetSort(bson.D{{Key: "created_at", Value: -1}})
-	
-	cursor, err := r.collection.Find(ctx, filter, opts)
+func (r *reviewRepository) CountVisibleByRating(ctx context.Context, productID primitive.ObjectID) (map[int]int, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: visibleFilter(productID)}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$rating",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = cursor.All(ctx, &reviews); err != nil {
+	var results []struct {
+		Rating int `bson:"_id"`
+		Count  int `bson:"count"`
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
 
-	return reviews, nil
+	counts := make(map[int]int, len(results))
+	for _, res := range results {
+		counts[res.Rating] = res.Count
+	}
+
+	return counts, nil
+
+}
+
+func (r *reviewRepository) FindReviewedProductIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+
+	values, err := r.collection.Distinct(ctx, "product_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []primitive.ObjectID
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
 
 }
 
@@ -69,17 +225,232 @@ func (r *reviewRepository) FindByID(ctx context.Context, id primitive.ObjectID)
 
 }
 
-func (r *reviewRepository) UpdateByID(ctx context.Context, id primitive.ObjectID, req *UpdateReviewRequest) error {
+func (r *reviewRepository) FindByUserAndProduct(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (*Reviews, error) {
+
+	var review Reviews
+
+	filter := bson.M{
+		"user_id":    userID,
+		"product_id": productID,
+	}
+
+	err := r.collection.FindOne(ctx, filter).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &review, nil
+
+}
+
+// EnsureUserProductIndex allows one review per user and product.
+func (r *reviewRepository) EnsureUserProductIndex(ctx context.Context) error {
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "product_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+func (r *reviewRepository) UpdateFields(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Vote moves the vote of the user from the remove list to the add list in a
+// single update. Reviews written before voting have no vote arrays, which $push
+// and $pull cannot handle, so the update is a pipeline.
+func (r *reviewRepository) Vote(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID, add string, remove string) error {
+
+	votes := func(field string) bson.M {
+		return bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}}
+	}
+
+	set := bson.M{
+		remove: bson.M{"$filter": bson.M{
+			"input": votes(remove),
+			"cond":  bson.M{"$ne": bson.A{"$$this.user_id", userID}},
+		}},
+	}
+
+	if add != "" {
+		now := time.Now()
+		set[add] = bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{userID, bson.M{"$ifNull": bson.A{"$" + add + ".user_id", bson.A{}}}}},
+			votes(add),
+			bson.M{"$concatArrays": bson.A{votes(add), bson.A{LikeReview{UserID: userID, CreatedAt: now, UpdatedAt: now}}}},
+		}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, mongo.Pipeline{{{Key: "$set", Value: set}}})
+	return err
+}
+
+// PushMedia appends media as long as the review stays within maxMedia items.
+func (r *reviewRepository) PushMedia(ctx context.Context, id primitive.ObjectID, media []ReviewMedia, maxMedia int) error {
+
+	filter := bson.M{
+		"_id": id,
+		"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$media", bson.A{}}}}, len(media)}},
+			maxMedia,
+		}},
+	}
+
+	update := bson.M{"$push": bson.M{"media": bson.M{"$each": media}}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("a review can have at most %d photos or videos", maxMedia)
+	}
+
+	return nil
+
+}
+
+func (r *reviewRepository) PullMedia(ctx context.Context, id primitive.ObjectID, publicID string) error {
+
+	update := bson.M{"$pull": bson.M{"media": bson.M{"public_id": publicID}}}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *reviewRepository) PushReply(ctx context.Context, id primitive.ObjectID, reply ReviewReply) error {
+
+	update := bson.M{"$push": bson.M{"replies": reply}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("review not found")
+	}
+
+	return nil
+
+}
+
+func (r *reviewRepository) UpdateReply(ctx context.Context, id primitive.ObjectID, replyID primitive.ObjectID, message string) error {
+
+	filter := bson.M{"_id": id, "replies.id": replyID}
+	update := bson.M{"$set": bson.M{
+		"replies.$.message":    message,
+		"replies.$.updated_at": time.Now(),
+	}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("reply not found")
+	}
+
+	return nil
+
+}
+
+func (r *reviewRepository) PullReply(ctx context.Context, id primitive.ObjectID, replyID primitive.ObjectID) error {
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": req})
+	update := bson.M{"$pull": bson.M{"replies": bson.M{"id": replyID}}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "replies.id": replyID}, update)
 	if err != nil {
 		return err
 	}
 
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("reply not found")
+	}
+
 	return nil
 
 }
 
+func (r *reviewRepository) FindForModeration(ctx context.Context, filter *ModerationFilter) ([]*Reviews, error) {
+
+	var reviews []*Reviews
+
+	query := bson.M{}
+
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	if filter.Hidden != nil {
+		if *filter.Hidden {
+			query["hidden"] = true
+		} else {
+			query["hidden"] = bson.M{"$ne": true}
+		}
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+
+}
+
+// AddReport records the report unless the user already reported the review.
+func (r *reviewRepository) AddReport(ctx context.Context, id primitive.ObjectID, report ReviewReport) (*Reviews, error) {
+
+	filter := bson.M{
+		"_id":             id,
+		"reports.user_id": bson.M{"$ne": report.UserID},
+	}
+
+	update := bson.M{"$push": bson.M{"reports": report}}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var review Reviews
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("review not found or already reported")
+		}
+		return nil, err
+	}
+
+	return &review, nil
+
+}
+
 func (r *reviewRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
 
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
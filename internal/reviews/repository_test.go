@@ -0,0 +1,37 @@
+package reviews
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHelpfulness(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		higher float64
+		lower  float64
+	}{
+		{"helpful votes net against not helpful ones", helpfulness(5, 1, 1), helpfulness(5, 3, 1)},
+		{"recent review outranks an old one with the same votes", helpfulness(4, 0, 1), helpfulness(4, 0, 30)},
+		{"recent useful review outranks an old one with more votes", helpfulness(5, 0, 2), helpfulness(20, 0, 200)},
+		{"review without votes outranks a disliked one", helpfulness(0, 0, 1), helpfulness(0, 2, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.higher <= tt.lower {
+				t.Errorf("score %v should be above %v", tt.higher, tt.lower)
+			}
+		})
+	}
+
+	if got := helpfulness(0, 0, 10); got != 0 {
+		t.Errorf("helpfulness without votes = %v, want 0", got)
+	}
+
+	want := 6 / math.Pow(3, 1.5)
+	if got := helpfulness(7, 1, 1); math.Abs(got-want) > 1e-9 {
+		t.Errorf("helpfulness(7, 1, 1) = %v, want %v", got, want)
+	}
+}
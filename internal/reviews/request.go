@@ -1,19 +1,45 @@
 package reviews
 
 type CreateReviewRequest struct {
-	ProductID string `json:"product_id" bson:"product_id"`
-	UserID    string `json:"user_id" bson:"user_id"`
-	Rating    int    `json:"rating" bson:"rating"`
-	Review    string `json:"review" bson:"review"`
+	ProductID string `json:"product_id" form:"product_id" bson:"product_id"`
+	Rating    int    `json:"rating" form:"rating" bson:"rating"`
+	Review    string `json:"review" form:"review" bson:"review"`
+	Size      string `json:"size" form:"size" bson:"size"`
+}
+
+type ReviewFilter struct {
+	ProductID string `form:"product_id"`
+	HasMedia  bool   `form:"has_media"`
+	Rating    int    `form:"rating"`
+	Verified  *bool  `form:"verified"`
+	Size      string `form:"size"`
+	Sort      string `form:"sort"`
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
 }
 
 type UpdateReviewRequest struct {
-	Rating     int          `json:"rating" bson:"rating"`
-	Review     string       `json:"review" bson:"review"`
-	LikeReview []LikeReview `json:"like_review" bson:"like_review"`
+	Rating int    `json:"rating" bson:"rating"`
+	Review string `json:"review" bson:"review"`
+}
+
+type ModerateReviewRequest struct {
+	Reason string `json:"reason" bson:"reason"`
+}
+
+type ReplyReviewRequest struct {
+	Message string `json:"message" bson:"message"`
+}
+
+type ReportReviewRequest struct {
+	Reason string `json:"reason" bson:"reason"`
+}
+
+type ModerationFilter struct {
+	Status string `form:"status"`
+	Hidden *bool  `form:"hidden"`
 }
 
 type LikeReviewRequest struct {
-	Type   string `json:"type" bson:"type"`
-	UserID string `json:"user_id" bson:"user_id"`
+	Type string `json:"type" bson:"type"`
 }
@@ -9,31 +9,63 @@ import (
 type ReviewsResponse struct {
 	ReviewsResponse   []*ReviewResponse `json:"reviews" bson:"reviews"`
 	TotalReviewsCount int               `json:"total_reviews_count" bson:"total_reviews_count"`
-	Percent []PercentRating `json:"percent" bson:"percent"`
+	Percent           []PercentRating   `json:"percent" bson:"percent"`
+	Pagination        Pagination        `json:"pagination" bson:"pagination"`
 	AvarageRating     float64           `json:"average_rating" bson:"average_rating"`
 	CreatedAt         time.Time         `json:"created_at" bson:"created_at"`
 	UpdatedAt         time.Time         `json:"updated_at" bson:"updated_at"`
 }
 
+type Pagination struct {
+	Page       int   `json:"page" bson:"page"`
+	Limit      int   `json:"limit" bson:"limit"`
+	Total      int64 `json:"total" bson:"total"`
+	TotalPages int64 `json:"total_pages" bson:"total_pages"`
+}
+
 type PercentRating struct {
 	Percent string `json:"percent" bson:"percent"`
-	Rating int `json:"rating" bson:"rating"`
-	Count  int `json:"count" bson:"count"`
+	Rating  int    `json:"rating" bson:"rating"`
+	Count   int    `json:"count" bson:"count"`
 }
 type ReviewResponse struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id"`
-	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
-	Rating    int                `json:"rating" bson:"rating"`
-	Review    string             `json:"review" bson:"review"`
-	UserInfo  UserInfo           `json:"user_info" bson:"user_info"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID               primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID        primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Rating           int                `json:"rating" bson:"rating"`
+	Review           string             `json:"review" bson:"review"`
+	Size             string             `json:"size" bson:"size"`
+	VerifiedPurchase bool               `json:"verified_purchase" bson:"verified_purchase"`
+	Status           ReviewStatus       `json:"status" bson:"status"`
+	Media            []ReviewMedia      `json:"media" bson:"media"`
+	Replies          []ReviewReply      `json:"replies" bson:"replies"`
+	HelpfulCount     int                `json:"helpful_count" bson:"helpful_count"`
+	NotHelpfulCount  int                `json:"not_helpful_count" bson:"not_helpful_count"`
+	UserInfo         UserInfo           `json:"user_info" bson:"user_info"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
 type UserInfo struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id"`
 	FullName  string             `json:"full_name" bson:"full_name"`
-	Avatar    *string             `json:"avatar" bson:"avatar"`
+	Avatar    *string            `json:"avatar" bson:"avatar"`
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 }
+
+type ModerationReviewResponse struct {
+	ReviewResponse
+	ModerationReason *string             `json:"moderation_reason" bson:"moderation_reason"`
+	ModeratedBy      *primitive.ObjectID `json:"moderated_by" bson:"moderated_by"`
+	ModeratedAt      *time.Time          `json:"moderated_at" bson:"moderated_at"`
+	Reports          []ReviewReport      `json:"reports" bson:"reports"`
+	Hidden           bool                `json:"hidden" bson:"hidden"`
+}
+
+type CustomerMediaResponse struct {
+	ReviewID  primitive.ObjectID `json:"review_id" bson:"review_id"`
+	Rating    int                `json:"rating" bson:"rating"`
+	Url       string             `json:"url" bson:"url"`
+	Type      string             `json:"type" bson:"type"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -1,18 +1,34 @@
 package reviews
 
 import (
+	"modular_monolith/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRoutes(r *gin.Engine, handler *ReviewHandler) {
 	reviewGroup := r.Group("/api/v1/review")
 	{
-		reviewGroup.POST("", handler.CreateReview)
+		reviewGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateReview)
 		reviewGroup.GET("", handler.GetAllReviews)
+		reviewGroup.GET("/media", handler.GetCustomerMedia)
 		reviewGroup.GET("/:id", handler.GetReviewByID)
-		reviewGroup.PUT("/:id", handler.UpdateReview)
-		reviewGroup.DELETE("/:id", handler.DeleteReview)
+		reviewGroup.PUT("/:id", middleware.JWTAuthMiddleware(), handler.UpdateReview)
+		reviewGroup.DELETE("/:id", middleware.JWTAuthMiddleware(), handler.DeleteReview)
+
+		reviewGroup.POST("like/:id", middleware.JWTAuthMiddleware(), handler.LikeReview)
+		reviewGroup.POST("report/:id", middleware.JWTAuthMiddleware(), handler.ReportReview)
+		reviewGroup.POST("/:id/media", middleware.JWTAuthMiddleware(), handler.AddReviewMedia)
+		reviewGroup.DELETE("/:id/media", middleware.JWTAuthMiddleware(), handler.DeleteReviewMedia)
+		reviewGroup.POST("/:id/reply", middleware.JWTAuthMiddleware(), handler.ReplyReview)
+		reviewGroup.PUT("/:id/reply/:reply_id", middleware.JWTAuthMiddleware(), handler.UpdateReply)
+		reviewGroup.DELETE("/:id/reply/:reply_id", middleware.JWTAuthMiddleware(), handler.DeleteReply)
 
-		reviewGroup.POST("like/:id", handler.LikeReview)
+		moderationGroup := reviewGroup.Group("/moderation", middleware.JWTAuthMiddleware())
+		{
+			moderationGroup.GET("", handler.GetModerationQueue)
+			moderationGroup.POST("/:id/approve", handler.ApproveReview)
+			moderationGroup.POST("/:id/reject", handler.RejectReview)
+		}
 	}
 }
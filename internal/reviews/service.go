@@ -3,52 +3,86 @@ package reviews
 import (
 	"context"
 	"fmt"
+	"log"
+	"math"
+	"mime/multipart"
+	"modular_monolith/config"
+	"modular_monolith/helper"
+	"modular_monolith/internal/product"
+	"modular_monolith/internal/shared/model"
+	"modular_monolith/internal/shared/ports"
 	"modular_monolith/internal/user"
+	"modular_monolith/pkg/email"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ReviewService interface {
-	CreateReview(ctx context.Context, req *CreateReviewRequest) error
-	GetAllReviews(ctx context.Context, productID string) (*ReviewsResponse, error)
+	CreateReview(ctx context.Context, userID string, req *CreateReviewRequest, files []*multipart.FileHeader) error
+	GetAllReviews(ctx context.Context, filter *ReviewFilter) (*ReviewsResponse, error)
 	GetReviewByID(ctx context.Context, id string) (*ReviewResponse, error)
-	UpdateReview(ctx context.Context, id string, req *UpdateReviewRequest) error
-	DeleteReview(ctx context.Context, id string) error
-	LikeReview(ctx context.Context, req *LikeReviewRequest, id string) error
+	UpdateReview(ctx context.Context, userID string, id string, req *UpdateReviewRequest) error
+	DeleteReview(ctx context.Context, userID string, id string) error
+	LikeReview(ctx context.Context, userID string, id string, req *LikeReviewRequest) error
+	GetModerationQueue(ctx context.Context, moderatorID string, filter *ModerationFilter) ([]*ModerationReviewResponse, error)
+	ApproveReview(ctx context.Context, moderatorID string, id string, req *ModerateReviewRequest) error
+	RejectReview(ctx context.Context, moderatorID string, id string, req *ModerateReviewRequest) error
+	ReportReview(ctx context.Context, userID string, id string, req *ReportReviewRequest) error
+	SyncProductRatings(ctx context.Context) error
+	AddReviewMedia(ctx context.Context, userID string, id string, files []*multipart.FileHeader) error
+	DeleteReviewMedia(ctx context.Context, userID string, id string, publicID string) error
+	GetCustomerMedia(ctx context.Context, productID string) ([]*CustomerMediaResponse, error)
+	ReplyReview(ctx context.Context, staffID string, id string, req *ReplyReviewRequest) error
+	UpdateReply(ctx context.Context, staffID string, id string, replyID string, req *ReplyReviewRequest) error
+	DeleteReply(ctx context.Context, staffID string, id string, replyID string) error
 }
 
 type reviewService struct {
-	reviewRepo ReviewRepository
-	userRepo   user.UserRepository
+	reviewRepo   ReviewRepository
+	userRepo     user.UserRepository
+	orderRepo    ports.OrderRepository
+	productRepo  product.ProductRepository
+	uploader     *helper.CloudinaryUploader
+	emailService *email.EmailService
+	filter       *contentFilter
+	config       config.ReviewConfig
 }
 
-func NewReviewService(reviewRepo ReviewRepository, userRepo user.UserRepository) ReviewService {
+func NewReviewService(reviewRepo ReviewRepository, userRepo user.UserRepository, orderRepo ports.OrderRepository, productRepo product.ProductRepository, uploader *helper.CloudinaryUploader, config config.ReviewConfig) ReviewService {
 	return &reviewService{
-		reviewRepo: reviewRepo,
-		userRepo:   userRepo,
+		reviewRepo:   reviewRepo,
+		userRepo:     userRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		uploader:     uploader,
+		emailService: email.NewEmailService(),
+		filter:       newContentFilter(config),
+		config:       config,
 	}
 }
 
-func (r *reviewService) CreateReview(ctx context.Context, req *CreateReviewRequest) error {
+func (r *reviewService) CreateReview(ctx context.Context, userID string, req *CreateReviewRequest, files []*multipart.FileHeader) error {
 
 	if req.ProductID == "" {
 		return fmt.Errorf("product_id is required")
 	}
 
-	if req.UserID == "" {
-		return fmt.Errorf("user_id is required")
-	}
-
-	if req.Rating <= 0 {
-		return fmt.Errorf("rating must be greater than 0")
+	if req.Rating < 1 || req.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
 	}
 
 	if req.Review == "" {
 		return fmt.Errorf("review is required")
 	}
 
-	objectUserID, err := primitive.ObjectIDFromHex(req.UserID)
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return fmt.Errorf("invalid user id: %v", err)
 	}
@@ -58,85 +92,164 @@ func (r *reviewService) CreateReview(ctx context.Context, req *CreateReviewReque
 		return fmt.Errorf("invalid product id: %v", err)
 	}
 
+	existing, err := r.reviewRepo.FindByUserAndProduct(ctx, objectUserID, objectProductID)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return fmt.Errorf("you have already reviewed this product, please edit your review instead")
+	}
+
+	orders, err := r.orderRepo.FindPurchasedOrders(ctx, objectUserID, objectProductID)
+	if err != nil {
+		return err
+	}
+
+	if len(orders) == 0 {
+		return fmt.Errorf("only customers who purchased this product can review it")
+	}
+
+	order, size, err := r.purchasedSize(orders, objectProductID, req.Size)
+	if err != nil {
+		return err
+	}
+
 	review := &Reviews{
-		ID:        primitive.NewObjectID(),
-		ProductID: objectProductID,
-		UserID:    objectUserID,
-		Rating:    req.Rating,
-		Review:    req.Review,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:               primitive.NewObjectID(),
+		ProductID:        objectProductID,
+		UserID:           objectUserID,
+		OrderID:          order.ID,
+		Rating:           req.Rating,
+		Review:           req.Review,
+		Size:             size,
+		VerifiedPurchase: true,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	review.Status, review.ModerationReason = r.screen(req.Review)
+
+	if len(files) > r.config.MaxMedia {
+		return fmt.Errorf("a review can have at most %d photos or videos", r.config.MaxMedia)
 	}
 
-	return r.reviewRepo.Create(ctx, review)
+	media, err := r.uploadMedia(ctx, files)
+	if err != nil {
+		return err
+	}
+	review.Media = media
+
+	if err := r.reviewRepo.Create(ctx, review); err != nil {
+		r.deleteMedia(ctx, media)
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("you have already reviewed this product, please edit your review instead")
+		}
+		return err
+	}
+
+	r.refreshProductRating(ctx, objectProductID)
+
+	return nil
+
+}
+
+// screen runs the content filter and returns the status a new or edited
+// review starts with.
+func (r *reviewService) screen(text string) (ReviewStatus, *string) {
 
+	verdict, reason := r.filter.Check(text)
+
+	switch verdict {
+	case FilterBlocked:
+		return ReviewRejected, &reason
+	case FilterFlagged:
+		return ReviewPending, &reason
+	}
+
+	if r.config.AutoApprove {
+		return ReviewApproved, nil
+	}
+
+	return ReviewPending, nil
 }
 
-func (r *reviewService) GetAllReviews(ctx context.Context, productID string) (*ReviewsResponse, error) {
+// purchasedSize picks the size the user bought and the order it was bought
+// in. When several sizes were bought the requested one must be among them.
+func (r *reviewService) purchasedSize(orders []*model.Order, productID primitive.ObjectID, size string) (*model.Order, string, error) {
 
-	ratingCount := map[int]int{
-		1: 0,
-		2: 0,
-		3: 0,
-		4: 0,
-		5: 0,
+	for _, order := range orders {
+		for _, item := range order.OrderItems {
+			if item.ProductID == productID && (size == "" || item.Size == size) {
+				return order, item.Size, nil
+			}
+		}
 	}
 
+	return nil, "", fmt.Errorf("size %s was not purchased for this product", size)
+}
+
+func (r *reviewService) GetAllReviews(ctx context.Context, filter *ReviewFilter) (*ReviewsResponse, error) {
+
 	var percent []PercentRating
-	objectID, err := primitive.ObjectIDFromHex(productID)
+	objectID, err := primitive.ObjectIDFromHex(filter.ProductID)
 	if err != nil {
 		return nil, err
 	}
 
-	reviewList, err := r.reviewRepo.FindAll(ctx, objectID)
+	if filter.Rating < 0 || filter.Rating > 5 {
+		return nil, fmt.Errorf("rating must be between 1 and 5")
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	} else if filter.Limit > 50 {
+		filter.Limit = 50
+	}
+
+	reviewList, total, err := r.reviewRepo.FindAll(ctx, objectID, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var reviews []*ReviewResponse
-	totalRating := 0
+	reviews := []*ReviewResponse{}
 
 	for _, v := range reviewList {
 
-		totalRating += v.Rating
-
-		ratingCount[v.Rating]++
-
-		user, err := r.userRepo.FindByID(ctx, v.UserID)
+		reviewRes, err := r.toReviewResponse(ctx, v)
 		if err != nil {
 			return nil, err
 		}
 
-		var avatar *string
-		if user.Profile != nil {
-			avatar = &user.Profile.Avatar
-		}
+		reviews = append(reviews, reviewRes)
+	}
 
-		reviews = append(reviews, &ReviewResponse{
-			ID:        v.ID,
-			ProductID: v.ProductID,
-			Rating:    v.Rating,
-			Review:    v.Review,
-			UserInfo: UserInfo{
-				ID:       user.ID,
-				FullName: user.LastName + user.FirstName,
-				Avatar:   avatar,
-			},
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-		})
+	// The summary covers every visible review, not only the filtered page
+	ratingCount, err := r.reviewRepo.CountVisibleByRating(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalRating, totalCount := 0, 0
+	for i := 1; i <= 5; i++ {
+		totalRating += i * ratingCount[i]
+		totalCount += ratingCount[i]
 	}
 
 	average := 0.0
-	if len(reviewList) > 0 {
-		average = float64(totalRating) / float64(len(reviewList))
+	if totalCount > 0 {
+		average = float64(totalRating) / float64(totalCount)
 	}
 
 	for i := 1; i <= 5; i++ {
 		count := ratingCount[i]
 		percentValue := 0
-		if len(reviewList) > 0 {
-			percentValue = int(float64(count) / float64(len(reviewList)) * 100)
+		if totalCount > 0 {
+			percentValue = int(float64(count) / float64(totalCount) * 100)
 		}
 		percent = append(percent, PercentRating{
 			Rating:  i,
@@ -147,11 +260,17 @@ func (r *reviewService) GetAllReviews(ctx context.Context, productID string) (*R
 
 	reviewsRes := &ReviewsResponse{
 		ReviewsResponse:   reviews,
-		TotalReviewsCount: len(reviews),
+		TotalReviewsCount: totalCount,
 		AvarageRating:     average,
 		CreatedAt:         time.Now(),
 		Percent:           percent,
-		UpdatedAt:         time.Now(),
+		Pagination: Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		},
+		UpdatedAt: time.Now(),
 	}
 
 	return reviewsRes, nil
@@ -169,63 +288,160 @@ func (r *reviewService) GetReviewByID(ctx context.Context, id string) (*ReviewRe
 		return nil, err
 	}
 
-	user, err := r.userRepo.FindByID(ctx, review.UserID)
+	return r.toReviewResponse(ctx, review)
+}
+
+func (r *reviewService) toReviewResponse(ctx context.Context, review *Reviews) (*ReviewResponse, error) {
+
+	author, err := AuthorInfo(ctx, r.userRepo, review.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Reviews written before moderation existed were published right away
+	status := review.Status
+	if status == "" {
+		status = ReviewApproved
+	}
+
+	return &ReviewResponse{
+		ID:               review.ID,
+		ProductID:        review.ProductID,
+		Rating:           review.Rating,
+		Review:           review.Review,
+		Size:             review.Size,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Status:           status,
+		Media:            review.Media,
+		Replies:          review.Replies,
+		HelpfulCount:     len(review.LikeReview),
+		NotHelpfulCount:  len(review.DislikeReview),
+		UserInfo:         author,
+		CreatedAt:        review.CreatedAt,
+		UpdatedAt:        review.UpdatedAt,
+	}, nil
+}
+
+// AuthorInfo is the public profile shown next to reviews, questions and
+// answers.
+func AuthorInfo(ctx context.Context, userRepo user.UserRepository, userID primitive.ObjectID) (UserInfo, error) {
+
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
 	var avatar *string
 	if user.Profile != nil {
 		avatar = &user.Profile.Avatar
 	}
 
-	reviewRes := &ReviewResponse{
-		ID:        review.ID,
-		ProductID: review.ProductID,
-		Rating:    review.Rating,
-		Review:    review.Review,
-		UserInfo: UserInfo{
-			ID:       user.ID,
-			FullName: user.LastName + user.FirstName,
-			Avatar:   avatar,
-		},
-		CreatedAt: review.CreatedAt,
-		UpdatedAt: review.UpdatedAt,
-	}
-
-	return reviewRes, nil
+	return UserInfo{
+		ID:       user.ID,
+		FullName: user.LastName + user.FirstName,
+		Avatar:   avatar,
+	}, nil
 }
 
-func (r *reviewService) UpdateReview(ctx context.Context, id string, req *UpdateReviewRequest) error {
+func (r *reviewService) UpdateReview(ctx context.Context, userID string, id string, req *UpdateReviewRequest) error {
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid review id: %v", err)
 	}
 
-	return r.reviewRepo.UpdateByID(ctx, objectID, req)
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	if req.Rating < 1 || req.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+
+	if req.Review == "" {
+		return fmt.Errorf("review is required")
+	}
+
+	review, err := r.reviewRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if review == nil {
+		return fmt.Errorf("review not found")
+	}
+
+	if review.UserID != userObjID {
+		return fmt.Errorf("you can only edit your own review")
+	}
+
+	// Edited text is moderated again in the same write
+	status, reason := r.screen(req.Review)
+
+	err = r.reviewRepo.UpdateFields(ctx, objectID, bson.M{
+		"rating":            req.Rating,
+		"review":            req.Review,
+		"status":            status,
+		"moderation_reason": reason,
+		"moderated_by":      nil,
+		"moderated_at":      nil,
+		"updated_at":        time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	r.refreshProductRating(ctx, review.ProductID)
+
+	return nil
 
 }
 
-func (r *reviewService) DeleteReview(ctx context.Context, id string) error {
+func (r *reviewService) DeleteReview(ctx context.Context, userID string, id string) error {
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid review id: %v", err)
 	}
 
-	return r.reviewRepo.DeleteByID(ctx, objectID)
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	review, err := r.reviewRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if review == nil {
+		return fmt.Errorf("review not found")
+	}
+
+	if review.UserID != userObjID {
+		if _, err := user.RequireStaff(ctx, r.userRepo, userID); err != nil {
+			return fmt.Errorf("you can only delete your own review")
+		}
+	}
+
+	if err := r.reviewRepo.DeleteByID(ctx, objectID); err != nil {
+		return err
+	}
+
+	r.deleteMedia(ctx, review.Media)
+
+	r.refreshProductRating(ctx, review.ProductID)
+
+	return nil
 
 }
 
-func (r *reviewService) LikeReview(ctx context.Context, req *LikeReviewRequest, id string) error {
+func (r *reviewService) LikeReview(ctx context.Context, userID string, id string, req *LikeReviewRequest) error {
 
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
-	if req.UserID == "" {
-		return fmt.Errorf("user_id is required")
-	}
 	if req.Type == "" {
 		return fmt.Errorf("type is required")
 	}
@@ -235,7 +451,7 @@ func (r *reviewService) LikeReview(ctx context.Context, req *LikeReviewRequest,
 		return fmt.Errorf("invalid review id: %v", err)
 	}
 
-	userObjID, err := primitive.ObjectIDFromHex(req.UserID)
+	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return fmt.Errorf("invalid user id: %v", err)
 	}
@@ -249,36 +465,444 @@ func (r *reviewService) LikeReview(ctx context.Context, req *LikeReviewRequest,
 		return fmt.Errorf("review not found")
 	}
 
-	if req.Type == "like" {
-		alreadyLiked := false
-		for _, v := range review.LikeReview {
-			if v.UserID == userObjID {
-				alreadyLiked = true
-				break
-			}
+	switch req.Type {
+	case "like":
+		return r.reviewRepo.Vote(ctx, objectID, userObjID, "like_review", "dislike_review")
+	case "unlike":
+		return r.reviewRepo.Vote(ctx, objectID, userObjID, "", "like_review")
+	case "dislike":
+		return r.reviewRepo.Vote(ctx, objectID, userObjID, "dislike_review", "like_review")
+	case "undislike":
+		return r.reviewRepo.Vote(ctx, objectID, userObjID, "", "dislike_review")
+	default:
+		return fmt.Errorf("invalid type, must be 'like', 'unlike', 'dislike' or 'undislike'")
+	}
+}
+
+func (r *reviewService) GetModerationQueue(ctx context.Context, moderatorID string, filter *ModerationFilter) ([]*ModerationReviewResponse, error) {
+
+	if _, err := user.RequireStaff(ctx, r.userRepo, moderatorID); err != nil {
+		return nil, err
+	}
+
+	if filter.Status == "" && filter.Hidden == nil {
+		filter.Status = string(ReviewPending)
+	}
+
+	reviewList, err := r.reviewRepo.FindForModeration(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*ModerationReviewResponse
+
+	for _, v := range reviewList {
+
+		reviewRes, err := r.toReviewResponse(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, &ModerationReviewResponse{
+			ReviewResponse:   *reviewRes,
+			ModerationReason: v.ModerationReason,
+			ModeratedBy:      v.ModeratedBy,
+			ModeratedAt:      v.ModeratedAt,
+			Reports:          v.Reports,
+			Hidden:           v.Hidden,
+		})
+	}
+
+	return reviews, nil
+}
+
+func (r *reviewService) ApproveReview(ctx context.Context, moderatorID string, id string, req *ModerateReviewRequest) error {
+	return r.moderate(ctx, moderatorID, id, ReviewApproved, req.Reason)
+}
+
+func (r *reviewService) RejectReview(ctx context.Context, moderatorID string, id string, req *ModerateReviewRequest) error {
+
+	if req.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	return r.moderate(ctx, moderatorID, id, ReviewRejected, req.Reason)
+}
+
+func (r *reviewService) moderate(ctx context.Context, moderatorID string, id string, status ReviewStatus, reason string) error {
+
+	moderator, err := user.RequireStaff(ctx, r.userRepo, moderatorID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid review id: %v", err)
+	}
+
+	review, err := r.reviewRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	var moderationReason *string
+	if reason != "" {
+		moderationReason = &reason
+	}
+
+	now := time.Now()
+
+	// A moderator decision overrides an automatic hide from abuse reports
+	err = r.reviewRepo.UpdateFields(ctx, objectID, bson.M{
+		"status":            status,
+		"moderation_reason": moderationReason,
+		"moderated_by":      moderator.ID,
+		"moderated_at":      now,
+		"hidden":            false,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.refreshProductRating(ctx, review.ProductID)
+
+	return nil
+}
+
+func (r *reviewService) ReportReview(ctx context.Context, userID string, id string, req *ReportReviewRequest) error {
+
+	if req.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid review id: %v", err)
+	}
+
+	review, err := r.reviewRepo.AddReport(ctx, objectID, ReviewReport{
+		UserID:    userObjID,
+		Reason:    req.Reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.config.ReportThreshold > 0 && len(review.Reports) >= r.config.ReportThreshold && !review.Hidden {
+		if err := r.reviewRepo.UpdateFields(ctx, objectID, bson.M{"hidden": true}); err != nil {
+			return err
+		}
+		r.refreshProductRating(ctx, review.ProductID)
+	}
+
+	return nil
+}
+
+// refreshProductRating recomputes the rating stored on the product. Failures
+// are only logged; SyncProductRatings repairs any drift.
+func (r *reviewService) refreshProductRating(ctx context.Context, productID primitive.ObjectID) {
+	if err := r.updateProductRating(ctx, productID); err != nil {
+		log.Printf("failed to update rating of product %s: %v", productID.Hex(), err)
+	}
+}
+
+func (r *reviewService) updateProductRating(ctx context.Context, productID primitive.ObjectID) error {
+
+	counts, err := r.reviewRepo.CountVisibleByRating(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	histogram := make(map[string]int, 5)
+	total, count := 0, 0
+
+	for i := 1; i <= 5; i++ {
+		histogram[strconv.Itoa(i)] = counts[i]
+		total += i * counts[i]
+		count += counts[i]
+	}
+
+	average := 0.0
+	if count > 0 {
+		average = math.Round(float64(total)/float64(count)*100) / 100
+	}
+
+	return r.productRepo.UpdateRatingStats(ctx, productID, average, count, histogram)
+}
+
+func (r *reviewService) SyncProductRatings(ctx context.Context) error {
+
+	productIDs, err := r.reviewRepo.FindReviewedProductIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, productID := range productIDs {
+		if err := r.updateProductRating(ctx, productID); err != nil {
+			return err
 		}
-		if !alreadyLiked {
-			review.LikeReview = append(review.LikeReview, LikeReview{
-				UserID:    userObjID,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+	}
+
+	return nil
+}
+
+func (r *reviewService) AddReviewMedia(ctx context.Context, userID string, id string, files []*multipart.FileHeader) error {
+
+	if len(files) == 0 {
+		return fmt.Errorf("media is required")
+	}
+
+	review, err := r.findOwnReview(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if len(review.Media)+len(files) > r.config.MaxMedia {
+		return fmt.Errorf("a review can have at most %d photos or videos", r.config.MaxMedia)
+	}
+
+	media, err := r.uploadMedia(ctx, files)
+	if err != nil {
+		return err
+	}
+
+	if err := r.reviewRepo.PushMedia(ctx, review.ID, media, r.config.MaxMedia); err != nil {
+		r.deleteMedia(ctx, media)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reviewService) DeleteReviewMedia(ctx context.Context, userID string, id string, publicID string) error {
+
+	if publicID == "" {
+		return fmt.Errorf("public_id is required")
+	}
+
+	review, err := r.findOwnReview(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range review.Media {
+		if m.PublicID != publicID {
+			continue
+		}
+
+		if err := r.reviewRepo.PullMedia(ctx, review.ID, publicID); err != nil {
+			return err
+		}
+
+		r.deleteMedia(ctx, []ReviewMedia{m})
+		return nil
+	}
+
+	return fmt.Errorf("media not found")
+}
+
+func (r *reviewService) GetCustomerMedia(ctx context.Context, productID string) ([]*CustomerMediaResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id: %v", err)
+	}
+
+	reviewList, _, err := r.reviewRepo.FindAll(ctx, objectID, &ReviewFilter{HasMedia: true})
+	if err != nil {
+		return nil, err
+	}
+
+	gallery := []*CustomerMediaResponse{}
+
+	for _, review := range reviewList {
+		for _, m := range review.Media {
+			gallery = append(gallery, &CustomerMediaResponse{
+				ReviewID:  review.ID,
+				Rating:    review.Rating,
+				Url:       m.Url,
+				Type:      m.Type,
+				CreatedAt: m.CreatedAt,
 			})
 		}
-	} else if req.Type == "unlike" {
-		for i, v := range review.LikeReview {
-			if v.UserID == userObjID {
-				review.LikeReview = append(review.LikeReview[:i], review.LikeReview[i+1:]...)
-				break
-			}
+	}
+
+	return gallery, nil
+}
+
+func (r *reviewService) findOwnReview(ctx context.Context, userID string, id string) (*Reviews, error) {
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid review id: %v", err)
+	}
+
+	review, err := r.reviewRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.UserID != userObjID {
+		return nil, fmt.Errorf("you can only edit your own review")
+	}
+
+	return review, nil
+}
+
+// uploadMedia uploads review photos and videos, removing what was already
+// uploaded if one of the files fails.
+func (r *reviewService) uploadMedia(ctx context.Context, files []*multipart.FileHeader) ([]ReviewMedia, error) {
+
+	var media []ReviewMedia
+
+	for i, file := range files {
+
+		contentType := file.Header.Get("Content-Type")
+		if !strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "video/") {
+			r.deleteMedia(ctx, media)
+			return nil, fmt.Errorf("file %d must be an image or a video", i)
 		}
-	} else {
-		return fmt.Errorf("invalid type, must be 'like' or 'unlike'")
+
+		tempPath := "/tmp/" + primitive.NewObjectID().Hex() + filepath.Ext(file.Filename)
+		if err := helper.SaveUploadedFile(file, tempPath); err != nil {
+			r.deleteMedia(ctx, media)
+			return nil, fmt.Errorf("failed to save file %d: %w", i, err)
+		}
+
+		url, publicID, resourceType, err := r.uploader.UploadMedia(ctx, tempPath, "reviews")
+		os.Remove(tempPath)
+		if err != nil {
+			r.deleteMedia(ctx, media)
+			return nil, fmt.Errorf("failed to upload file %d: %w", i, err)
+		}
+
+		media = append(media, ReviewMedia{
+			Url:       url,
+			PublicID:  publicID,
+			Type:      resourceType,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return media, nil
+}
+
+func (r *reviewService) deleteMedia(ctx context.Context, media []ReviewMedia) {
+	for _, m := range media {
+		if err := r.uploader.DeleteMedia(ctx, m.PublicID, m.Type); err != nil {
+			log.Printf("failed to delete review media %s: %v", m.PublicID, err)
+		}
+	}
+}
+
+func (r *reviewService) ReplyReview(ctx context.Context, staffID string, id string, req *ReplyReviewRequest) error {
+
+	if strings.TrimSpace(req.Message) == "" {
+		return fmt.Errorf("message is required")
+	}
+
+	staff, err := user.RequireStaff(ctx, r.userRepo, staffID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid review id: %v", err)
+	}
+
+	review, err := r.reviewRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	reply := ReviewReply{
+		ID:        primitive.NewObjectID(),
+		StaffID:   staff.ID,
+		Message:   req.Message,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.reviewRepo.PushReply(ctx, objectID, reply); err != nil {
+		return err
+	}
+
+	r.notifyReviewer(ctx, review, reply)
+
+	return nil
+}
+
+func (r *reviewService) UpdateReply(ctx context.Context, staffID string, id string, replyID string, req *ReplyReviewRequest) error {
+
+	if strings.TrimSpace(req.Message) == "" {
+		return fmt.Errorf("message is required")
+	}
+
+	if _, err := user.RequireStaff(ctx, r.userRepo, staffID); err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid review id: %v", err)
+	}
+
+	replyObjID, err := primitive.ObjectIDFromHex(replyID)
+	if err != nil {
+		return fmt.Errorf("invalid reply id: %v", err)
+	}
+
+	return r.reviewRepo.UpdateReply(ctx, objectID, replyObjID, req.Message)
+}
+
+func (r *reviewService) DeleteReply(ctx context.Context, staffID string, id string, replyID string) error {
+
+	if _, err := user.RequireStaff(ctx, r.userRepo, staffID); err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid review id: %v", err)
+	}
+
+	replyObjID, err := primitive.ObjectIDFromHex(replyID)
+	if err != nil {
+		return fmt.Errorf("invalid reply id: %v", err)
+	}
+
+	return r.reviewRepo.PullReply(ctx, objectID, replyObjID)
+}
+
+// notifyReviewer emails the review author about a shop reply.
+func (r *reviewService) notifyReviewer(ctx context.Context, review *Reviews, reply ReviewReply) {
+
+	reviewer, err := r.userRepo.FindByUserID(ctx, review.UserID)
+	if err != nil || reviewer == nil {
+		log.Printf("failed to find reviewer %s: %v", review.UserID.Hex(), err)
+		return
+	}
+
+	productName := ""
+	if p, err := r.productRepo.FindByID(ctx, review.ProductID); err == nil && p != nil {
+		productName = p.ProductName
 	}
 
-	updateReq := &UpdateReviewRequest{
-		Rating:      review.Rating,
-		Review:      review.Review,
-		LikeReview:  review.LikeReview, 
+	html := BuildReviewReplyEmailHTML(*review, productName, reply, reviewer.LastName+" "+reviewer.FristName, "Football Shop")
+	if err := r.emailService.SendEmail(reviewer.Email, "The shop replied to your review", html); err != nil {
+		log.Printf("failed to send review reply email to %s: %v", reviewer.Email, err)
 	}
-	return r.reviewRepo.UpdateByID(ctx, objectID, updateReq)
 }
@@ -0,0 +1,70 @@
+package reviews
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+func BuildReviewReplyEmailHTML(review Reviews, productName string, reply ReviewReply, fullName string, brandName string) string {
+
+	// Ratings saved before validation existed may be out of range
+	rating := min(max(review.Rating, 0), 5)
+	stars := strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body style="margin:0; background:#f6f7f9; font-family:system-ui,-apple-system,Segoe UI,Roboto,Helvetica,Arial,sans-serif;">
+  <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="background:#f6f7f9; padding:24px 0;">
+    <tr>
+      <td align="center">
+        <table role="presentation" width="640" cellspacing="0" cellpadding="0" style="max-width:640px; width:100%%; background:#ffffff; border-radius:16px; overflow:hidden; box-shadow:0 4px 24px rgba(0,0,0,0.06);">
+          <tr>
+            <td style="background:linear-gradient(135deg,#0ea5e9,#6366f1); padding:20px; color:#eaf2ff; font-weight:800; font-size:18px;">%s</td>
+          </tr>
+          <tr>
+            <td style="padding:24px;">
+              <div style="font-size:18px; font-weight:700; color:#111; margin-bottom:6px;">Cửa hàng đã phản hồi đánh giá của bạn 💬</div>
+              <div style="font-size:14px; color:#444;">Xin chào %s, cảm ơn bạn đã đánh giá sản phẩm <strong>%s</strong>.</div>
+            </td>
+          </tr>
+          <tr>
+            <td style="padding:0 24px 16px 24px;">
+              <div style="font-size:12px; color:#64748b;">Đánh giá của bạn</div>
+              <div style="font-size:16px; color:#f59e0b; margin-top:4px;">%s</div>
+              <div style="font-size:14px; color:#444; margin-top:4px;">%s</div>
+            </td>
+          </tr>
+          <tr>
+            <td style="padding:0 24px 24px 24px;">
+              <table role="presentation" width="100%%" cellspacing="0" cellpadding="0" style="background:#f8fafc; border-left:4px solid #6366f1; border-radius:8px;">
+                <tr>
+                  <td style="padding:16px;">
+                    <div style="font-size:12px; color:#64748b;">Phản hồi từ %s</div>
+                    <div style="font-size:14px; color:#111; margin-top:4px;">%s</div>
+                  </td>
+                </tr>
+              </table>
+            </td>
+          </tr>
+        </table>
+      </td>
+    </tr>
+  </table>
+</body>
+</html>`,
+		brandName,
+		brandName,
+		html.EscapeString(fullName),
+		html.EscapeString(productName),
+		stars,
+		html.EscapeString(review.Review),
+		brandName,
+		html.EscapeString(reply.Message),
+	)
+}
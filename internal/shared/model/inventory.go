@@ -0,0 +1,32 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Stock movement types
+const (
+	MovementSale         = "sale"
+	MovementCancellation = "cancellation"
+	MovementReturn       = "return"
+	MovementAdjustment   = "adjustment"
+	MovementImport       = "import"
+	MovementTransfer     = "transfer"
+	MovementStockTake    = "stock_take"
+	MovementPurchase     = "purchase"
+)
+
+// StockAllocation is the part of an order line taken from one warehouse.
+type StockAllocation struct {
+	WarehouseID primitive.ObjectID `json:"warehouse_id" bson:"warehouse_id"`
+	Quantity    int                `json:"quantity" bson:"quantity"`
+}
+
+// StockChange says why stock moves, for the movement ledger. ReferenceID
+// points at the transfer, stock take, import job or purchase order behind
+// the change.
+type StockChange struct {
+	Type        string
+	Reason      string
+	ActorID     *primitive.ObjectID
+	OrderID     *primitive.ObjectID
+	ReferenceID *primitive.ObjectID
+}
@@ -12,7 +12,9 @@ const (
 	OrderPending    OrderStatus = "pending"
 	OrderPaid       OrderStatus = "paid"
 	OrderProcessing OrderStatus = "processing"
+	OrderDelivered  OrderStatus = "delivered"
 	OrderCancelled  OrderStatus = "cancelled"
+	OrderRefunded   OrderStatus = "refunded"
 )
 
 type Order struct {
@@ -32,12 +34,29 @@ type Order struct {
 
 type OrderItem struct {
 	ProductID    primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID    primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU          string             `json:"sku" bson:"sku"`
+	Attributes   map[string]string  `json:"attributes" bson:"attributes"`
 	ProductName  string             `json:"product_name" bson:"product_name"`
 	ProductImage string             `json:"product_image" bson:"product_image"`
 	Quantity     int                `json:"quantity" bson:"quantity"`
 	Price        float64            `json:"price" bson:"price"`
 	Size         string             `json:"size" bson:"size"`
 	TotalPrice   float64            `json:"total_price" bson:"total_price"`
+	Allocations  []StockAllocation  `json:"allocations" bson:"allocations"`
+	Components   []OrderComponent   `json:"components,omitempty" bson:"components,omitempty"`
+}
+
+// OrderComponent is an item of a bundle bought on an order line. Quantity is
+// per bundle; the stock taken is allocated on the component.
+type OrderComponent struct {
+	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
+	VariantID   primitive.ObjectID `json:"variant_id" bson:"variant_id"`
+	SKU         string             `json:"sku" bson:"sku"`
+	ProductName string             `json:"product_name" bson:"product_name"`
+	Attributes  map[string]string  `json:"attributes" bson:"attributes"`
+	Quantity    int                `json:"quantity" bson:"quantity"`
+	Allocations []StockAllocation  `json:"allocations" bson:"allocations"`
 }
 
 type ShippingAddress struct {
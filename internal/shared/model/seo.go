@@ -0,0 +1,7 @@
+package model
+
+type SEO struct {
+	MetaTitle       string `json:"meta_title" bson:"meta_title"`
+	MetaDescription string `json:"meta_description" bson:"meta_description"`
+	CanonicalURL    string `json:"canonical_url" bson:"canonical_url"`
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"modular_monolith/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InventorySync interface {
+	SyncProductStock(ctx context.Context, productID primitive.ObjectID, change model.StockChange) error
+}
@@ -1 +1,16 @@
-package ports
\ No newline at end of file
+package ports
+
+import (
+	"context"
+	"modular_monolith/internal/shared/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrderRepository interface {
+	FindPurchasedOrder(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (*model.Order, error)
+	FindPurchasedOrders(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) ([]*model.Order, error)
+	FindPurchasedSince(ctx context.Context, since time.Time) ([]*model.Order, error)
+	FindPurchasedByUserID(ctx context.Context, userID primitive.ObjectID) ([]*model.Order, error)
+}
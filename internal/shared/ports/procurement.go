@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PurchaseOrderRepository interface {
+	SumOpenQuantities(ctx context.Context) (map[primitive.ObjectID]int, error)
+}
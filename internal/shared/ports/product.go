@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProductCategoryRepository interface {
+	CountByCategoryIDs(ctx context.Context, categoryIDs []primitive.ObjectID) (int64, error)
+	MoveCategory(ctx context.Context, fromIDs []primitive.ObjectID, to primitive.ObjectID) error
+}
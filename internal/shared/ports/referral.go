@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReferralService interface {
+	GenerateReferralCode(ctx context.Context) (string, error)
+	TrackRegistration(ctx context.Context, refereeID primitive.ObjectID, referralCode string, clientIP string) error
+}
@@ -66,7 +66,9 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.UserService.RegisterUser(c, &req)
+	clientIP := helper.GetClientIP(c)
+
+	user, err := h.UserService.RegisterUser(c, &req, clientIP)
 
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
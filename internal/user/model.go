@@ -14,6 +14,13 @@ type User struct {
 	Token        string             `json:"token" bson:"token"`
 	RefreshToken string             `json:"refresh_token" bson:"refresh_token"`
 	UserType     string             `json:"user_type" bson:"user_type"`
+	ReferralCode string             `json:"referral_code" bson:"referral_code"`
+	RegisterIP   string             `json:"-" bson:"register_ip"`
 	CreatedAt    string             `json:"created_at" bson:"created_at"`
 	UpdatedAt    string             `json:"updated_at" bson:"updated_at"`
 }
+
+// IsStaff reports whether the user can act on behalf of the shop
+func (u *User) IsStaff() bool {
+	return u.UserType == "admin" || u.UserType == "staff"
+}
@@ -14,6 +14,7 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*User, error)
 	FindByID(ctx context.Context, userId primitive.ObjectID) (*UserWithProfile, error)
 	FindByUserID(ctx context.Context, userID primitive.ObjectID) (*User, error)
+	FindByReferralCode(ctx context.Context, code string) (*User, error)
 	UpdateByID(ctx context.Context, userID primitive.ObjectID, updateFields bson.M) error
 	DeleteByID(ctx context.Context, userID primitive.ObjectID) error
 }
@@ -146,4 +147,22 @@ func (r *userRepository) FindByUserID(ctx context.Context, userID primitive.Obje
 
 	return &user, nil
 	
-}
\ No newline at end of file
+}
+
+func (r *userRepository) FindByReferralCode(ctx context.Context, code string) (*User, error) {
+
+	filter := bson.M{"referral_code": code}
+
+	var user User
+
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+
+}
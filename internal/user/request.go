@@ -6,6 +6,8 @@ type RegisterRequest struct {
 	Email     string `json:"email" bson:"email"`
 	Password  string `json:"password" bson:"password"`
 	Phone     string `json:"phone" bson:"phone"`
+	// Referral code of the user who invited this one, optional
+	ReferralCode string `json:"referral_code" bson:"referral_code"`
 }
 
 type LoginRequest struct {
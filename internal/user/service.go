@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"modular_monolith/internal/profile"
+	"modular_monolith/internal/shared/ports"
 	"modular_monolith/pkg/email"
 	"os"
 	"time"
@@ -18,7 +19,7 @@ import (
 )
 
 type UserService interface {
-	RegisterUser(ctx context.Context, req *RegisterRequest) (*User, error)
+	RegisterUser(ctx context.Context, req *RegisterRequest, clientIP string) (*User, error)
 	LoginUser(ctx context.Context, email, password string) (*User, error)
 	GetUserByID(ctx context.Context, userID string) (*UserWithProfile, error)
 	GetAllUsers(ctx context.Context) ([]*UserWithProfile, error)
@@ -32,17 +33,19 @@ type UserService interface {
 }
 
 type userService struct {
-	repository     UserRepository
-	profileService profile.ProfileService
-	EmailService   *email.EmailService
+	repository      UserRepository
+	profileService  profile.ProfileService
+	referralService ports.ReferralService
+	EmailService    *email.EmailService
 }
 
-func NewUserService(repository UserRepository, profileService profile.ProfileService) UserService {
+func NewUserService(repository UserRepository, profileService profile.ProfileService, referralService ports.ReferralService) UserService {
 	emailService := email.NewEmailService()
 	return &userService{
-		repository:     repository,
-		profileService: profileService,
-		EmailService:   emailService,
+		repository:      repository,
+		profileService:  profileService,
+		referralService: referralService,
+		EmailService:    emailService,
 	}
 }
 
@@ -98,7 +101,7 @@ func (s *userService) GetUserByID(ctx context.Context, userID string) (*UserWith
 
 }
 
-func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*User, error) {
+func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest, clientIP string) (*User, error) {
 
 	if req.Email == "" {
 		return nil, fmt.Errorf("email is required")
@@ -126,6 +129,11 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 	newUserID := primitive.NewObjectID()
 	token, refreshToken := s.GenerateToken(newUserID.Hex())
 
+	referralCode, err := s.referralService.GenerateReferralCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate referral code: %w", err)
+	}
+
 	now := time.Now().Format(time.RFC3339)
 	user = &User{
 		ID:           newUserID,
@@ -137,6 +145,8 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 		Token:        token,
 		RefreshToken: refreshToken,
 		UserType:     "user",
+		ReferralCode: referralCode,
+		RegisterIP:   clientIP,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
@@ -146,6 +156,12 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if req.ReferralCode != "" {
+		if err := s.referralService.TrackRegistration(ctx, createdUser.ID, req.ReferralCode, clientIP); err != nil {
+			log.Printf("failed to track referral: %v", err)
+		}
+	}
+
 	createdUser.Password = ""
 	return createdUser, nil
 }
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RequireStaff returns the user when they are staff, and an error otherwise.
+func RequireStaff(ctx context.Context, repository UserRepository, userID string) (*User, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	staff, err := repository.FindByUserID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if staff == nil || !staff.IsStaff() {
+		return nil, fmt.Errorf("only staff can perform this action")
+	}
+
+	return staff, nil
+}
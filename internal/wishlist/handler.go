@@ -0,0 +1,70 @@
+package wishlist
+
+import (
+	"modular_monolith/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandler struct {
+	WishlistService WishlistService
+}
+
+func NewWishlistHandler(wishlistService WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		WishlistService: wishlistService,
+	}
+}
+
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.WishlistService.GetWishlist(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", products)
+}
+
+func (h *WishlistHandler) AddToWishlist(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AddToWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.WishlistService.AddToWishlist(c, userID, &req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", nil)
+}
+
+func (h *WishlistHandler) RemoveFromWishlist(c *gin.Context) {
+
+	userID, ok := helper.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.WishlistService.RemoveFromWishlist(c, userID, c.Param("product_id")); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
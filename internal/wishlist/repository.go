@@ -0,0 +1,69 @@
+package wishlist
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WishlistRepository interface {
+	Add(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) error
+	Remove(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (bool, error)
+	FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*WishlistItem, error)
+}
+
+type wishlistRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWishlistRepository(collection *mongo.Collection) WishlistRepository {
+	return &wishlistRepository{
+		collection: collection,
+	}
+}
+
+// Add is idempotent: adding a product twice keeps the first entry.
+func (r *wishlistRepository) Add(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) error {
+
+	filter := bson.M{"user_id": userID, "product_id": productID}
+	update := bson.M{"$setOnInsert": bson.M{"created_at": time.Now()}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *wishlistRepository) Remove(ctx context.Context, userID primitive.ObjectID, productID primitive.ObjectID) (bool, error) {
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID, "product_id": productID})
+	if err != nil {
+		return false, err
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+// FindByUserID returns the wishlist of the user, most recently added first.
+func (r *wishlistRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*WishlistItem, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*WishlistItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
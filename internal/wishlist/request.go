@@ -0,0 +1,5 @@
+package wishlist
+
+type AddToWishlistRequest struct {
+	ProductID string `json:"product_id"`
+}
@@ -0,0 +1,16 @@
+package wishlist
+
+import (
+	"modular_monolith/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *WishlistHandler) {
+	wishlistGroup := r.Group("/api/v1/wishlist", middleware.JWTAuthMiddleware())
+	{
+		wishlistGroup.GET("", handler.GetWishlist)
+		wishlistGroup.POST("", handler.AddToWishlist)
+		wishlistGroup.DELETE("/:product_id", handler.RemoveFromWishlist)
+	}
+}
@@ -0,0 +1,94 @@
+package wishlist
+
+import (
+	"context"
+	"fmt"
+	"modular_monolith/internal/product"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WishlistService interface {
+	AddToWishlist(ctx context.Context, userID string, req *AddToWishlistRequest) error
+	RemoveFromWishlist(ctx context.Context, userID string, productID string) error
+	GetWishlist(ctx context.Context, userID string) ([]*product.ProductResponse, error)
+}
+
+type wishlistService struct {
+	repository        WishlistRepository
+	productRepository product.ProductRepository
+	productService    product.ProductService
+}
+
+func NewWishlistService(repository WishlistRepository, productRepository product.ProductRepository, productService product.ProductService) WishlistService {
+	return &wishlistService{
+		repository:        repository,
+		productRepository: productRepository,
+		productService:    productService,
+	}
+}
+
+func (s *wishlistService) AddToWishlist(ctx context.Context, userID string, req *AddToWishlistRequest) error {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	productID, err := primitive.ObjectIDFromHex(req.ProductID)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	p, err := s.productRepository.FindByID(ctx, productID)
+	if err != nil || p == nil || !p.IsVisible() {
+		return fmt.Errorf("product not found")
+	}
+
+	return s.repository.Add(ctx, objectUserID, productID)
+}
+
+func (s *wishlistService) RemoveFromWishlist(ctx context.Context, userID string, productID string) error {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	objectProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product id: %v", err)
+	}
+
+	removed, err := s.repository.Remove(ctx, objectUserID, objectProductID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("product is not in the wishlist")
+	}
+
+	return nil
+}
+
+// GetWishlist returns the wished products that are still for sale.
+func (s *wishlistService) GetWishlist(ctx context.Context, userID string) ([]*product.ProductResponse, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	items, err := s.repository.FindByUserID(ctx, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []primitive.ObjectID
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	return s.productService.GetProductsByIDs(ctx, ids)
+}
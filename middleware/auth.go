@@ -63,3 +63,37 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalJWTAuthMiddleware sets user_id when a valid bearer token is sent and
+// lets the request through as a guest otherwise.
+func OptionalJWTAuthMiddleware() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.Next()
+			return
+		}
+
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			log.Panic("JWT_SECRET not set")
+		}
+
+		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(secret), nil
+		})
+
+		if err == nil {
+			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+				c.Set("user_id", claims["user_id"])
+			}
+		}
+
+		c.Next()
+	}
+}